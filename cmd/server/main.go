@@ -18,19 +18,37 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/ahxar/go-backend-service/internal/config"
 	"github.com/ahxar/go-backend-service/internal/handler"
+	"github.com/ahxar/go-backend-service/internal/middleware"
 	"github.com/ahxar/go-backend-service/internal/repository"
 	"github.com/ahxar/go-backend-service/internal/server"
 	"github.com/ahxar/go-backend-service/internal/service"
+	"github.com/ahxar/go-backend-service/internal/version"
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/httpcache"
+	"github.com/ahxar/go-backend-service/pkg/httpclient"
+	"github.com/ahxar/go-backend-service/pkg/idempotency"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
 	"github.com/ahxar/go-backend-service/pkg/logger"
 	"github.com/ahxar/go-backend-service/pkg/otel"
+	"github.com/ahxar/go-backend-service/pkg/outbox"
+	"github.com/ahxar/go-backend-service/pkg/profiling"
+	"github.com/ahxar/go-backend-service/pkg/scheduler"
+	"github.com/ahxar/go-backend-service/pkg/sse"
+	"github.com/ahxar/go-backend-service/pkg/webhook"
+	"github.com/ahxar/go-backend-service/pkg/wshub"
 )
 
 func main() {
@@ -38,21 +56,125 @@ func main() {
 	cfg := config.Load()
 
 	// Initialize logger
-	log := logger.New(cfg.Environment, cfg.LogLevel)
+	sinks := make([]logger.SinkConfig, 0, len(cfg.LogSinks))
+	for _, sink := range cfg.LogSinks {
+		sinks = append(sinks, logger.SinkConfig{
+			Type:   sink.Type,
+			Format: sink.Format,
+			Level:  sink.Level,
+			File: logger.FileConfig{
+				Path:       sink.Path,
+				MaxSizeMB:  sink.MaxSizeMB,
+				MaxAge:     sink.MaxAge,
+				MaxBackups: sink.MaxBackups,
+				Compress:   sink.Compress,
+			},
+			Syslog: logger.SyslogConfig{
+				Network: sink.Network,
+				Address: sink.Address,
+				Tag:     sink.Tag,
+			},
+		})
+	}
+
+	// Fan logs out to the OTLP logs signal; records are dropped until OpenTelemetry is set up
+	var extra []slog.Handler
+	if cfg.OtelEnabled && cfg.OtelLogsEnabled && cfg.OtelMode != otel.ModeLocal {
+		extra = append(extra, otel.LogHandler(cfg.OtelServiceName))
+	}
+
+	log, closeLogs, err := logger.New(logger.Config{
+		Level:  cfg.LogLevel,
+		Format: cfg.LogFormat,
+		Sinks:  sinks,
+		Sampling: logger.SamplingConfig{
+			Initial:    cfg.LogSamplingInitial,
+			Thereafter: cfg.LogSamplingThereafter,
+			Tick:       cfg.LogSamplingTick,
+		},
+		ExcludeRoutes: cfg.LogExcludeRoutes,
+		DedupeWindow:  cfg.LogDedupeWindow,
+		RedactKeys:    cfg.LogRedactKeys,
+		Extra:         extra,
+	})
+	if err != nil {
+		slog.Error("failed to create logger",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := closeLogs(); err != nil {
+			slog.Error("failed to close log sinks",
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+
+	build := version.Get()
 
 	log.Info("starting server",
+		slog.String("version", build.Version),
+		slog.String("commit", build.Commit),
 		slog.String("port", cfg.Port),
 		slog.String("environment", cfg.Environment),
 		slog.String("log_level", cfg.LogLevel),
 	)
 
+	// Keep recent spans in memory when running without a collector
+	var spans *otel.SpanBuffer
+	if cfg.OtelMode == otel.ModeLocal {
+		spans = otel.NewSpanBuffer(cfg.OtelLocalBufferSize)
+	}
+
 	// Initialize OpenTelemetry
 	otelShutdown, err := otel.Setup(context.Background(), otel.Config{
 		ServiceName:    cfg.OtelServiceName,
 		ServiceVersion: cfg.OtelServiceVersion,
 		Environment:    cfg.Environment,
-		Endpoint:       cfg.OtelEndpoint,
 		Enabled:        cfg.OtelEnabled,
+		Exporter: otel.ExporterConfig{
+			Protocol:          cfg.OtelProtocol,
+			Endpoint:          cfg.OtelEndpoint,
+			Insecure:          cfg.OtelInsecure,
+			Certificate:       cfg.OtelCertificate,
+			ClientCertificate: cfg.OtelClientCertificate,
+			ClientKey:         cfg.OtelClientKey,
+			Headers:           cfg.OtelHeaders,
+			Compression:       cfg.OtelCompression,
+			Timeout:           cfg.OtelTimeout,
+			Traces: otel.SignalConfig{
+				Endpoint: cfg.OtelTracesEndpoint,
+				Headers:  cfg.OtelTracesHeaders,
+				Timeout:  cfg.OtelTracesTimeout,
+			},
+			Metrics: otel.SignalConfig{
+				Endpoint: cfg.OtelMetricsEndpoint,
+				Headers:  cfg.OtelMetricsHeaders,
+				Timeout:  cfg.OtelMetricsTimeout,
+			},
+			Logs: otel.SignalConfig{
+				Endpoint: cfg.OtelLogsEndpoint,
+				Headers:  cfg.OtelLogsHeaders,
+				Timeout:  cfg.OtelLogsTimeout,
+			},
+		},
+		Sampling: otel.SamplingConfig{
+			Strategy:    cfg.OtelSampler,
+			Ratio:       cfg.OtelSamplerRatio,
+			RateLimit:   cfg.OtelSamplerRateLimit,
+			NeverPaths:  cfg.OtelSamplerNeverPaths,
+			AlwaysPaths: cfg.OtelSamplerAlwaysPaths,
+			KeepErrors:  cfg.OtelSamplerKeepErrors,
+		},
+		Logs:           cfg.OtelLogsEnabled,
+		Mode:           cfg.OtelMode,
+		LocalSpans:     spans,
+		RuntimeMetrics: cfg.OtelRuntimeMetrics,
+		Build: otel.BuildInfo{
+			Version: build.Version,
+			Commit:  build.Commit,
+		},
 	}, log)
 	if err != nil {
 		log.Error("failed to setup OpenTelemetry",
@@ -72,14 +194,305 @@ func main() {
 	// In a real app, this would include database connections
 	repo := repository.New(log)
 
+	// Create the event broker for Server-Sent Events
+	events := sse.NewBroker(sse.Config{
+		ReplaySize:   cfg.SSEReplaySize,
+		ClientBuffer: cfg.SSEClientBuffer,
+	})
+
+	// Cache service results in memory; a remote store can be layered in with cache.NewTiered
+	var cacheStore cache.Store
+	if cfg.CacheEnabled {
+		cacheStore = cache.NewMemory(cfg.CacheMaxEntries)
+	}
+
+	// Create the background job queue
+	var queue jobs.Queue
+	if cfg.JobsEnabled {
+		switch cfg.JobsBackend {
+		case "memory":
+			queue = jobs.NewMemory()
+		case "database":
+			queue = repo.Jobs()
+		default:
+			log.Error("unknown jobs backend",
+				slog.String("backend", cfg.JobsBackend),
+			)
+			os.Exit(1)
+		}
+	}
+
+	// Outgoing HTTP clients share retry and circuit breaker settings
+	clientCfg := httpclient.Config{
+		AttemptTimeout: cfg.HTTPClientTimeout,
+		MaxRetries:     cfg.HTTPClientMaxRetries,
+		BackoffBase:    cfg.HTTPClientBackoffBase,
+		BackoffMax:     cfg.HTTPClientBackoffMax,
+		Breaker: httpclient.BreakerConfig{
+			FailureThreshold: cfg.HTTPClientBreakerThreshold,
+			OpenTimeout:      cfg.HTTPClientBreakerTimeout,
+		},
+	}
+	healthClientCfg := clientCfg
+	healthClientCfg.Name = "health"
+	healthClientCfg.Timeout = cfg.HealthDependencyTimeout
+	// Probes are repeated by the orchestrator, so a check fails fast instead of retrying
+	healthClientCfg.MaxRetries = 0
+
+	// Webhook URLs are customer-supplied, so deliveries only dial public
+	// addresses unless WEBHOOKS_ALLOWED_NETWORKS opens internal ones
+	allowedNetworks, err := parseNetworks(cfg.WebhooksAllowedNetworks)
+	if err != nil {
+		log.Error("invalid WEBHOOKS_ALLOWED_NETWORKS",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	// Webhook deliveries are retried as jobs and must keep failing per endpoint,
+	// so their client only adds tracing, request IDs and metrics
+	webhookClient := httpclient.New(webhook.NewTransport(webhook.AddressPolicy{Allow: allowedNetworks}), httpclient.Config{
+		Name:           "webhooks",
+		AttemptTimeout: cfg.WebhooksTimeout,
+		Breaker:        httpclient.BreakerConfig{FailureThreshold: -1},
+	})
+
 	// Initialize service layer
-	svc := service.New(log, repo)
+	svc := service.New(log, repo, service.Options{
+		Events:     events,
+		CacheStore: cacheStore,
+		Cache: cache.Config{
+			TTL:         cfg.CacheTTL,
+			StaleTTL:    cfg.CacheStaleTTL,
+			LoadTimeout: cfg.CacheLoadTimeout,
+		},
+		Jobs:   queue,
+		Outbox: cfg.OutboxEnabled,
+		Webhooks: service.WebhookConfig{
+			Sender:       webhook.NewSender(webhookClient.Transport, webhook.SenderConfig{Timeout: cfg.WebhooksTimeout}),
+			MaxAttempts:  cfg.WebhooksMaxAttempts,
+			DisableAfter: cfg.WebhooksDisableAfter,
+		},
+		Health: service.HealthConfig{
+			Client:       httpclient.New(nil, healthClientCfg),
+			Dependencies: cfg.HealthDependencies,
+		},
+	})
+
+	// Start the job workers
+	var workers *jobs.Workers
+	if queue != nil {
+		workers = jobs.NewWorkers(queue, log, jobs.Config{
+			Concurrency:  cfg.JobsConcurrency,
+			PollInterval: cfg.JobsPollInterval,
+			Timeout:      cfg.JobsTimeout,
+			MaxAttempts:  cfg.JobsMaxAttempts,
+			BackoffBase:  cfg.JobsBackoffBase,
+			BackoffMax:   cfg.JobsBackoffMax,
+		})
+		svc.RegisterJobs(workers)
+		workers.Start()
+	}
+
+	// Start the scheduler; the repository lock elects one replica to run tasks
+	var sched *scheduler.Scheduler
+	if cfg.SchedulerEnabled {
+		sched, err = newScheduler(cfg, log, repo, svc.Tasks())
+		if err != nil {
+			log.Error("failed to create scheduler",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+		sched.Start()
+	}
+
+	// Relay domain events from the outbox; in-process consumers subscribe to bus
+	bus := outbox.NewMemory()
+	var relay *outbox.Relay
+	var natsBroker *outbox.NATS
+	if cfg.OutboxEnabled {
+		var broker outbox.Broker = bus
+		switch cfg.OutboxBroker {
+		case "memory":
+		case "nats":
+			natsBroker, err = outbox.NewNATS(outbox.NATSConfig{
+				URL:           cfg.OutboxNATSURL,
+				SubjectPrefix: cfg.OutboxNATSSubjectPrefix,
+				JetStream:     cfg.OutboxNATSJetStream,
+				Timeout:       cfg.OutboxNATSTimeout,
+			})
+			if err != nil {
+				log.Error("failed to create NATS broker",
+					slog.String("error", err.Error()),
+				)
+				os.Exit(1)
+			}
+			broker = outbox.Fanout(bus, natsBroker)
+		default:
+			log.Error("unknown outbox broker",
+				slog.String("broker", cfg.OutboxBroker),
+			)
+			os.Exit(1)
+		}
+
+		// Deliver domain events to webhooks; handled event IDs are remembered to skip redeliveries
+		svc.Subscribe(bus, cache.NewMemory(cfg.CacheMaxEntries))
+
+		relay = outbox.NewRelay(repo.Outbox(), broker, log, outbox.RelayConfig{
+			PollInterval: cfg.OutboxPollInterval,
+			BatchSize:    cfg.OutboxBatchSize,
+			MaxAttempts:  cfg.OutboxMaxAttempts,
+		})
+		relay.Start()
+	}
 
 	// Initialize handler layer
-	h := handler.New(log, svc)
+	h := handler.New(log, svc, events, cfg.SSEHeartbeat)
+
+	// Start continuous profiling
+	var profiler *profiling.Profiler
+	if cfg.ProfilingEnabled {
+		var sink profiling.Sink
+		if cfg.ProfilingPushURL != "" {
+			sink = profiling.NewHTTPSink(cfg.ProfilingPushURL, 30*time.Second)
+		} else {
+			sink, err = profiling.NewDirSink(cfg.ProfilingDir, cfg.ProfilingMaxFiles)
+			if err != nil {
+				log.Error("failed to create profile sink",
+					slog.String("error", err.Error()),
+				)
+				os.Exit(1)
+			}
+		}
+
+		profiler = profiling.New(profiling.Config{
+			Interval:         cfg.ProfilingInterval,
+			CPUDuration:      cfg.ProfilingCPUDuration,
+			Types:            cfg.ProfilingTypes,
+			LatencyThreshold: cfg.ProfilingLatencyThreshold,
+			MemoryThreshold:  uint64(max(cfg.ProfilingMemoryThresholdMB, 0)) << 20, //nolint:gosec // clamped to non-negative
+			Cooldown:         cfg.ProfilingTriggerCooldown,
+		}, sink, log)
+		profiler.Start()
+		defer profiler.Stop()
+	}
+
+	// Create the access logger, replacing the structured request log
+	var accessLog *middleware.AccessLogger
+	if cfg.AccessLogEnabled {
+		var out io.Writer
+		switch cfg.AccessLogOutput {
+		case "stdout", "":
+			out = os.Stdout
+		case "stderr":
+			out = os.Stderr
+		default:
+			file, err := logger.OpenRotatingFile(logger.FileConfig{
+				Path:       cfg.AccessLogOutput,
+				MaxSizeMB:  100,
+				MaxBackups: 5,
+			})
+			if err != nil {
+				log.Error("failed to open access log",
+					slog.String("error", err.Error()),
+				)
+				os.Exit(1)
+			}
+			defer func() { _ = file.Close() }()
+			out = file
+		}
+
+		accessLog, err = middleware.NewAccessLogger(out, cfg.AccessLogFormat, cfg.AccessLogExcludeRoutes)
+		if err != nil {
+			log.Error("failed to create access logger",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+	}
+
+	// Create the WebSocket handler and its session registry
+	var wsHub *wshub.Hub
+	var ws *handler.WebSocket
+	if cfg.WSEnabled {
+		wsHub = wshub.New(wshub.Config{
+			SendBuffer:   cfg.WSSendBuffer,
+			WriteTimeout: cfg.WSWriteTimeout,
+			PingInterval: cfg.WSPingInterval,
+		})
+		ws, err = handler.NewWebSocket(log, wsHub, handler.WebSocketConfig{
+			OriginPatterns:  cfg.WSAllowedOrigins,
+			Tokens:          cfg.WSAuthTokens,
+			MaxMessageBytes: int64(cfg.WSMaxMessageBytes),
+		})
+		if err != nil {
+			log.Error("failed to create websocket handler",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+	}
+
+	// Create the shared response cache
+	var responseCache *httpcache.ResponseCache
+	if cfg.ResponseCacheEnabled {
+		responseCache = httpcache.New(cache.NewMemory(cfg.ResponseCacheMaxEntries), httpcache.Config{
+			MaxBodyBytes: int64(cfg.ResponseCacheMaxBodyBytes),
+		})
+	}
+
+	// Create the idempotency guard for retried unsafe requests
+	var idempotent *idempotency.Guard
+	if cfg.IdempotencyEnabled {
+		idempotent = idempotency.New(idempotency.NewMemory(), idempotency.Config{
+			TTL:          cfg.IdempotencyTTL,
+			LockTTL:      cfg.IdempotencyLockTTL,
+			MaxBodyBytes: int64(cfg.IdempotencyMaxBodyBytes),
+		})
+	}
+
+	// Create the receiver for third-party webhooks; verified payloads are processed as jobs
+	var inbound *webhook.Receiver
+	if len(cfg.InboundWebhooks) > 0 {
+		if queue == nil {
+			log.Error("inbound webhooks require background jobs to be enabled")
+			os.Exit(1)
+		}
+		inbound, err = newReceiver(cfg, log, svc.ReceiveWebhook)
+		if err != nil {
+			log.Error("failed to create webhook receiver",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+	}
 
 	// Create and configure HTTP server
-	srv := server.New(cfg, log, h)
+	srv := server.New(cfg, log, h, server.Deps{
+		Spans:         spans,
+		Profiler:      profiler,
+		AccessLog:     accessLog,
+		WebSocket:     ws,
+		ResponseCache: responseCache,
+		Idempotency:   idempotent,
+		Inbound:       inbound,
+	})
+
+	// End event streams on shutdown so they do not hold connections open
+	srv.RegisterOnShutdown(events.Close)
+
+	// Create the admin server for profiling and runtime diagnostics
+	var admin *http.Server
+	if cfg.AdminEnabled {
+		admin, err = server.NewAdmin(cfg, log)
+		if err != nil {
+			log.Error("failed to create admin server",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+	}
 
 	// Create signal context for graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -99,6 +512,22 @@ func main() {
 		}
 	}()
 
+	// Start admin server in goroutine
+	if admin != nil {
+		go func() {
+			log.Info("admin server listening",
+				slog.String("address", admin.Addr),
+			)
+
+			if err := admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error("admin server error",
+					slog.String("error", err.Error()),
+				)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// Block until shutdown signal received
 	<-ctx.Done()
 
@@ -108,6 +537,20 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
+	// Stop the admin server first so diagnostics do not hold up shutdown
+	if admin != nil {
+		if err := admin.Shutdown(shutdownCtx); err != nil {
+			log.Error("admin server shutdown error",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	// Close WebSocket sessions, which Shutdown does not track once upgraded
+	if wsHub != nil {
+		wsHub.Close()
+	}
+
 	// Attempt graceful shutdown
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Error("shutdown error",
@@ -116,5 +559,121 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Stop scheduled tasks before draining the jobs they may enqueue
+	if sched != nil {
+		if err := sched.Stop(shutdownCtx); err != nil {
+			log.Error("scheduler stop error",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	// Stop relaying before draining the workers, since relayed events enqueue
+	// webhook jobs nobody would run; unpublished messages stay in the outbox
+	// for the next start
+	if relay != nil {
+		if err := relay.Stop(shutdownCtx); err != nil {
+			log.Error("outbox relay stop error",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	if natsBroker != nil {
+		_ = natsBroker.Close()
+	}
+
+	// Drain running jobs once requests and the relay have stopped enqueueing new ones
+	if workers != nil {
+		if err := workers.Shutdown(shutdownCtx); err != nil {
+			log.Error("job drain error",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
 	log.Info("server stopped gracefully")
 }
+
+// newScheduler creates a scheduler running the configured tasks
+// Tasks with an empty schedule are disabled
+func newScheduler(cfg *config.Config, log *slog.Logger, locker scheduler.Locker, tasks map[string]func(context.Context) error) (*scheduler.Scheduler, error) {
+	location, err := time.LoadLocation(cfg.SchedulerTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduler timezone: %w", err)
+	}
+
+	sched := scheduler.New(log, scheduler.Config{
+		Locker:   locker,
+		LeaseTTL: cfg.SchedulerLeaseTTL,
+		Location: location,
+		Timeout:  cfg.SchedulerTimeout,
+	})
+	for name, spec := range cfg.SchedulerTasks {
+		run, ok := tasks[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown scheduled task %q", name)
+		}
+		if spec == "" {
+			continue
+		}
+		schedule, err := scheduler.Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schedule of task %q: %w", name, err)
+		}
+		if err := sched.Add(scheduler.Task{
+			Name:     name,
+			Schedule: schedule,
+			Jitter:   cfg.SchedulerJitter,
+			Run:      run,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to add task %q: %w", name, err)
+		}
+	}
+	return sched, nil
+}
+
+// newReceiver creates a webhook receiver for the configured providers, each
+// verified with its scheme's signatures and handed to handle
+func newReceiver(cfg *config.Config, log *slog.Logger, handle func(context.Context, webhook.Delivery) error) (*webhook.Receiver, error) {
+	receiver := webhook.NewReceiver(webhook.NewMemoryNonces(), log, webhook.ReceiverConfig{
+		MaxBodyBytes: int64(cfg.InboundWebhookMaxBodyBytes),
+		NonceTTL:     cfg.InboundWebhookNonceTTL,
+	})
+	for name, scheme := range cfg.InboundWebhooks {
+		secret := cfg.InboundWebhookSecrets[name]
+		if secret == "" {
+			return nil, fmt.Errorf("missing secret for webhook provider %q", name)
+		}
+		var secrets [][]byte
+		for s := range strings.SplitSeq(secret, ",") {
+			secrets = append(secrets, []byte(s))
+		}
+
+		var verifier webhook.Verifier
+		switch scheme {
+		case "standard":
+			verifier = webhook.NewStandardVerifier(cfg.InboundWebhookTolerance, secrets...)
+		case "github":
+			verifier = webhook.NewGitHubVerifier(secrets...)
+		case "slack":
+			verifier = webhook.NewSlackVerifier(cfg.InboundWebhookTolerance, secrets...)
+		default:
+			return nil, fmt.Errorf("unknown signature scheme %q for webhook provider %q", scheme, name)
+		}
+		receiver.Register(name, webhook.Provider{Verifier: verifier, Handle: handle})
+	}
+	return receiver, nil
+}
+
+// parseNetworks parses CIDR prefixes such as 10.0.0.0/8
+func parseNetworks(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse network %q: %w", value, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,15 +17,191 @@ type Config struct {
 	ShutdownTimeout time.Duration
 	LogLevel        string
 	Environment     string
+	// Log output: default format and destinations
+	LogFormat string
+	LogSinks  []LogSink
+	// Log filtering and redaction
+	LogSamplingInitial    int
+	LogSamplingThereafter int
+	LogSamplingTick       time.Duration
+	LogExcludeRoutes      []string
+	LogDedupeWindow       time.Duration
+	LogRedactKeys         []string
+	// Access log configuration
+	AccessLogEnabled       bool
+	AccessLogFormat        string
+	AccessLogOutput        string
+	AccessLogExcludeRoutes []string
 	// OpenTelemetry configuration
 	OtelEnabled        bool
 	OtelEndpoint       string
 	OtelServiceName    string
 	OtelServiceVersion string
+	OtelLogsEnabled    bool
+	OtelRuntimeMetrics bool
+	// Telemetry mode: otlp exports to a collector, local keeps spans in memory
+	OtelMode            string
+	OtelLocalBufferSize int
+	// OTLP exporter transport configuration
+	OtelProtocol          string
+	OtelTracesEndpoint    string
+	OtelMetricsEndpoint   string
+	OtelLogsEndpoint      string
+	OtelInsecure          bool
+	OtelCertificate       string
+	OtelClientCertificate string
+	OtelClientKey         string
+	OtelHeaders           map[string]string
+	OtelCompression       string
+	OtelTimeout           time.Duration
+	// Per-signal headers and timeouts, taking precedence over the shared ones
+	OtelTracesHeaders  map[string]string
+	OtelMetricsHeaders map[string]string
+	OtelLogsHeaders    map[string]string
+	OtelTracesTimeout  time.Duration
+	OtelMetricsTimeout time.Duration
+	OtelLogsTimeout    time.Duration
+	// Trace sampling configuration
+	OtelSampler            string
+	OtelSamplerRatio       float64
+	OtelSamplerRateLimit   float64
+	OtelSamplerNeverPaths  []string
+	OtelSamplerAlwaysPaths []string
+	OtelSamplerKeepErrors  bool
+	// Admin server configuration
+	AdminEnabled bool
+	AdminAddr    string
+	AdminToken   string
+	// Continuous profiling configuration
+	ProfilingEnabled           bool
+	ProfilingInterval          time.Duration
+	ProfilingCPUDuration       time.Duration
+	ProfilingTypes             []string
+	ProfilingDir               string
+	ProfilingMaxFiles          int
+	ProfilingPushURL           string
+	ProfilingLatencyThreshold  time.Duration
+	ProfilingMemoryThresholdMB int
+	ProfilingTriggerCooldown   time.Duration
+	// Server-Sent Events configuration
+	SSEReplaySize   int
+	SSEClientBuffer int
+	SSEHeartbeat    time.Duration
+	// WebSocket configuration
+	WSEnabled         bool
+	WSAllowedOrigins  []string
+	WSAuthTokens      map[string]string
+	WSMaxMessageBytes int
+	WSPingInterval    time.Duration
+	WSWriteTimeout    time.Duration
+	WSSendBuffer      int
+	// Service result cache configuration
+	CacheEnabled     bool
+	CacheMaxEntries  int
+	CacheTTL         time.Duration
+	CacheStaleTTL    time.Duration
+	CacheLoadTimeout time.Duration
+	// HTTP caching configuration
+	HTTPCacheControl          map[string]string
+	HTTPCacheControlDefault   string
+	ResponseCacheEnabled      bool
+	ResponseCacheMaxEntries   int
+	ResponseCacheMaxBodyBytes int
+	// Idempotency configuration
+	IdempotencyEnabled      bool
+	IdempotencyTTL          time.Duration
+	IdempotencyLockTTL      time.Duration
+	IdempotencyMaxBodyBytes int
+	// Background job configuration
+	JobsEnabled      bool
+	JobsBackend      string
+	JobsConcurrency  int
+	JobsPollInterval time.Duration
+	JobsTimeout      time.Duration
+	JobsMaxAttempts  int
+	JobsBackoffBase  time.Duration
+	JobsBackoffMax   time.Duration
+	// Scheduler configuration
+	SchedulerEnabled  bool
+	SchedulerTasks    map[string]string
+	SchedulerJitter   time.Duration
+	SchedulerLeaseTTL time.Duration
+	SchedulerTimezone string
+	SchedulerTimeout  time.Duration
+	// Transactional outbox relay configuration
+	OutboxEnabled           bool
+	OutboxBroker            string
+	OutboxPollInterval      time.Duration
+	OutboxBatchSize         int
+	OutboxMaxAttempts       int
+	OutboxNATSURL           string
+	OutboxNATSSubjectPrefix string
+	OutboxNATSJetStream     bool
+	OutboxNATSTimeout       time.Duration
+	// Outgoing webhook delivery configuration
+	WebhooksTimeout      time.Duration
+	WebhooksMaxAttempts  int
+	WebhooksDisableAfter int
+	// WebhooksAllowedNetworks lists internal CIDRs webhooks may be sent to, e.g. 127.0.0.0/8 in development
+	WebhooksAllowedNetworks []string
+	// Inbound webhook receiver configuration
+	// InboundWebhooks maps provider names to signature schemes (standard, github or slack)
+	InboundWebhooks map[string]string
+	// InboundWebhookSecrets maps provider names to secrets; several may be separated by an encoded comma (%2C) during rotation
+	InboundWebhookSecrets      map[string]string
+	InboundWebhookTolerance    time.Duration
+	InboundWebhookNonceTTL     time.Duration
+	InboundWebhookMaxBodyBytes int
+	// Outgoing HTTP client configuration
+	HTTPClientTimeout          time.Duration
+	HTTPClientMaxRetries       int
+	HTTPClientBackoffBase      time.Duration
+	HTTPClientBackoffMax       time.Duration
+	HTTPClientBreakerThreshold int
+	HTTPClientBreakerTimeout   time.Duration
+	// Health check configuration
+	// HealthDependencies maps names to URLs that must answer GET with a 2xx status for /ready to pass
+	HealthDependencies      map[string]string
+	HealthDependencyTimeout time.Duration
 }
 
+// LogSink configures one log destination, read from LOG_SINK_<NAME>_* variables
+type LogSink struct {
+	Name   string
+	Type   string
+	Format string
+	Level  string
+	// Rotating file settings
+	Path       string
+	MaxSizeMB  int
+	MaxAge     time.Duration
+	MaxBackups int
+	Compress   bool
+	// Syslog settings
+	Network string
+	Address string
+	Tag     string
+}
+
+// redacted replaces secret values in the effective configuration
+const redacted = "REDACTED"
+
 // Load loads configuration from environment variables with sensible defaults
 func Load() *Config {
+	// The default OTLP endpoint depends on the transport protocol
+	otelProtocol := getEnv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	otelEndpoint := "http://localhost:4318"
+	if otelProtocol == "grpc" {
+		otelEndpoint = "http://localhost:4317"
+	}
+
+	// Production logs default to JSON for log shippers
+	environment := getEnv("ENVIRONMENT", "development")
+	logFormat := "text"
+	if environment == "production" {
+		logFormat = "json"
+	}
+
 	return &Config{
 		Port:            getEnv("PORT", "8080"),
 		ReadTimeout:     getEnv("READ_TIMEOUT", 5*time.Second),
@@ -31,13 +209,245 @@ func Load() *Config {
 		IdleTimeout:     getEnv("IDLE_TIMEOUT", 120*time.Second),
 		ShutdownTimeout: getEnv("SHUTDOWN_TIMEOUT", 15*time.Second),
 		LogLevel:        getEnv("LOG_LEVEL", "info"),
-		Environment:     getEnv("ENVIRONMENT", "development"),
+		Environment:     environment,
+		// Log output
+		LogFormat: getEnv("LOG_FORMAT", logFormat),
+		LogSinks:  loadLogSinks(getEnv("LOG_SINKS", []string{"stdout"})),
+		// Log filtering and redaction
+		LogSamplingInitial:    getEnv("LOG_SAMPLING_INITIAL", 0),
+		LogSamplingThereafter: getEnv("LOG_SAMPLING_THEREAFTER", 0),
+		LogSamplingTick:       getEnv("LOG_SAMPLING_TICK", time.Second),
+		LogExcludeRoutes:      getEnv("LOG_EXCLUDE_ROUTES", []string{"/health", "/ready"}),
+		LogDedupeWindow:       getEnv("LOG_DEDUPE_WINDOW", time.Duration(0)),
+		LogRedactKeys:         getEnv("LOG_REDACT_KEYS", []string{"password", "secret", "token", "authorization", "cookie", "api_key"}),
+		// Access log configuration
+		AccessLogEnabled:       getEnv("ACCESS_LOG_ENABLED", false),
+		AccessLogFormat:        getEnv("ACCESS_LOG_FORMAT", "combined"),
+		AccessLogOutput:        getEnv("ACCESS_LOG_OUTPUT", "stdout"),
+		AccessLogExcludeRoutes: getEnv("ACCESS_LOG_EXCLUDE_ROUTES", []string{"/health", "/ready"}),
 		// OpenTelemetry configuration
 		OtelEnabled:        getEnv("OTEL_ENABLED", true),
-		OtelEndpoint:       getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+		OtelEndpoint:       getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", otelEndpoint),
 		OtelServiceName:    getEnv("OTEL_SERVICE_NAME", "go-backend-service"),
 		OtelServiceVersion: getEnv("OTEL_SERVICE_VERSION", "1.0.0"),
+		OtelLogsEnabled:    getEnv("OTEL_LOGS_ENABLED", false),
+		OtelRuntimeMetrics: getEnv("OTEL_RUNTIME_METRICS", true),
+		// Telemetry mode
+		OtelMode:            getEnv("OTEL_MODE", "otlp"),
+		OtelLocalBufferSize: getEnv("OTEL_LOCAL_BUFFER_SIZE", 2000),
+		// OTLP exporter transport configuration
+		OtelProtocol:          otelProtocol,
+		OtelTracesEndpoint:    getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", ""),
+		OtelMetricsEndpoint:   getEnv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", ""),
+		OtelLogsEndpoint:      getEnv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", ""),
+		OtelInsecure:          getEnv("OTEL_EXPORTER_OTLP_INSECURE", false),
+		OtelCertificate:       getEnv("OTEL_EXPORTER_OTLP_CERTIFICATE", ""),
+		OtelClientCertificate: getEnv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE", ""),
+		OtelClientKey:         getEnv("OTEL_EXPORTER_OTLP_CLIENT_KEY", ""),
+		OtelHeaders:           getEnv("OTEL_EXPORTER_OTLP_HEADERS", map[string]string{}),
+		OtelCompression:       getEnv("OTEL_EXPORTER_OTLP_COMPRESSION", "none"),
+		OtelTimeout:           time.Duration(getEnv("OTEL_EXPORTER_OTLP_TIMEOUT", 10000)) * time.Millisecond,
+		OtelTracesHeaders:     getEnv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", map[string]string{}),
+		OtelMetricsHeaders:    getEnv("OTEL_EXPORTER_OTLP_METRICS_HEADERS", map[string]string{}),
+		OtelLogsHeaders:       getEnv("OTEL_EXPORTER_OTLP_LOGS_HEADERS", map[string]string{}),
+		OtelTracesTimeout:     time.Duration(getEnv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", 0)) * time.Millisecond,
+		OtelMetricsTimeout:    time.Duration(getEnv("OTEL_EXPORTER_OTLP_METRICS_TIMEOUT", 0)) * time.Millisecond,
+		OtelLogsTimeout:       time.Duration(getEnv("OTEL_EXPORTER_OTLP_LOGS_TIMEOUT", 0)) * time.Millisecond,
+		// Trace sampling configuration
+		OtelSampler:            getEnv("OTEL_TRACES_SAMPLER", "parentbased_always_on"),
+		OtelSamplerRatio:       getEnv("OTEL_TRACES_SAMPLER_ARG", 1.0),
+		OtelSamplerRateLimit:   getEnv("OTEL_TRACES_SAMPLER_RATE_LIMIT", 0.0),
+		OtelSamplerNeverPaths:  getEnv("OTEL_TRACES_SAMPLER_NEVER_PATHS", []string{"/health", "/ready"}),
+		OtelSamplerAlwaysPaths: getEnv("OTEL_TRACES_SAMPLER_ALWAYS_PATHS", []string{}),
+		OtelSamplerKeepErrors:  getEnv("OTEL_TRACES_SAMPLER_KEEP_ERRORS", true),
+		// Admin server configuration
+		AdminEnabled: getEnv("ADMIN_ENABLED", false),
+		AdminAddr:    getEnv("ADMIN_ADDR", "127.0.0.1:6060"),
+		AdminToken:   getEnv("ADMIN_TOKEN", ""),
+		// Continuous profiling configuration
+		ProfilingEnabled:           getEnv("PROFILING_ENABLED", false),
+		ProfilingInterval:          getEnv("PROFILING_INTERVAL", time.Minute),
+		ProfilingCPUDuration:       getEnv("PROFILING_CPU_DURATION", 10*time.Second),
+		ProfilingTypes:             getEnv("PROFILING_TYPES", []string{"cpu", "heap", "goroutine"}),
+		ProfilingDir:               getEnv("PROFILING_DIR", "profiles"),
+		ProfilingMaxFiles:          getEnv("PROFILING_MAX_FILES", 100),
+		ProfilingPushURL:           getEnv("PROFILING_PUSH_URL", ""),
+		ProfilingLatencyThreshold:  getEnv("PROFILING_LATENCY_THRESHOLD", time.Duration(0)),
+		ProfilingMemoryThresholdMB: getEnv("PROFILING_MEMORY_THRESHOLD_MB", 0),
+		ProfilingTriggerCooldown:   getEnv("PROFILING_TRIGGER_COOLDOWN", 5*time.Minute),
+		// Server-Sent Events configuration
+		SSEReplaySize:   getEnv("SSE_REPLAY_SIZE", 1000),
+		SSEClientBuffer: getEnv("SSE_CLIENT_BUFFER", 64),
+		SSEHeartbeat:    getEnv("SSE_HEARTBEAT", 15*time.Second),
+		// WebSocket configuration
+		WSEnabled:         getEnv("WS_ENABLED", false),
+		WSAllowedOrigins:  getEnv("WS_ALLOWED_ORIGINS", []string{}),
+		WSAuthTokens:      getEnv("WS_AUTH_TOKENS", map[string]string{}),
+		WSMaxMessageBytes: getEnv("WS_MAX_MESSAGE_BYTES", 32768),
+		WSPingInterval:    getEnv("WS_PING_INTERVAL", 30*time.Second),
+		WSWriteTimeout:    getEnv("WS_WRITE_TIMEOUT", 10*time.Second),
+		WSSendBuffer:      getEnv("WS_SEND_BUFFER", 16),
+		// Service result cache configuration
+		CacheEnabled:     getEnv("CACHE_ENABLED", true),
+		CacheMaxEntries:  getEnv("CACHE_MAX_ENTRIES", 10000),
+		CacheTTL:         getEnv("CACHE_TTL", time.Minute),
+		CacheStaleTTL:    getEnv("CACHE_STALE_TTL", 5*time.Minute),
+		CacheLoadTimeout: getEnv("CACHE_LOAD_TIMEOUT", 30*time.Second),
+		// HTTP caching configuration
+		HTTPCacheControl: getEnv("HTTP_CACHE_CONTROL", map[string]string{
+			"/health":          "no-store",
+			"/ready":           "no-store",
+			"GET /api/example": "public, max-age=60",
+		}),
+		HTTPCacheControlDefault:   getEnv("HTTP_CACHE_CONTROL_DEFAULT", "no-cache"),
+		ResponseCacheEnabled:      getEnv("RESPONSE_CACHE_ENABLED", false),
+		ResponseCacheMaxEntries:   getEnv("RESPONSE_CACHE_MAX_ENTRIES", 1000),
+		ResponseCacheMaxBodyBytes: getEnv("RESPONSE_CACHE_MAX_BODY_BYTES", 1<<20),
+		// Idempotency configuration
+		IdempotencyEnabled:      getEnv("IDEMPOTENCY_ENABLED", true),
+		IdempotencyTTL:          getEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyLockTTL:      getEnv("IDEMPOTENCY_LOCK_TTL", time.Minute),
+		IdempotencyMaxBodyBytes: getEnv("IDEMPOTENCY_MAX_BODY_BYTES", 1<<20),
+		// Background job configuration
+		JobsEnabled:      getEnv("JOBS_ENABLED", true),
+		JobsBackend:      getEnv("JOBS_BACKEND", "memory"),
+		JobsConcurrency:  getEnv("JOBS_CONCURRENCY", 4),
+		JobsPollInterval: getEnv("JOBS_POLL_INTERVAL", time.Second),
+		JobsTimeout:      getEnv("JOBS_TIMEOUT", time.Minute),
+		JobsMaxAttempts:  getEnv("JOBS_MAX_ATTEMPTS", 5),
+		JobsBackoffBase:  getEnv("JOBS_BACKOFF_BASE", time.Second),
+		JobsBackoffMax:   getEnv("JOBS_BACKOFF_MAX", 5*time.Minute),
+		// Scheduler configuration
+		SchedulerEnabled: getEnv("SCHEDULER_ENABLED", true),
+		SchedulerTasks: getEnv("SCHEDULER_TASKS", map[string]string{
+			"examples.report": "@hourly",
+			"outbox.cleanup":  "@hourly",
+		}),
+		SchedulerJitter:   getEnv("SCHEDULER_JITTER", 10*time.Second),
+		SchedulerLeaseTTL: getEnv("SCHEDULER_LEASE_TTL", 30*time.Second),
+		SchedulerTimezone: getEnv("SCHEDULER_TIMEZONE", "UTC"),
+		SchedulerTimeout:  getEnv("SCHEDULER_TIMEOUT", 5*time.Minute),
+		// Transactional outbox relay configuration
+		OutboxEnabled:           getEnv("OUTBOX_ENABLED", true),
+		OutboxBroker:            getEnv("OUTBOX_BROKER", "memory"),
+		OutboxPollInterval:      getEnv("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:         getEnv("OUTBOX_BATCH_SIZE", 100),
+		OutboxMaxAttempts:       getEnv("OUTBOX_MAX_ATTEMPTS", 10),
+		OutboxNATSURL:           getEnv("OUTBOX_NATS_URL", "nats://localhost:4222"),
+		OutboxNATSSubjectPrefix: getEnv("OUTBOX_NATS_SUBJECT_PREFIX", "events."),
+		OutboxNATSJetStream:     getEnv("OUTBOX_NATS_JETSTREAM", false),
+		OutboxNATSTimeout:       getEnv("OUTBOX_NATS_TIMEOUT", 5*time.Second),
+		// Outgoing webhook delivery configuration
+		WebhooksTimeout:         getEnv("WEBHOOKS_TIMEOUT", 10*time.Second),
+		WebhooksMaxAttempts:     getEnv("WEBHOOKS_MAX_ATTEMPTS", 8),
+		WebhooksDisableAfter:    getEnv("WEBHOOKS_DISABLE_AFTER", 20),
+		WebhooksAllowedNetworks: getEnv("WEBHOOKS_ALLOWED_NETWORKS", []string{}),
+		// Inbound webhook receiver configuration
+		InboundWebhooks:            getEnv("INBOUND_WEBHOOKS", map[string]string{}),
+		InboundWebhookSecrets:      getEnv("INBOUND_WEBHOOK_SECRETS", map[string]string{}),
+		InboundWebhookTolerance:    getEnv("INBOUND_WEBHOOK_TOLERANCE", 5*time.Minute),
+		InboundWebhookNonceTTL:     getEnv("INBOUND_WEBHOOK_NONCE_TTL", 24*time.Hour),
+		InboundWebhookMaxBodyBytes: getEnv("INBOUND_WEBHOOK_MAX_BODY_BYTES", 1<<20),
+		// Outgoing HTTP client configuration
+		HTTPClientTimeout:          getEnv("HTTP_CLIENT_TIMEOUT", 10*time.Second),
+		HTTPClientMaxRetries:       getEnv("HTTP_CLIENT_MAX_RETRIES", 2),
+		HTTPClientBackoffBase:      getEnv("HTTP_CLIENT_BACKOFF_BASE", 100*time.Millisecond),
+		HTTPClientBackoffMax:       getEnv("HTTP_CLIENT_BACKOFF_MAX", 5*time.Second),
+		HTTPClientBreakerThreshold: getEnv("HTTP_CLIENT_BREAKER_THRESHOLD", 5),
+		HTTPClientBreakerTimeout:   getEnv("HTTP_CLIENT_BREAKER_TIMEOUT", 30*time.Second),
+		// Health check configuration
+		HealthDependencies:      getEnv("HEALTH_DEPENDENCIES", map[string]string{}),
+		HealthDependencyTimeout: getEnv("HEALTH_DEPENDENCY_TIMEOUT", 2*time.Second),
+	}
+}
+
+// loadLogSinks reads the settings of each named sink
+// The sink type defaults to the name, so LOG_SINKS=stdout,file needs no LOG_SINK_<NAME>_TYPE
+func loadLogSinks(names []string) []LogSink {
+	sinks := make([]LogSink, 0, len(names))
+	for _, name := range names {
+		prefix := "LOG_SINK_" + strings.ToUpper(name) + "_"
+		sinks = append(sinks, LogSink{
+			Name:       name,
+			Type:       getEnv(prefix+"TYPE", strings.ToLower(name)),
+			Format:     getEnv(prefix+"FORMAT", ""),
+			Level:      getEnv(prefix+"LEVEL", ""),
+			Path:       getEnv(prefix+"PATH", "logs/service.log"),
+			MaxSizeMB:  getEnv(prefix+"MAX_SIZE_MB", 100),
+			MaxAge:     getEnv(prefix+"MAX_AGE", time.Duration(0)),
+			MaxBackups: getEnv(prefix+"MAX_BACKUPS", 5),
+			Compress:   getEnv(prefix+"COMPRESS", false),
+			Network:    getEnv(prefix+"NETWORK", "udp"),
+			Address:    getEnv(prefix+"ADDRESS", ""),
+			Tag:        getEnv(prefix+"TAG", "go-backend-service"),
+		})
+	}
+	return sinks
+}
+
+// Redacted returns a copy of the configuration with secrets masked, safe to expose
+func (c *Config) Redacted() Config {
+	out := *c
+
+	if out.AdminToken != "" {
+		out.AdminToken = redacted
+	}
+
+	// Exporter headers usually carry API keys
+	out.OtelHeaders = redactValues(c.OtelHeaders)
+	out.OtelTracesHeaders = redactValues(c.OtelTracesHeaders)
+	out.OtelMetricsHeaders = redactValues(c.OtelMetricsHeaders)
+	out.OtelLogsHeaders = redactValues(c.OtelLogsHeaders)
+
+	out.WSAuthTokens = make(map[string]string, len(c.WSAuthTokens))
+	for subject := range c.WSAuthTokens {
+		out.WSAuthTokens[subject] = redacted
+	}
+
+	out.InboundWebhookSecrets = make(map[string]string, len(c.InboundWebhookSecrets))
+	for provider := range c.InboundWebhookSecrets {
+		out.InboundWebhookSecrets[provider] = redacted
+	}
+
+	// URLs may carry credentials in their userinfo or query
+	out.OtelEndpoint = redactURL(c.OtelEndpoint)
+	out.OtelTracesEndpoint = redactURL(c.OtelTracesEndpoint)
+	out.OtelMetricsEndpoint = redactURL(c.OtelMetricsEndpoint)
+	out.OtelLogsEndpoint = redactURL(c.OtelLogsEndpoint)
+	out.ProfilingPushURL = redactURL(c.ProfilingPushURL)
+	out.OutboxNATSURL = redactURL(c.OutboxNATSURL)
+
+	out.HealthDependencies = make(map[string]string, len(c.HealthDependencies))
+	for name, dependency := range c.HealthDependencies {
+		out.HealthDependencies[name] = redactURL(dependency)
+	}
+
+	return out
+}
+
+// redactValues returns a copy of m with every value masked
+func redactValues(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for key := range m {
+		out[key] = redacted
+	}
+	return out
+}
+
+// redactURL masks the userinfo of raw and drops its query and fragment
+// Values that do not parse as URLs, such as host:port endpoints, are kept as-is
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.User == nil && u.RawQuery == "" && u.Fragment == "") {
+		return raw
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
 	}
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	return u.String()
 }
 
 // getEnv retrieves an environment variable, parses it based on type, or returns a default value
@@ -56,8 +466,16 @@ func getEnv[T any](key string, defaultValue T) T {
 		result = value
 	case bool:
 		result, err = strconv.ParseBool(value)
+	case int:
+		result, err = strconv.Atoi(value)
+	case float64:
+		result, err = strconv.ParseFloat(value, 64)
 	case time.Duration:
 		result, err = time.ParseDuration(value)
+	case []string:
+		result = splitList(value)
+	case map[string]string:
+		result, err = splitPairs(value)
 	default:
 		return defaultValue
 	}
@@ -68,3 +486,35 @@ func getEnv[T any](key string, defaultValue T) T {
 
 	return result.(T)
 }
+
+// splitList parses a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitPairs parses a comma-separated list of URL-encoded key=value pairs
+func splitPairs(value string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, item := range splitList(value) {
+		key, val, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimSpace(key))
+		if err != nil {
+			return nil, err
+		}
+		val, err = url.PathUnescape(strings.TrimSpace(val))
+		if err != nil {
+			return nil, err
+		}
+		pairs[key] = val
+	}
+	return pairs, nil
+}
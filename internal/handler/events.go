@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/sse"
+)
+
+// Events streams change events as Server-Sent Events
+// @Summary Event stream
+// @Description Streams live change events. Reconnecting clients resume after Last-Event-ID from a bounded replay buffer
+// @Tags events
+// @Produce text/event-stream
+// @Param topic query []string false "Topics to subscribe to, all when omitted" collectionFormat(csv)
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 500 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/events [get]
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.events == nil {
+		h.writeError(w, r, http.StatusServiceUnavailable, "event stream unavailable")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	// The stream outlives the server write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.WarnContext(ctx, "failed to clear write deadline",
+			slog.String("error", err.Error()),
+		)
+	}
+
+	var topics []string
+	for _, value := range r.URL.Query()["topic"] {
+		for topic := range strings.SplitSeq(value, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	// EventSource sends Last-Event-ID on reconnect; the query parameter allows resuming a fresh connection
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	sub, backlog := h.events.Subscribe(topics, lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disable response buffering in nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := sse.WriteRetry(w, 3000); err != nil {
+		return
+	}
+	for _, event := range backlog {
+		if err := sse.WriteEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.ErrorContext(ctx, "streaming not supported",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			err = sse.WriteComment(w, "heartbeat")
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					// The client reconnects and resumes from the replay buffer
+					h.logger.WarnContext(ctx, "event stream dropped slow client")
+				}
+				return
+			}
+			err = sse.WriteEvent(w, event)
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -3,8 +3,6 @@ package handler
 import (
 	"log/slog"
 	"net/http"
-
-	"github.com/ahxar/go-backend-service/internal/model"
 )
 
 // Example handles example API requests
@@ -14,7 +12,9 @@ import (
 // @Accept json
 // @Produce json
 // @Param name query string false "Name to greet" default(World)
+// @Param If-None-Match header string false "ETag of a cached response"
 // @Success 200 {object} model.ExampleResponse
+// @Success 304 "Not modified"
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/example [get]
 func (h *Handler) Example(w http.ResponseWriter, r *http.Request) {
@@ -31,14 +31,11 @@ func (h *Handler) Example(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.ErrorContext(ctx, "service error",
 			slog.String("error", err.Error()),
-			slog.String("name", name),
 		)
-		h.writeJSON(w, http.StatusInternalServerError, &model.ErrorResponse{
-			Error: "internal server error",
-		})
+		h.writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	// Return successful response
-	h.writeJSON(w, http.StatusOK, result)
+	h.writeJSON(w, r, http.StatusOK, result)
 }
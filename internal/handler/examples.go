@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ahxar/go-backend-service/internal/model"
+	"github.com/ahxar/go-backend-service/internal/service"
+	"github.com/ahxar/go-backend-service/pkg/httpcache"
+)
+
+// CreateExample handles example creation
+// @Summary Create an example
+// @Description Stores a new example at version 1; the ETag holds the version for later conditional writes
+// @Tags examples
+// @Accept json
+// @Produce json
+// @Param example body model.ExampleInput true "Example to create"
+// @Param Idempotency-Key header string false "Key deduplicating retries of this request"
+// @Success 201 {object} model.Example
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/examples [post]
+func (h *Handler) CreateExample(w http.ResponseWriter, r *http.Request) {
+	var input model.ExampleInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	example, err := h.service.CreateExample(r.Context(), input)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/examples/"+example.ID)
+	h.writeExample(w, r, http.StatusCreated, example)
+}
+
+// GetExample handles example retrieval
+// @Summary Get an example
+// @Description Returns an example with its version as a strong ETag
+// @Tags examples
+// @Produce json
+// @Param id path string true "Example ID"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Success 200 {object} model.Example
+// @Success 304 "Not modified"
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/examples/{id} [get]
+func (h *Handler) GetExample(w http.ResponseWriter, r *http.Request) {
+	example, err := h.service.GetExample(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Last-Modified", example.UpdatedAt.Format(http.TimeFormat))
+	h.writeExample(w, r, http.StatusOK, example)
+}
+
+// UpdateExample handles example replacement
+// @Summary Replace an example
+// @Description Replaces an example if If-Match names its current version
+// @Tags examples
+// @Accept json
+// @Produce json
+// @Param id path string true "Example ID"
+// @Param If-Match header string true "ETag of the version being replaced, or *"
+// @Param example body model.ExampleInput true "New example fields"
+// @Success 200 {object} model.Example
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 412 {object} model.ErrorResponse
+// @Failure 428 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/examples/{id} [put]
+func (h *Handler) UpdateExample(w http.ResponseWriter, r *http.Request) {
+	version, ok := h.expectedVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var input model.ExampleInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	example, err := h.service.UpdateExample(r.Context(), r.PathValue("id"), version, input)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	h.writeExample(w, r, http.StatusOK, example)
+}
+
+// PatchExample handles partial example updates
+// @Summary Update an example
+// @Description Changes the given fields of an example if If-Match names its current version
+// @Tags examples
+// @Accept json
+// @Produce json
+// @Param id path string true "Example ID"
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Param example body model.ExamplePatch true "Fields to change"
+// @Param Idempotency-Key header string false "Key deduplicating retries of this request"
+// @Success 200 {object} model.Example
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 412 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
+// @Failure 428 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/examples/{id} [patch]
+func (h *Handler) PatchExample(w http.ResponseWriter, r *http.Request) {
+	version, ok := h.expectedVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var patch model.ExamplePatch
+	if err := decodeJSON(w, r, &patch); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	example, err := h.service.PatchExample(r.Context(), r.PathValue("id"), version, patch)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	h.writeExample(w, r, http.StatusOK, example)
+}
+
+// DeleteExample handles example deletion
+// @Summary Delete an example
+// @Description Deletes an example if If-Match names its current version
+// @Tags examples
+// @Produce json
+// @Param id path string true "Example ID"
+// @Param If-Match header string true "ETag of the version being deleted, or *"
+// @Success 204 "Deleted"
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 412 {object} model.ErrorResponse
+// @Failure 428 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/examples/{id} [delete]
+func (h *Handler) DeleteExample(w http.ResponseWriter, r *http.Request) {
+	version, ok := h.expectedVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteExample(r.Context(), r.PathValue("id"), version); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// expectedVersion reads the version a write is conditional on from If-Match
+// A missing header is answered with 428 and a tag that cannot match any
+// version with 412; it reports false when a response has been written
+func (h *Handler) expectedVersion(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		h.writeError(w, r, http.StatusPreconditionRequired, "If-Match header is required")
+		return 0, false
+	}
+	if value == "*" {
+		return service.AnyVersion, true
+	}
+
+	version, ok := httpcache.ParseVersionETag(value)
+	if !ok {
+		h.writeError(w, r, http.StatusPreconditionFailed, "If-Match does not match the current version")
+		return 0, false
+	}
+	return version, true
+}
+
+// writeExample writes an example with its version as the ETag
+func (h *Handler) writeExample(w http.ResponseWriter, r *http.Request, status int, example *model.Example) {
+	w.Header().Set("ETag", httpcache.VersionETag(example.Version))
+	h.writeJSON(w, r, status, example)
+}
+
+// writeServiceError maps service errors to responses
+func (h *Handler) writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		h.writeError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		h.writeError(w, r, http.StatusNotFound, "example not found")
+	case errors.Is(err, service.ErrVersionMismatch):
+		h.writeError(w, r, http.StatusPreconditionFailed, "If-Match does not match the current version")
+	default:
+		h.logger.ErrorContext(r.Context(), "service error",
+			slog.String("error", err.Error()),
+		)
+		h.writeError(w, r, http.StatusInternalServerError, "internal server error")
+	}
+}
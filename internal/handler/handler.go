@@ -2,36 +2,89 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/ahxar/go-backend-service/internal/model"
 	"github.com/ahxar/go-backend-service/internal/service"
+	"github.com/ahxar/go-backend-service/pkg/httpcache"
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+	"github.com/ahxar/go-backend-service/pkg/sse"
 )
 
 // Handler contains HTTP handlers and dependencies
 type Handler struct {
-	logger  *slog.Logger
-	service *service.Service
+	logger    *slog.Logger
+	service   *service.Service
+	events    *sse.Broker
+	heartbeat time.Duration
 }
 
 // New creates a new Handler instance
-func New(logger *slog.Logger, svc *service.Service) *Handler {
+// events is the broker streamed by the events endpoint and may be nil;
+// heartbeat is the interval of keep-alive comments on event streams
+func New(logger *slog.Logger, svc *service.Service, events *sse.Broker, heartbeat time.Duration) *Handler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
 	return &Handler{
-		logger:  logger,
-		service: svc,
+		logger:    logger,
+		service:   svc,
+		events:    events,
+		heartbeat: heartbeat,
 	}
 }
 
-// writeJSON writes a JSON response with the given status code
-func (h *Handler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+// maxBodyBytes limits the size of JSON request bodies
+const maxBodyBytes = 1 << 20
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		// If we fail to encode, there's not much we can do
-		// The status code has already been written
-		h.logger.Error("failed to encode response",
+// decodeJSON decodes a JSON request body into v, rejecting unknown fields
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode request body: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes a JSON response with the given status code
+// Successful responses carry an ETag, derived from the body unless the handler
+// set a version-based one, and conditional GET and HEAD requests receive 304
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode response",
 			slog.String("error", err.Error()),
 		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+	body = append(body, '\n')
+
+	w.Header().Set("Content-Type", "application/json")
+	if status == http.StatusOK {
+		if w.Header().Get("ETag") == "" {
+			w.Header().Set("ETag", httpcache.ETag(body))
+		}
+		if httpcache.NotModified(r, w.Header()) {
+			httpcache.WriteNotModified(w)
+			return
+		}
+	}
+
+	w.WriteHeader(status)
+	// Write errors mean the client went away; the status has already been sent
+	_, _ = w.Write(body)
+}
+
+// writeError writes a JSON error response tagged with the request ID
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	h.writeJSON(w, r, status, &model.ErrorResponse{
+		Error:     message,
+		RequestID: requestid.FromContext(r.Context()),
+	})
 }
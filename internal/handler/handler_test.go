@@ -1,16 +1,28 @@
 package handler
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/coder/websocket"
 
 	"github.com/ahxar/go-backend-service/internal/model"
 	"github.com/ahxar/go-backend-service/internal/repository"
 	"github.com/ahxar/go-backend-service/internal/service"
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+	"github.com/ahxar/go-backend-service/pkg/outbox"
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+	"github.com/ahxar/go-backend-service/pkg/sse"
+	"github.com/ahxar/go-backend-service/pkg/wshub"
 )
 
 func setupTestHandler() *Handler {
@@ -18,8 +30,8 @@ func setupTestHandler() *Handler {
 		Level: slog.LevelError,
 	}))
 	repo := repository.New(logger)
-	svc := service.New(logger, repo)
-	return New(logger, svc)
+	svc := service.New(logger, repo, service.Options{})
+	return New(logger, svc, nil, 0)
 }
 
 func TestHealth(t *testing.T) {
@@ -92,3 +104,395 @@ func TestExample(t *testing.T) {
 		t.Error("expected processed to be true")
 	}
 }
+
+func TestWriteError_IncludesRequestID(t *testing.T) {
+	h := setupTestHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/example", http.NoBody)
+	req = req.WithContext(requestid.NewContext(req.Context(), "req-1"))
+	rec := httptest.NewRecorder()
+
+	h.writeError(rec, req, http.StatusInternalServerError, "internal server error")
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+
+	var response model.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.RequestID != "req-1" {
+		t.Errorf("expected request ID req-1, got %s", response.RequestID)
+	}
+}
+
+func TestWriteJSON_ConditionalGet(t *testing.T) {
+	h := setupTestHandler()
+	data := map[string]string{"status": "ok"}
+
+	req := httptest.NewRequest(http.MethodGet, "/health", http.NoBody)
+	rec := httptest.NewRecorder()
+	h.writeJSON(rec, req, http.StatusOK, data)
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/health", http.NoBody)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.writeJSON(rec, req, http.StatusOK, data)
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected status 304, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected an empty body, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "" {
+		t.Error("expected Content-Type to be omitted from 304 responses")
+	}
+}
+
+func TestExamples_ConditionalWrites(t *testing.T) {
+	h := setupTestHandler()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/examples", h.CreateExample)
+	mux.HandleFunc("GET /api/examples/{id}", h.GetExample)
+	mux.HandleFunc("PUT /api/examples/{id}", h.UpdateExample)
+	mux.HandleFunc("DELETE /api/examples/{id}", h.DeleteExample)
+
+	do := func(method, path, ifMatch, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/examples", "", `{"name":"first"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rec.Code)
+	}
+	var created model.Example
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	path := "/api/examples/" + created.ID
+	etag := rec.Header().Get("ETag")
+	if etag != `"v1"` {
+		t.Errorf("expected ETag \"v1\", got %s", etag)
+	}
+
+	if rec := do(http.MethodPut, path, "", `{"name":"second"}`); rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("expected status 428 without If-Match, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, path, `"v7"`, `{"name":"second"}`); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status 412 for a stale version, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, path, `"abc"`, `{"name":"second"}`); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status 412 for a foreign tag, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodPut, path, etag, `{"name":"second"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("ETag") != `"v2"` {
+		t.Errorf("expected ETag \"v2\", got %s", rec.Header().Get("ETag"))
+	}
+
+	// The first version's tag is now stale
+	if rec := do(http.MethodDelete, path, etag, ""); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status 412 deleting a stale version, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, path, `"v2"`, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, path, "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestEvents_ResumeAndStream(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	events := sse.NewBroker(sse.Config{ReplaySize: 10})
+	svc := service.New(logger, repository.New(logger), service.Options{Events: events})
+	h := New(logger, svc, events, time.Hour)
+
+	first := events.Publish(service.TopicExamples, "first", []byte(`1`))
+	events.Publish(service.TopicExamples, "second", []byte(`2`))
+	events.Publish("other", "ignored", []byte(`3`))
+
+	server := httptest.NewServer(http.HandlerFunc(h.Events))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"?topic="+service.TopicExamples, http.NoBody)
+	req.Header.Set("Last-Event-ID", sse.FormatID(first))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEventName := func() string {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read stream: %v", err)
+			}
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				return strings.TrimSpace(name)
+			}
+		}
+	}
+
+	if name := readEventName(); name != "second" {
+		t.Errorf("expected replayed event second, got %s", name)
+	}
+	// Publish a live event once the backlog has been read
+	events.Publish(service.TopicExamples, "live", []byte(`4`))
+	if name := readEventName(); name != "live" {
+		t.Errorf("expected live event, got %s", name)
+	}
+}
+
+func TestEvents_Unavailable(t *testing.T) {
+	h := setupTestHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	h.Events(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+}
+
+func setupTestWebSocket(t *testing.T, maxMessageBytes int64) *httptest.Server {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+	hub := wshub.New(wshub.Config{})
+	ws, err := NewWebSocket(logger, hub, WebSocketConfig{
+		Tokens:          map[string]string{"alice": "alice-token", "bob": "bob-token"},
+		MaxMessageBytes: maxMessageBytes,
+	})
+	if err != nil {
+		t.Fatalf("failed to create websocket handler: %v", err)
+	}
+
+	server := httptest.NewServer(ws)
+	t.Cleanup(server.Close)
+	t.Cleanup(hub.Close)
+	return server
+}
+
+func dialWebSocket(t *testing.T, server *httptest.Server, token string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	conn, resp, err := websocket.Dial(t.Context(), "ws"+strings.TrimPrefix(server.URL, "http"), &websocket.DialOptions{HTTPHeader: header})
+	if conn != nil {
+		t.Cleanup(func() { _ = conn.CloseNow() })
+	}
+	return conn, resp, err
+}
+
+func TestWebSocket_RequiresAuth(t *testing.T) {
+	server := setupTestWebSocket(t, 0)
+
+	for _, token := range []string{"", "wrong-token"} {
+		_, resp, err := dialWebSocket(t, server, token)
+		if err == nil {
+			t.Fatalf("expected dial with token %q to fail", token)
+		}
+		if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected status 401 for token %q, got %v", token, resp)
+		}
+	}
+}
+
+func TestWebSocket_RejectsCrossOrigin(t *testing.T) {
+	server := setupTestWebSocket(t, 0)
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer alice-token")
+	header.Set("Origin", "https://evil.example")
+	_, resp, err := websocket.Dial(t.Context(), "ws"+strings.TrimPrefix(server.URL, "http"), &websocket.DialOptions{HTTPHeader: header})
+	if err == nil {
+		t.Fatal("expected cross-origin dial to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status 403, got %v", resp)
+	}
+}
+
+func TestWebSocket_RelaysMessages(t *testing.T) {
+	server := setupTestWebSocket(t, 0)
+
+	alice, _, err := dialWebSocket(t, server, "alice-token")
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	// The query parameter authenticates browsers that cannot set headers
+	bob, _, err := websocket.Dial(t.Context(), "ws"+strings.TrimPrefix(server.URL, "http")+"?access_token=bob-token", nil)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer bob.CloseNow()
+
+	// Wait until both sessions are registered by exchanging a message on each
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		if err := conn.Write(t.Context(), websocket.MessageText, []byte(`{"hello":true}`)); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+	}
+
+	var received []model.WebSocketMessage
+	for range 2 {
+		_, data, err := bob.Read(t.Context())
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		var message model.WebSocketMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+		received = append(received, message)
+	}
+
+	senders := map[string]bool{}
+	for _, message := range received {
+		senders[message.From] = true
+		if string(message.Data) != `{"hello":true}` {
+			t.Errorf("unexpected data %s", message.Data)
+		}
+	}
+	if !senders["bob"] {
+		t.Errorf("expected bob's own message to be relayed, got %+v", received)
+	}
+}
+
+func TestWebSocket_MessageLimits(t *testing.T) {
+	server := setupTestWebSocket(t, 16)
+
+	tests := map[string]struct {
+		message []byte
+		status  websocket.StatusCode
+	}{
+		"too large":    {[]byte(`{"data":"` + strings.Repeat("x", 32) + `"}`), websocket.StatusMessageTooBig},
+		"invalid JSON": {[]byte("not json"), websocket.StatusUnsupportedData},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conn, _, err := dialWebSocket(t, server, "alice-token")
+			if err != nil {
+				t.Fatalf("failed to dial: %v", err)
+			}
+			if err := conn.Write(t.Context(), websocket.MessageText, tt.message); err != nil {
+				t.Fatalf("failed to write: %v", err)
+			}
+
+			_, _, err = conn.Read(t.Context())
+			if status := websocket.CloseStatus(err); status != tt.status {
+				t.Errorf("expected close status %v, got %v", tt.status, err)
+			}
+		})
+	}
+}
+
+func TestWebhooks_UnavailableWithoutJobs(t *testing.T) {
+	h := setupTestHandler()
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(`{"url":"https://example.com/hook","events":["*"]}`))
+	rec := httptest.NewRecorder()
+
+	h.CreateWebhook(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+}
+
+func TestWebhooks_CRUD(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.New(logger, repository.New(logger), service.Options{Jobs: jobs.NewMemory()})
+	svc.Subscribe(outbox.NewMemory(), cache.NewMemory(10))
+	h := New(logger, svc, nil, 0)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/webhooks", h.CreateWebhook)
+	mux.HandleFunc("GET /api/webhooks", h.ListWebhooks)
+	mux.HandleFunc("GET /api/webhooks/{id}", h.GetWebhook)
+	mux.HandleFunc("PATCH /api/webhooks/{id}", h.UpdateWebhook)
+	mux.HandleFunc("DELETE /api/webhooks/{id}", h.DeleteWebhook)
+	mux.HandleFunc("GET /api/webhooks/{id}/deliveries", h.ListDeliveries)
+	mux.HandleFunc("POST /api/webhooks/{id}/deliveries/{delivery}/redeliver", h.Redeliver)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPost, "/api/webhooks", `{"url":"not a url","events":["*"]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid URL, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodPost, "/api/webhooks", `{"url":"https://example.com/hook","events":["example.created"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created model.Webhook
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Secret == "" || !created.Active {
+		t.Errorf("expected an active webhook with a secret, got %+v", created)
+	}
+	path := "/api/webhooks/" + created.ID
+
+	rec = do(http.MethodPatch, path, `{"active":false,"events":["*"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var updated model.Webhook
+	_ = json.NewDecoder(rec.Body).Decode(&updated)
+	if updated.Active || updated.Events[0] != "*" || updated.Secret != "" {
+		t.Errorf("expected an inactive webhook for all events without its secret, got %+v", updated)
+	}
+
+	rec = do(http.MethodGet, path+"/deliveries", "")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("expected an empty delivery log, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodGet, path+"/deliveries?limit=0", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid limit, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, path+"/deliveries/missing/redeliver", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 redelivering an unknown delivery, got %d", rec.Code)
+	}
+
+	if rec := do(http.MethodDelete, path, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 after delete, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/webhooks", ""); strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("expected no webhooks, got %s", rec.Body)
+	}
+}
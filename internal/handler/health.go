@@ -23,13 +23,11 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 		h.logger.ErrorContext(ctx, "health check failed",
 			slog.String("error", err.Error()),
 		)
-		h.writeJSON(w, http.StatusServiceUnavailable, &model.ErrorResponse{
-			Error: "service unhealthy",
-		})
+		h.writeError(w, r, http.StatusServiceUnavailable, "service unhealthy")
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, &model.HealthResponse{
+	h.writeJSON(w, r, http.StatusOK, &model.HealthResponse{
 		Status: "healthy",
 	})
 }
@@ -50,13 +48,11 @@ func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
 		h.logger.ErrorContext(ctx, "readiness check failed",
 			slog.String("error", err.Error()),
 		)
-		h.writeJSON(w, http.StatusServiceUnavailable, &model.ErrorResponse{
-			Error: "service not ready",
-		})
+		h.writeError(w, r, http.StatusServiceUnavailable, "service not ready")
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, &model.ReadyResponse{
+	h.writeJSON(w, r, http.StatusOK, &model.ReadyResponse{
 		Status: "ready",
 	})
 }
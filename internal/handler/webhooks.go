@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ahxar/go-backend-service/internal/model"
+	"github.com/ahxar/go-backend-service/internal/service"
+)
+
+// defaultDeliveryLimit and maxDeliveryLimit bound the deliveries listed per request
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// CreateWebhook handles webhook subscription
+// @Summary Create a webhook
+// @Description Subscribes an endpoint to event types ("*" for all). The response carries the signing secret, which is not returned again
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body model.WebhookInput true "Webhook to create"
+// @Param Idempotency-Key header string false "Key deduplicating retries of this request"
+// @Success 201 {object} model.Webhook
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/webhooks [post]
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var input model.WebhookInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	hook, err := h.service.CreateWebhook(r.Context(), input)
+	if err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/webhooks/"+hook.ID)
+	h.writeJSON(w, r, http.StatusCreated, hook)
+}
+
+// ListWebhooks handles webhook listing
+// @Summary List webhooks
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} model.Webhook
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/webhooks [get]
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.service.ListWebhooks(r.Context())
+	if err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, hooks)
+}
+
+// GetWebhook handles webhook retrieval
+// @Summary Get a webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} model.Webhook
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/webhooks/{id} [get]
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, err := h.service.GetWebhook(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, hook)
+}
+
+// UpdateWebhook handles webhook changes
+// @Summary Update a webhook
+// @Description Changes the given fields of a webhook; active: true re-enables a disabled webhook and clears its failure count
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body model.WebhookPatch true "Fields to change"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/webhooks/{id} [patch]
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var patch model.WebhookPatch
+	if err := decodeJSON(w, r, &patch); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	hook, err := h.service.UpdateWebhook(r.Context(), r.PathValue("id"), patch)
+	if err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, hook)
+}
+
+// DeleteWebhook handles webhook deletion
+// @Summary Delete a webhook
+// @Description Deletes a webhook and its delivery log
+// @Tags webhooks
+// @Param id path string true "Webhook ID"
+// @Success 204 "Deleted"
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteWebhook(r.Context(), r.PathValue("id")); err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles delivery log listing
+// @Summary List webhook deliveries
+// @Description Returns recent deliveries of a webhook with their attempts, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, at most 200)"
+// @Success 200 {array} model.WebhookDelivery
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/webhooks/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit := defaultDeliveryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			h.writeError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(n, maxDeliveryLimit)
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), r.PathValue("id"), limit)
+	if err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []model.WebhookDelivery{}
+	}
+	h.writeJSON(w, r, http.StatusOK, deliveries)
+}
+
+// GetDelivery handles delivery retrieval
+// @Summary Get a webhook delivery
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery path string true "Delivery ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/webhooks/{id}/deliveries/{delivery} [get]
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.service.GetDelivery(r.Context(), r.PathValue("id"), r.PathValue("delivery"))
+	if err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, delivery)
+}
+
+// Redeliver handles webhook redelivery
+// @Summary Redeliver a webhook event
+// @Description Sends the event of a delivery again as a new delivery with the same Webhook-Id
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery path string true "Delivery ID"
+// @Param Idempotency-Key header string false "Key deduplicating retries of this request"
+// @Success 202 {object} model.WebhookDelivery
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/webhooks/{id}/deliveries/{delivery}/redeliver [post]
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.service.Redeliver(r.Context(), r.PathValue("id"), r.PathValue("delivery"))
+	if err != nil {
+		h.writeWebhookError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/webhooks/"+delivery.WebhookID+"/deliveries/"+delivery.ID)
+	h.writeJSON(w, r, http.StatusAccepted, delivery)
+}
+
+// writeWebhookError maps webhook service errors to responses
+func (h *Handler) writeWebhookError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		h.writeError(w, r, http.StatusNotFound, "webhook or delivery not found")
+	case errors.Is(err, service.ErrWebhookDisabled):
+		h.writeError(w, r, http.StatusConflict, "webhook is disabled; re-enable it before redelivering")
+	case errors.Is(err, service.ErrWebhooksUnavailable):
+		h.writeError(w, r, http.StatusServiceUnavailable, "webhooks unavailable; background jobs or the outbox are disabled")
+	default:
+		h.writeServiceError(w, r, err)
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ahxar/go-backend-service/internal/model"
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+	"github.com/ahxar/go-backend-service/pkg/wshub"
+)
+
+// tracerName is the instrumentation scope of WebSocket message spans
+const tracerName = "github.com/ahxar/go-backend-service/internal/handler"
+
+// WebSocketConfig holds WebSocket endpoint configuration
+type WebSocketConfig struct {
+	// OriginPatterns lists extra origins allowed to connect; the request host is always allowed
+	OriginPatterns []string
+	// Tokens maps subjects to the bearer tokens that authenticate them
+	Tokens map[string]string
+	// MaxMessageBytes is the largest message accepted from a client
+	MaxMessageBytes int64
+}
+
+// WebSocket serves authenticated WebSocket sessions that relay messages to every session
+type WebSocket struct {
+	logger *slog.Logger
+	hub    *wshub.Hub
+	cfg    WebSocketConfig
+	tracer trace.Tracer
+}
+
+// NewWebSocket creates the WebSocket handler
+func NewWebSocket(logger *slog.Logger, hub *wshub.Hub, cfg WebSocketConfig) (*WebSocket, error) {
+	if len(cfg.Tokens) == 0 {
+		return nil, errors.New("websocket auth tokens are required")
+	}
+	if cfg.MaxMessageBytes <= 0 {
+		cfg.MaxMessageBytes = 32 << 10
+	}
+	return &WebSocket{
+		logger: logger,
+		hub:    hub,
+		cfg:    cfg,
+		tracer: otel.Tracer(tracerName),
+	}, nil
+}
+
+// ServeHTTP upgrades the request to a WebSocket session
+// @Summary WebSocket session
+// @Description Upgrades to a WebSocket. Authenticate with a bearer token in the Authorization header or the access_token query parameter. Every JSON message sent is relayed to all sessions
+// @Tags websocket
+// @Param access_token query string false "Bearer token for clients that cannot set headers"
+// @Success 101 {object} model.WebSocketMessage
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "origin not allowed"
+// @Router /api/ws [get]
+func (ws *WebSocket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Authenticate before upgrading so rejected clients get a plain HTTP error
+	subject, ok := ws.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="websocket"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns: ws.cfg.OriginPatterns,
+	})
+	if err != nil {
+		// Accept has already written the error response
+		ws.logger.WarnContext(ctx, "websocket upgrade failed",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(ws.cfg.MaxMessageBytes)
+
+	session, err := ws.hub.Register(conn, requestid.Generate(), subject)
+	if err != nil {
+		_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+		return
+	}
+	defer session.Unregister()
+
+	logger := ws.logger.With(
+		slog.String("session", session.ID),
+		slog.String("subject", subject),
+	)
+	logger.InfoContext(ctx, "websocket session opened")
+
+	// The request context ends with the handler; the session outlives neither
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Message spans link to the upgrade request instead of nesting under a span lasting the whole session
+	upgrade := trace.SpanContextFromContext(ctx)
+
+	go func() {
+		defer cancel()
+		if err := session.Run(ctx); err != nil && ctx.Err() == nil {
+			logger.DebugContext(ctx, "websocket writer stopped",
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			logger.InfoContext(ctx, "websocket session closed",
+				slog.Int("status", int(websocket.CloseStatus(err))),
+			)
+			return
+		}
+		if err := ws.handleMessage(session, upgrade, data); err != nil {
+			_ = conn.Close(websocket.StatusUnsupportedData, "messages must be JSON")
+			return
+		}
+	}
+}
+
+// handleMessage relays a client message to every session within its own span
+func (ws *WebSocket) handleMessage(session *wshub.Session, upgrade trace.SpanContext, data []byte) error {
+	ctx, span := ws.tracer.Start(context.Background(), "websocket.message",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithLinks(trace.Link{SpanContext: upgrade}),
+		trace.WithAttributes(
+			attribute.String("websocket.session", session.ID),
+			attribute.String("enduser.id", session.Subject),
+			attribute.Int("messaging.message.body.size", len(data)),
+		),
+	)
+	defer span.End()
+
+	if !json.Valid(data) {
+		err := errors.New("message is not valid JSON")
+		span.SetStatus(codes.Error, err.Error())
+		ws.logger.WarnContext(ctx, "invalid websocket message",
+			slog.String("session", session.ID),
+		)
+		return err
+	}
+
+	message, err := json.Marshal(&model.WebSocketMessage{
+		From:      session.Subject,
+		Session:   session.ID,
+		Data:      data,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	ws.hub.Broadcast(message)
+	return nil
+}
+
+// authenticate returns the subject of the bearer token in the Authorization
+// header or, for browsers that cannot set headers, the access_token query parameter
+func (ws *WebSocket) authenticate(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("access_token")
+	}
+	if token == "" {
+		return "", false
+	}
+
+	// Compare against every token so timing does not reveal which subject matched
+	subject := ""
+	for candidate, expected := range ws.cfg.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			subject = candidate
+		}
+	}
+	return subject, subject != ""
+}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/httpcapture"
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// Access log formats
+const (
+	// AccessLogCommon is the NCSA Common Log Format
+	AccessLogCommon = "common"
+	// AccessLogCombined is the Common Log Format with referer and user agent
+	AccessLogCombined = "combined"
+)
+
+// Templates of the predefined formats
+const (
+	commonTemplate   = `{remote_addr} - {remote_user} [{time}] "{method} {uri} {protocol}" {status} {bytes_clf}`
+	combinedTemplate = commonTemplate + ` "{referer}" "{user_agent}"`
+)
+
+// accessLogEntry holds the values available to access log templates
+type accessLogEntry struct {
+	r        *http.Request
+	start    time.Time
+	duration time.Duration
+	status   int
+	bytes    int64
+	ttfb     time.Duration
+	route    string
+}
+
+// accessLogFields maps template placeholders to their values
+var accessLogFields = map[string]func(e *accessLogEntry) string{
+	"remote_addr": func(e *accessLogEntry) string {
+		if host, _, err := net.SplitHostPort(e.r.RemoteAddr); err == nil {
+			return host
+		}
+		return e.r.RemoteAddr
+	},
+	"remote_user": func(e *accessLogEntry) string {
+		if user, _, ok := e.r.BasicAuth(); ok && user != "" {
+			return escapeField(user)
+		}
+		return "-"
+	},
+	"time":        func(e *accessLogEntry) string { return e.start.Format("02/Jan/2006:15:04:05 -0700") },
+	"time_iso":    func(e *accessLogEntry) string { return e.start.Format(time.RFC3339) },
+	"method":      func(e *accessLogEntry) string { return e.r.Method },
+	"uri":         func(e *accessLogEntry) string { return escapeField(maskQuery(e.r.URL).RequestURI()) },
+	"path":        func(e *accessLogEntry) string { return escapeField(e.r.URL.Path) },
+	"protocol":    func(e *accessLogEntry) string { return e.r.Proto },
+	"host":        func(e *accessLogEntry) string { return escapeField(e.r.Host) },
+	"status":      func(e *accessLogEntry) string { return strconv.Itoa(e.status) },
+	"bytes":       func(e *accessLogEntry) string { return strconv.FormatInt(e.bytes, 10) },
+	"bytes_clf":   func(e *accessLogEntry) string { return orDash(e.bytes) },
+	"duration":    func(e *accessLogEntry) string { return e.duration.String() },
+	"duration_ms": func(e *accessLogEntry) string { return strconv.FormatInt(e.duration.Milliseconds(), 10) },
+	"ttfb_ms":     func(e *accessLogEntry) string { return strconv.FormatInt(e.ttfb.Milliseconds(), 10) },
+	"referer":     func(e *accessLogEntry) string { return escapeOrDash(e.r.Referer()) },
+	"user_agent":  func(e *accessLogEntry) string { return escapeOrDash(e.r.UserAgent()) },
+	"request_id":  func(e *accessLogEntry) string { return escapeOrDash(requestid.FromContext(e.r.Context())) },
+	"trace_id":    func(e *accessLogEntry) string { return escapeOrDash(GetTraceID(e.r.Context())) },
+	"route":       func(e *accessLogEntry) string { return escapeField(e.route) },
+}
+
+// accessLogSegment is literal text or, when field is set, a placeholder
+type accessLogSegment struct {
+	literal string
+	field   func(e *accessLogEntry) string
+}
+
+// AccessLogger writes one line per request in a standard or templated format
+type AccessLogger struct {
+	w        io.Writer
+	segments []accessLogSegment
+	exclude  map[string]bool
+}
+
+// NewAccessLogger parses the format, which is common, combined or a template
+// with {placeholder} fields such as {remote_addr} {status} {duration_ms}
+// Requests to excludeRoutes, given as patterns ("GET /health") or paths ("/health"), are not logged
+func NewAccessLogger(w io.Writer, format string, excludeRoutes []string) (*AccessLogger, error) {
+	switch format {
+	case AccessLogCommon:
+		format = commonTemplate
+	case AccessLogCombined, "":
+		format = combinedTemplate
+	}
+
+	segments, err := parseAccessLogTemplate(format)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := make(map[string]bool, len(excludeRoutes))
+	for _, route := range excludeRoutes {
+		exclude[route] = true
+	}
+
+	return &AccessLogger{w: w, segments: segments, exclude: exclude}, nil
+}
+
+// parseAccessLogTemplate splits a template into literals and known placeholders
+func parseAccessLogTemplate(format string) ([]accessLogSegment, error) {
+	var segments []accessLogSegment
+	for format != "" {
+		start := strings.IndexByte(format, '{')
+		if start < 0 {
+			segments = append(segments, accessLogSegment{literal: format})
+			break
+		}
+		if start > 0 {
+			segments = append(segments, accessLogSegment{literal: format[:start]})
+		}
+
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder in access log format %q", format)
+		}
+		name := format[start+1 : start+end]
+		field, ok := accessLogFields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown access log field {%s}", name)
+		}
+		segments = append(segments, accessLogSegment{field: field})
+		format = format[start+end+1:]
+	}
+	return segments, nil
+}
+
+// Middleware logs each request after it completes
+// The mux is used to resolve the route pattern for exclusion and the {route} field
+func (l *AccessLogger) Middleware(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := mux.Handler(r)
+			if l.excluded(route) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			// Wrap response writer to capture status code and bytes written
+			wrapped := httpcapture.Wrap(w)
+
+			next.ServeHTTP(wrapped, r)
+
+			l.write(&accessLogEntry{
+				r:        r,
+				start:    start,
+				duration: time.Since(start),
+				status:   wrapped.Status(),
+				bytes:    wrapped.BytesWritten(),
+				ttfb:     wrapped.TimeToFirstByte(),
+				route:    route,
+			})
+		})
+	}
+}
+
+// excluded reports whether requests to the route are not logged
+func (l *AccessLogger) excluded(route string) bool {
+	if l.exclude[route] {
+		return true
+	}
+	_, path, ok := strings.Cut(route, " ")
+	return ok && l.exclude[path]
+}
+
+// write renders the entry and writes it as a single line
+func (l *AccessLogger) write(e *accessLogEntry) {
+	var b strings.Builder
+	for _, segment := range l.segments {
+		if segment.field != nil {
+			b.WriteString(segment.field(e))
+		} else {
+			b.WriteString(segment.literal)
+		}
+	}
+	b.WriteByte('\n')
+
+	// Access logging must never fail a request
+	_, _ = io.WriteString(l.w, b.String())
+}
+
+// escapeField escapes quotes, backslashes and control characters so a
+// client-supplied value cannot break the line format
+func escapeField(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { return r == '"' || r == '\\' || r < 0x20 || r == 0x7f }) {
+		return s
+	}
+	quoted := strconv.Quote(s)
+	return quoted[1 : len(quoted)-1]
+}
+
+func escapeOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return escapeField(s)
+}
+
+func orDash(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(n, 10)
+}
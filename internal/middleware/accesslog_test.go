@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+func setupAccessLogMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("GET /api/example", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello world"))
+	})
+	return mux
+}
+
+func TestAccessLogger_Combined(t *testing.T) {
+	var buf bytes.Buffer
+	mux := setupAccessLogMux()
+	accessLog, err := NewAccessLogger(&buf, AccessLogCombined, nil)
+	if err != nil {
+		t.Fatalf("failed to create access logger: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/example?name=x", http.NoBody)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.SetBasicAuth("jane", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", `curl/8.0 "quoted"`)
+	rec := httptest.NewRecorder()
+
+	accessLog.Middleware(mux)(mux).ServeHTTP(rec, req)
+
+	want := regexp.MustCompile(`^203\.0\.113\.7 - jane \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /api/example\?name=x HTTP/1\.1" 201 11 "https://example\.com/" "curl/8\.0 \\"quoted\\""\n$`)
+	if !want.MatchString(buf.String()) {
+		t.Errorf("unexpected combined log line %q", buf.String())
+	}
+}
+
+func TestAccessLogger_Template(t *testing.T) {
+	var buf bytes.Buffer
+	mux := setupAccessLogMux()
+	accessLog, err := NewAccessLogger(&buf, "{method} {route} {status} {bytes} {request_id} {referer}", nil)
+	if err != nil {
+		t.Fatalf("failed to create access logger: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/example", http.NoBody)
+	req = req.WithContext(requestid.NewContext(req.Context(), "req-1"))
+	rec := httptest.NewRecorder()
+
+	accessLog.Middleware(mux)(mux).ServeHTTP(rec, req)
+
+	if got := buf.String(); got != "GET GET /api/example 201 11 req-1 -\n" {
+		t.Errorf("unexpected templated log line %q", got)
+	}
+}
+
+func TestAccessLogger_MasksAccessToken(t *testing.T) {
+	var buf bytes.Buffer
+	mux := setupAccessLogMux()
+	accessLog, err := NewAccessLogger(&buf, "{uri}", nil)
+	if err != nil {
+		t.Fatalf("failed to create access logger: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/example?name=x&access_token=s3cret", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	accessLog.Middleware(mux)(mux).ServeHTTP(rec, req)
+
+	if got := buf.String(); got != "/api/example?access_token=REDACTED&name=x\n" {
+		t.Errorf("unexpected uri %q", got)
+	}
+}
+
+func TestAccessLogger_ExcludedRoute(t *testing.T) {
+	var buf bytes.Buffer
+	mux := setupAccessLogMux()
+	accessLog, err := NewAccessLogger(&buf, AccessLogCommon, []string{"/health"})
+	if err != nil {
+		t.Fatalf("failed to create access logger: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	accessLog.Middleware(mux)(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected excluded route not to be logged, got %q", buf.String())
+	}
+}
+
+func TestNewAccessLogger_InvalidTemplate(t *testing.T) {
+	tests := []string{"{method} {nope}", "{method"}
+
+	for _, format := range tests {
+		if _, err := NewAccessLogger(&bytes.Buffer{}, format, nil); err == nil {
+			t.Errorf("expected error for format %q", format)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CacheControl sets the Cache-Control policy of the matched route before the handler runs
+// Policies are keyed by route pattern ("GET /api/example") or path ("/api/example");
+// defaultPolicy applies to other routes and may be empty. Handlers may override the header
+func CacheControl(policies map[string]string, defaultPolicy string, mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := mux.Handler(r)
+
+			policy, ok := policies[route]
+			if !ok {
+				if _, path, found := strings.Cut(route, " "); found {
+					policy, ok = policies[path]
+				}
+			}
+			if !ok {
+				policy = defaultPolicy
+			}
+			if policy != "" {
+				w.Header().Set("Cache-Control", policy)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheControl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /api/example", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /api/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+	})
+
+	policies := map[string]string{
+		"/health":          "no-store",
+		"GET /api/example": "public, max-age=60",
+	}
+	h := CacheControl(policies, "private", mux)(mux)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/health", "no-store"},
+		{"/api/example?name=test", "public, max-age=60"},
+		{"/api/events", "no-cache"},
+		{"/missing", "private"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("expected Cache-Control %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
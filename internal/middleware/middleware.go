@@ -2,10 +2,17 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/ahxar/go-backend-service/internal/model"
+	"github.com/ahxar/go-backend-service/pkg/httpcapture"
+	"github.com/ahxar/go-backend-service/pkg/logger"
+	"github.com/ahxar/go-backend-service/pkg/profiling"
+	"github.com/ahxar/go-backend-service/pkg/requestid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -29,7 +36,10 @@ func Recovery(logger *slog.Logger) func(http.Handler) http.Handler {
 
 					w.Header().Set("Content-Type", "application/json")
 					w.WriteHeader(http.StatusInternalServerError)
-					if _, err := w.Write([]byte(`{"error":"internal server error"}`)); err != nil {
+					if err := json.NewEncoder(w).Encode(&model.ErrorResponse{
+						Error:     "internal server error",
+						RequestID: requestid.FromContext(ctx),
+					}); err != nil {
 						logger.ErrorContext(ctx, "failed to write error response", slog.Any("error", err))
 					}
 				}
@@ -40,49 +50,71 @@ func Recovery(logger *slog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// RequestID accepts a valid incoming X-Request-ID or generates one, stores it
+// in the request context and log attributes and echoes it in the response header
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.Generate()
+			}
+
+			ctx := requestid.NewContext(r.Context(), id)
+			ctx = logger.WithAttrs(ctx, slog.String("request_id", id))
+			w.Header().Set(requestid.Header, id)
+
+			// Record the request ID on the span so traces can be found by it
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", id))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Route attaches the matched route pattern to the request's log attributes
+// The mux is used to resolve the pattern before it routes the request
+func Route(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := mux.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			ctx := logger.WithAttrs(r.Context(), slog.String("route", route))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // Logging logs HTTP requests with duration and status
 func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
-			// Wrap response writer to capture status code
-			wrapped := &responseWriter{
-				ResponseWriter: w,
-				statusCode:     http.StatusOK,
-			}
+			// Wrap response writer to capture status code, reusing an outer wrapper
+			wrapped := httpcapture.Wrap(w)
 
 			next.ServeHTTP(wrapped, r)
 
 			duration := time.Since(start)
-			ctx := r.Context()
 
-			// Get trace ID from OpenTelemetry span
-			traceID := GetTraceID(ctx)
-
-			logger.InfoContext(ctx, "http request",
+			// Trace and span IDs are added by the logger from the request context
+			logger.InfoContext(r.Context(), "http request",
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
-				slog.Int("status", wrapped.statusCode),
+				slog.Int("status", wrapped.Status()),
+				slog.Int64("bytes", wrapped.BytesWritten()),
 				slog.Duration("duration", duration),
+				slog.Duration("ttfb", wrapped.TimeToFirstByte()),
 				slog.String("remote_addr", r.RemoteAddr),
-				slog.String("trace_id", traceID),
 			)
 		})
 	}
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
-
 // GetTraceID extracts the OpenTelemetry trace ID from context
 func GetTraceID(ctx context.Context) string {
 	span := trace.SpanFromContext(ctx)
@@ -92,6 +124,31 @@ func GetTraceID(ctx context.Context) string {
 	return ""
 }
 
+// sensitiveQueryParams carry credentials, such as WebSocket bearer tokens,
+// and are masked wherever request URLs are recorded
+var sensitiveQueryParams = []string{"access_token"}
+
+// maskQuery returns u with the values of sensitive query parameters masked
+func maskQuery(u *url.URL) *url.URL {
+	if u.RawQuery == "" {
+		return u
+	}
+	query := u.Query()
+	masked := false
+	for _, param := range sensitiveQueryParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+			masked = true
+		}
+	}
+	if !masked {
+		return u
+	}
+	out := *u
+	out.RawQuery = query.Encode()
+	return &out
+}
+
 // Tracing creates OpenTelemetry traces for HTTP requests
 func Tracing(serviceName string) func(http.Handler) http.Handler {
 	tracer := otel.Tracer(serviceName)
@@ -107,7 +164,7 @@ func Tracing(serviceName string) func(http.Handler) http.Handler {
 				trace.WithSpanKind(trace.SpanKindServer),
 				trace.WithAttributes(
 					attribute.String("http.method", r.Method),
-					attribute.String("http.url", r.URL.String()),
+					attribute.String("http.url", maskQuery(r.URL).String()),
 					attribute.String("http.scheme", r.URL.Scheme),
 					attribute.String("http.host", r.Host),
 					attribute.String("http.target", r.URL.Path),
@@ -123,25 +180,45 @@ func Tracing(serviceName string) func(http.Handler) http.Handler {
 			}
 
 			// Wrap response writer to capture status code
-			wrapped := &responseWriter{
-				ResponseWriter: w,
-				statusCode:     http.StatusOK,
-			}
+			wrapped := httpcapture.Wrap(w)
 
 			// Serve the request
 			next.ServeHTTP(wrapped, r.WithContext(ctx))
 
 			// Add response attributes
 			span.SetAttributes(
-				attribute.Int("http.status_code", wrapped.statusCode),
+				attribute.Int("http.status_code", wrapped.Status()),
+				attribute.Int64("http.response_content_length", wrapped.BytesWritten()),
 			)
 
 			// Set span status based on HTTP status code
-			if wrapped.statusCode >= 400 {
-				span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+			if wrapped.Status() >= 400 {
+				span.SetStatus(codes.Error, http.StatusText(wrapped.Status()))
 			} else {
 				span.SetStatus(codes.Ok, "")
 			}
 		})
 	}
 }
+
+// Profiling labels request goroutines with the route and trace for profiles
+// and reports request latency to the profiler's threshold trigger
+// The mux is used to resolve the route pattern so labels stay low-cardinality
+func Profiling(profiler *profiling.Profiler, mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			_, route := mux.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			profiling.Do(r.Context(), route, r.Method, GetTraceID(r.Context()), func(ctx context.Context) {
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+
+			profiler.ObserveLatency(time.Since(start))
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // ExampleRequest represents an example API request
 type ExampleRequest struct {
@@ -14,6 +17,30 @@ type ExampleResponse struct {
 	Processed bool      `json:"processed"`
 }
 
+// Example is a stored example resource
+// Version starts at 1 and increases with every change; clients send it back
+// in If-Match so concurrent updates cannot overwrite each other
+type Example struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Version     int64     `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ExampleInput holds the fields of an example set on create and replace
+type ExampleInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ExamplePatch holds the fields of an example to change; omitted fields are kept
+type ExamplePatch struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
 // HealthResponse represents a health check response
 type HealthResponse struct {
 	Status string `json:"status"`
@@ -26,5 +53,14 @@ type ReadyResponse struct {
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WebSocketMessage is a client message relayed to every WebSocket session
+type WebSocketMessage struct {
+	From      string          `json:"from"`
+	Session   string          `json:"session"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	Timestamp time.Time       `json:"timestamp"`
 }
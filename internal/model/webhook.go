@@ -0,0 +1,73 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a subscription delivering events to a customer endpoint
+// Webhooks that keep failing are disabled until they are re-enabled with active: true
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Events lists the event types delivered, or "*" for all
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	// Secret signs deliveries; it is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+	Active bool   `json:"active"`
+	// ConsecutiveFailures counts failed attempts since the last successful delivery
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WebhookInput holds the fields of a webhook set on create
+type WebhookInput struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+}
+
+// WebhookPatch holds the fields of a webhook to change; omitted fields are kept
+type WebhookPatch struct {
+	URL         *string   `json:"url,omitempty"`
+	Events      *[]string `json:"events,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	// Active set to true re-enables a disabled webhook
+	Active *bool `json:"active,omitempty"`
+}
+
+// WebhookDelivery is an event sent to a webhook with the log of its attempts
+type WebhookDelivery struct {
+	ID        string `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	// EventID is sent as Webhook-Id and is the same for redeliveries of the event
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	Status    string          `json:"status"`
+	// RedeliveryOf is the ID of the delivery this one repeats
+	RedeliveryOf string           `json:"redelivery_of,omitempty"`
+	Attempts     []WebhookAttempt `json:"attempts"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// WebhookAttempt is one try of a delivery
+type WebhookAttempt struct {
+	At time.Time `json:"at"`
+	// StatusCode is omitted when the endpoint could not be reached
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
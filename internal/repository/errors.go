@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+// ErrNotFound is returned when the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrVersionConflict is returned when a write's expected version is not the stored one
+var ErrVersionConflict = errors.New("record version conflict")
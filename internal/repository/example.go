@@ -2,11 +2,25 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"time"
+
+	"github.com/ahxar/go-backend-service/internal/model"
 )
 
+// AnyVersion skips the version check of a write, e.g. for If-Match: *
+const AnyVersion int64 = 0
+
 // ExampleRepository defines methods for example data access
+// Writes take the version the caller last read and fail with ErrVersionConflict
+// when it is no longer current, so implementations must compare and write atomically
 type ExampleRepository interface {
 	GetData(ctx context.Context, id string) (map[string]interface{}, error)
+	CreateExample(ctx context.Context, input model.ExampleInput) (*model.Example, error)
+	GetExample(ctx context.Context, id string) (*model.Example, error)
+	UpdateExample(ctx context.Context, id string, expectedVersion int64, input model.ExampleInput) (*model.Example, error)
+	DeleteExample(ctx context.Context, id string, expectedVersion int64) error
+	CountExamples(ctx context.Context) (int, error)
 }
 
 // GetData retrieves example data
@@ -30,3 +44,133 @@ func (r *Repository) GetData(ctx context.Context, id string) (map[string]interfa
 
 	return data, nil
 }
+
+// CreateExample stores a new example at version 1
+func (r *Repository) CreateExample(ctx context.Context, input model.ExampleInput) (*model.Example, error) {
+	var example *model.Example
+	err := r.WithTx(ctx, func(tx *Tx) error {
+		var err error
+		example, err = tx.CreateExample(ctx, input)
+		return err
+	})
+	return example, err
+}
+
+// GetExample retrieves an example by ID
+func (r *Repository) GetExample(ctx context.Context, id string) (*model.Example, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	example, ok := r.examples[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &example, nil
+}
+
+// UpdateExample replaces an example's fields and increments its version
+func (r *Repository) UpdateExample(ctx context.Context, id string, expectedVersion int64, input model.ExampleInput) (*model.Example, error) {
+	var example *model.Example
+	err := r.WithTx(ctx, func(tx *Tx) error {
+		var err error
+		example, err = tx.UpdateExample(ctx, id, expectedVersion, input)
+		return err
+	})
+	return example, err
+}
+
+// DeleteExample removes an example if it is still at the expected version
+func (r *Repository) DeleteExample(ctx context.Context, id string, expectedVersion int64) error {
+	return r.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteExample(ctx, id, expectedVersion)
+	})
+}
+
+// CreateExample stores a new example at version 1
+// In production, this would be:
+// INSERT INTO examples (id, name, description, version) VALUES ($1, $2, $3, 1)
+func (tx *Tx) CreateExample(ctx context.Context, input model.ExampleInput) (*model.Example, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	example := model.Example{
+		ID:          rand.Text(),
+		Name:        input.Name,
+		Description: input.Description,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	tx.r.examples[example.ID] = example
+	tx.onRollback(func() { delete(tx.r.examples, example.ID) })
+
+	return &example, nil
+}
+
+// UpdateExample replaces an example's fields and increments its version
+// In production, the version check and write are one statement:
+// UPDATE examples SET name = $1, description = $2, version = version + 1
+// WHERE id = $3 AND version = $4
+// with zero affected rows meaning a missing record or a conflict
+func (tx *Tx) UpdateExample(ctx context.Context, id string, expectedVersion int64, input model.ExampleInput) (*model.Example, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	previous, ok := tx.r.examples[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if expectedVersion != AnyVersion && previous.Version != expectedVersion {
+		return nil, ErrVersionConflict
+	}
+
+	example := previous
+	example.Name = input.Name
+	example.Description = input.Description
+	example.Version++
+	example.UpdatedAt = time.Now().UTC()
+	tx.r.examples[id] = example
+	tx.onRollback(func() { tx.r.examples[id] = previous })
+
+	return &example, nil
+}
+
+// DeleteExample removes an example if it is still at the expected version
+// In production: DELETE FROM examples WHERE id = $1 AND version = $2
+func (tx *Tx) DeleteExample(ctx context.Context, id string, expectedVersion int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	previous, ok := tx.r.examples[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if expectedVersion != AnyVersion && previous.Version != expectedVersion {
+		return ErrVersionConflict
+	}
+
+	delete(tx.r.examples, id)
+	tx.onRollback(func() { tx.r.examples[id] = previous })
+	return nil
+}
+
+// CountExamples returns the number of stored examples
+// SELECT count(*) FROM examples
+func (r *Repository) CountExamples(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.examples), nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"slices"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+)
+
+// JobQueue is a jobs.Queue over the jobs table, so queued work is shared by
+// every replica and survives restarts once the repository is backed by a database
+//
+// In production the table would be:
+//
+//	CREATE TABLE jobs (
+//	    id           TEXT PRIMARY KEY,
+//	    type         TEXT NOT NULL,
+//	    payload      JSONB NOT NULL,
+//	    metadata     JSONB NOT NULL DEFAULT '{}',
+//	    attempts     INT NOT NULL DEFAULT 0,
+//	    max_attempts INT NOT NULL DEFAULT 0,
+//	    run_at       TIMESTAMPTZ NOT NULL,
+//	    locked_until TIMESTAMPTZ,
+//	    lease        TEXT,
+//	    last_error   TEXT,
+//	    dead_at      TIMESTAMPTZ,
+//	    created_at   TIMESTAMPTZ NOT NULL
+//	);
+//	CREATE INDEX jobs_due ON jobs (run_at) WHERE dead_at IS NULL;
+type JobQueue struct {
+	r *Repository
+}
+
+// jobRow is a row of the jobs table
+type jobRow struct {
+	job         jobs.Job
+	lockedUntil time.Time
+	deadAt      time.Time
+}
+
+// Jobs returns the queue stored in the repository
+func (r *Repository) Jobs() *JobQueue {
+	return &JobQueue{r: r}
+}
+
+// Enqueue inserts a job
+// INSERT INTO jobs (id, type, payload, metadata, max_attempts, run_at, created_at) VALUES (...)
+func (q *JobQueue) Enqueue(ctx context.Context, job *jobs.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	q.r.mu.Lock()
+	defer q.r.mu.Unlock()
+	q.r.jobs[job.ID] = &jobRow{job: *job}
+	return nil
+}
+
+// Claim leases the earliest due job under a new lease token; concurrent
+// workers skip rows already locked:
+//
+//	UPDATE jobs SET locked_until = now() + $1, lease = $2, attempts = attempts + 1
+//	WHERE id = (
+//	    SELECT id FROM jobs
+//	    WHERE dead_at IS NULL AND run_at <= now()
+//	      AND (locked_until IS NULL OR locked_until <= now())
+//	    ORDER BY run_at LIMIT 1
+//	    FOR UPDATE SKIP LOCKED
+//	)
+//	RETURNING *
+func (q *JobQueue) Claim(ctx context.Context, lease time.Duration) (*jobs.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	q.r.mu.Lock()
+	defer q.r.mu.Unlock()
+
+	now := time.Now()
+	var next *jobRow
+	for _, row := range q.r.jobs {
+		if !row.deadAt.IsZero() || row.job.RunAt.After(now) || row.lockedUntil.After(now) {
+			continue
+		}
+		if next == nil || row.job.RunAt.Before(next.job.RunAt) {
+			next = row
+		}
+	}
+	if next == nil {
+		return nil, jobs.ErrNoJob
+	}
+
+	next.lockedUntil = now.Add(lease)
+	next.job.Attempts++
+	next.job.Lease = rand.Text()
+	job := next.job
+	return &job, nil
+}
+
+// Complete deletes a finished job if the caller still holds its lease
+// DELETE FROM jobs WHERE id = $1 AND lease = $2
+// No affected row means the lease was lost
+func (q *JobQueue) Complete(ctx context.Context, id, lease string) error {
+	q.r.mu.Lock()
+	defer q.r.mu.Unlock()
+
+	if _, ok := q.leased(id, lease); !ok {
+		return jobs.ErrLeaseLost
+	}
+	delete(q.r.jobs, id)
+	return nil
+}
+
+// Retry releases a job to run again if the caller still holds its lease
+// UPDATE jobs SET run_at = $3, last_error = $4, locked_until = NULL, lease = NULL WHERE id = $1 AND lease = $2
+func (q *JobQueue) Retry(ctx context.Context, id, lease string, runAt time.Time, lastErr string) error {
+	q.r.mu.Lock()
+	defer q.r.mu.Unlock()
+
+	row, ok := q.leased(id, lease)
+	if !ok {
+		return jobs.ErrLeaseLost
+	}
+	row.job.RunAt = runAt
+	row.job.LastError = lastErr
+	row.job.Lease = ""
+	row.lockedUntil = time.Time{}
+	return nil
+}
+
+// Fail marks a job as dead, keeping it for inspection, if the caller still holds its lease
+// UPDATE jobs SET dead_at = now(), last_error = $3, locked_until = NULL, lease = NULL WHERE id = $1 AND lease = $2
+func (q *JobQueue) Fail(ctx context.Context, id, lease string, lastErr string) error {
+	q.r.mu.Lock()
+	defer q.r.mu.Unlock()
+
+	row, ok := q.leased(id, lease)
+	if !ok {
+		return jobs.ErrLeaseLost
+	}
+	row.job.LastError = lastErr
+	row.job.Lease = ""
+	row.deadAt = time.Now()
+	row.lockedUntil = time.Time{}
+	return nil
+}
+
+// leased returns the live row for id if lease is its current claim
+func (q *JobQueue) leased(id, lease string) (*jobRow, bool) {
+	row, ok := q.r.jobs[id]
+	if !ok || !row.deadAt.IsZero() || row.job.Lease == "" || row.job.Lease != lease {
+		return nil, false
+	}
+	return row, true
+}
+
+// DeadLetters lists dead jobs
+// SELECT * FROM jobs WHERE dead_at IS NOT NULL ORDER BY dead_at DESC LIMIT $1
+func (q *JobQueue) DeadLetters(ctx context.Context, limit int) ([]jobs.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	q.r.mu.RLock()
+	defer q.r.mu.RUnlock()
+
+	var dead []*jobRow
+	for _, row := range q.r.jobs {
+		if !row.deadAt.IsZero() {
+			dead = append(dead, row)
+		}
+	}
+	slices.SortFunc(dead, func(a, b *jobRow) int { return b.deadAt.Compare(a.deadAt) })
+	if limit > 0 && len(dead) > limit {
+		dead = dead[:limit]
+	}
+
+	out := make([]jobs.Job, len(dead))
+	for i, row := range dead {
+		out[i] = row.job
+	}
+	return out, nil
+}
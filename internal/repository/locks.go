@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// LockRepository defines methods for leased locks shared by replicas
+// Implementations must check and take a lock atomically
+type LockRepository interface {
+	TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context, name, owner string) error
+}
+
+// lockRow is a row of the locks table
+type lockRow struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// TryLock takes name for owner, or extends it if owner already holds it,
+// unless another owner's lease is still valid
+// In production this is one statement, holding the lock when a row is affected:
+//
+//	INSERT INTO locks (name, owner, expires_at) VALUES ($1, $2, now() + $3)
+//	ON CONFLICT (name) DO UPDATE SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at
+//	WHERE locks.owner = EXCLUDED.owner OR locks.expires_at <= now()
+func (r *Repository) TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if lock, ok := r.locks[name]; ok && lock.owner != owner && now.Before(lock.expiresAt) {
+		return false, nil
+	}
+	r.locks[name] = lockRow{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Unlock releases name if owner holds it
+// DELETE FROM locks WHERE name = $1 AND owner = $2
+func (r *Repository) Unlock(ctx context.Context, name, owner string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lock, ok := r.locks[name]; ok && lock.owner == owner {
+		delete(r.locks, name)
+	}
+	return nil
+}
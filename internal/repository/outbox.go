@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/outbox"
+)
+
+// OutboxStore is an outbox.Store over the outbox table, which is written in
+// the same transaction as the changes its messages describe
+//
+// In production the table would be:
+//
+//	CREATE TABLE outbox (
+//	    seq          BIGSERIAL PRIMARY KEY,
+//	    id           TEXT NOT NULL UNIQUE,
+//	    topic        TEXT NOT NULL,
+//	    type         TEXT NOT NULL,
+//	    key          TEXT NOT NULL,
+//	    payload      JSONB NOT NULL,
+//	    headers      JSONB NOT NULL DEFAULT '{}',
+//	    created_at   TIMESTAMPTZ NOT NULL,
+//	    attempts     INT NOT NULL DEFAULT 0,
+//	    parked_at    TIMESTAMPTZ,
+//	    published_at TIMESTAMPTZ
+//	);
+//	CREATE INDEX outbox_pending ON outbox (seq) WHERE published_at IS NULL AND parked_at IS NULL;
+type OutboxStore struct {
+	r *Repository
+}
+
+// outboxRow is a row of the outbox table
+type outboxRow struct {
+	msg         outbox.Message
+	attempts    int
+	parkedAt    time.Time
+	publishedAt time.Time
+}
+
+// Outbox returns the outbox stored in the repository
+func (r *Repository) Outbox() *OutboxStore {
+	return &OutboxStore{r: r}
+}
+
+// AddOutbox records a message to be relayed once the transaction commits
+// INSERT INTO outbox (id, topic, type, key, payload, headers, created_at) VALUES (...)
+func (tx *Tx) AddOutbox(ctx context.Context, msg outbox.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx.r.outbox = append(tx.r.outbox, &outboxRow{msg: msg})
+	tx.onRollback(func() {
+		tx.r.outbox = slices.DeleteFunc(tx.r.outbox, func(row *outboxRow) bool {
+			return row.msg.ID == msg.ID
+		})
+	})
+	return nil
+}
+
+// Pending returns the oldest unpublished messages in commit order, skipping parked ones
+// SELECT ... FROM outbox WHERE published_at IS NULL AND parked_at IS NULL ORDER BY seq LIMIT $1
+// Relays on several replicas may publish a message twice, which consumers
+// tolerate by deduplicating on its ID
+func (s *OutboxStore) Pending(ctx context.Context, limit int) ([]outbox.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	var msgs []outbox.Message
+	for _, row := range s.r.outbox {
+		if len(msgs) == limit {
+			break
+		}
+		if row.publishedAt.IsZero() && row.parkedAt.IsZero() {
+			msg := row.msg
+			msg.Attempts = row.attempts
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+// MarkPublished records messages as relayed
+// UPDATE outbox SET published_at = now() WHERE id = ANY($1)
+func (s *OutboxStore) MarkPublished(ctx context.Context, ids []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	now := time.Now()
+	for _, row := range s.r.outbox {
+		if row.publishedAt.IsZero() && slices.Contains(ids, row.msg.ID) {
+			row.publishedAt = now
+		}
+	}
+	return nil
+}
+
+// MarkFailed counts a failed publish, parking the message if park is set
+// UPDATE outbox SET attempts = attempts + 1, parked_at = CASE WHEN $2 THEN now() END WHERE id = $1
+func (s *OutboxStore) MarkFailed(ctx context.Context, id string, park bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	for _, row := range s.r.outbox {
+		if row.msg.ID == id {
+			row.attempts++
+			if park {
+				row.parkedAt = time.Now()
+			}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Purge deletes messages published before cutoff and returns how many were removed
+// DELETE FROM outbox WHERE published_at < $1
+func (s *OutboxStore) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	before := len(s.r.outbox)
+	s.r.outbox = slices.DeleteFunc(s.r.outbox, func(row *outboxRow) bool {
+		return !row.publishedAt.IsZero() && row.publishedAt.Before(cutoff)
+	})
+	return before - len(s.r.outbox), nil
+}
@@ -2,16 +2,34 @@ package repository
 
 import (
 	"log/slog"
+	"sync"
+
+	"github.com/ahxar/go-backend-service/internal/model"
 )
 
 // Repository provides data access methods
 type Repository struct {
 	logger *slog.Logger
+
+	// mu guards the in-memory stand-ins for the examples, jobs, locks, outbox and webhook tables
+	mu         sync.RWMutex
+	examples   map[string]model.Example
+	jobs       map[string]*jobRow
+	locks      map[string]lockRow
+	webhooks   map[string]model.Webhook
+	deliveries map[string]*model.WebhookDelivery
+	// outbox holds rows in commit order
+	outbox []*outboxRow
 }
 
 // New creates a new Repository instance
 func New(logger *slog.Logger) *Repository {
 	return &Repository{
-		logger: logger,
+		logger:     logger,
+		examples:   make(map[string]model.Example),
+		jobs:       make(map[string]*jobRow),
+		locks:      make(map[string]lockRow),
+		webhooks:   make(map[string]model.Webhook),
+		deliveries: make(map[string]*model.WebhookDelivery),
 	}
 }
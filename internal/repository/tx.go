@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+)
+
+// Tx is a unit of work over the repository
+// Writes made through it, including outbox messages, are committed together or not at all
+type Tx struct {
+	r *Repository
+	// undo reverts the writes made so far, applied in reverse on rollback
+	undo []func()
+}
+
+// WithTx runs fn in a transaction, committing when it returns nil and rolling back otherwise
+// In production this wraps db.BeginTx with COMMIT or ROLLBACK; the in-memory
+// stand-in serializes transactions and keeps an undo log
+func (r *Repository) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &Tx{r: r}
+	if err := fn(tx); err != nil {
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		return err
+	}
+	return nil
+}
+
+// onRollback records how to revert a write
+func (tx *Tx) onRollback(undo func()) {
+	tx.undo = append(tx.undo, undo)
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ahxar/go-backend-service/internal/model"
+)
+
+// WebhookRepository defines methods for webhook subscriptions and their delivery logs
+//
+// In production the tables would be:
+//
+//	CREATE TABLE webhooks (
+//	    id                   TEXT PRIMARY KEY,
+//	    url                  TEXT NOT NULL,
+//	    events               TEXT[] NOT NULL,
+//	    description          TEXT NOT NULL DEFAULT '',
+//	    secret               TEXT NOT NULL,
+//	    active               BOOLEAN NOT NULL DEFAULT true,
+//	    consecutive_failures INT NOT NULL DEFAULT 0,
+//	    disabled_at          TIMESTAMPTZ,
+//	    disabled_reason      TEXT NOT NULL DEFAULT '',
+//	    created_at           TIMESTAMPTZ NOT NULL,
+//	    updated_at           TIMESTAMPTZ NOT NULL
+//	);
+//	CREATE TABLE webhook_deliveries (
+//	    id            TEXT PRIMARY KEY,
+//	    webhook_id    TEXT NOT NULL REFERENCES webhooks ON DELETE CASCADE,
+//	    event_id      TEXT NOT NULL,
+//	    event_type    TEXT NOT NULL,
+//	    payload       JSONB NOT NULL,
+//	    status        TEXT NOT NULL,
+//	    redelivery_of TEXT,
+//	    attempts      JSONB NOT NULL DEFAULT '[]',
+//	    created_at    TIMESTAMPTZ NOT NULL,
+//	    updated_at    TIMESTAMPTZ NOT NULL
+//	);
+//	CREATE UNIQUE INDEX webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id)
+//	    WHERE redelivery_of IS NULL;
+//	CREATE INDEX webhook_deliveries_recent ON webhook_deliveries (webhook_id, created_at DESC);
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, input model.WebhookInput, secret string) (*model.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, patch model.WebhookPatch) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	RecordWebhookResult(ctx context.Context, id string, succeeded bool, disableAfter int) (bool, error)
+	CreateDelivery(ctx context.Context, delivery model.WebhookDelivery) (*model.WebhookDelivery, bool, error)
+	GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, id string, attempt model.WebhookAttempt, status string) (*model.WebhookDelivery, error)
+}
+
+// CreateWebhook stores a new active webhook
+// INSERT INTO webhooks (id, url, events, description, secret, created_at, updated_at) VALUES (...)
+func (r *Repository) CreateWebhook(ctx context.Context, input model.WebhookInput, secret string) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	hook := model.Webhook{
+		ID:          rand.Text(),
+		URL:         input.URL,
+		Events:      slices.Clone(input.Events),
+		Description: input.Description,
+		Secret:      secret,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[hook.ID] = hook
+
+	return cloneWebhook(hook), nil
+}
+
+// GetWebhook retrieves a webhook, including its secret, by ID
+func (r *Repository) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hook, ok := r.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneWebhook(hook), nil
+}
+
+// ListWebhooks returns every webhook, oldest first
+// SELECT ... FROM webhooks ORDER BY created_at
+func (r *Repository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := make([]model.Webhook, 0, len(r.webhooks))
+	for _, hook := range r.webhooks {
+		hooks = append(hooks, *cloneWebhook(hook))
+	}
+	slices.SortFunc(hooks, func(a, b model.Webhook) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return hooks, nil
+}
+
+// UpdateWebhook changes the fields set in patch
+// Re-enabling a webhook clears its failure count
+func (r *Repository) UpdateWebhook(ctx context.Context, id string, patch model.WebhookPatch) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hook, ok := r.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if patch.URL != nil {
+		hook.URL = *patch.URL
+	}
+	if patch.Events != nil {
+		hook.Events = slices.Clone(*patch.Events)
+	}
+	if patch.Description != nil {
+		hook.Description = *patch.Description
+	}
+	if patch.Active != nil {
+		if *patch.Active && !hook.Active {
+			hook.ConsecutiveFailures = 0
+			hook.DisabledAt = nil
+			hook.DisabledReason = ""
+		}
+		hook.Active = *patch.Active
+	}
+	hook.UpdatedAt = time.Now().UTC()
+	r.webhooks[id] = hook
+
+	return cloneWebhook(hook), nil
+}
+
+// DeleteWebhook removes a webhook and its deliveries
+func (r *Repository) DeleteWebhook(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.webhooks, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// RecordWebhookResult updates a webhook's failure count after an attempt and
+// disables it once disableAfter consecutive attempts have failed, reporting
+// whether this call disabled it; disableAfter 0 never disables
+// In production this is one statement:
+//
+//	UPDATE webhooks SET
+//	    consecutive_failures = CASE WHEN $2 THEN 0 ELSE consecutive_failures + 1 END,
+//	    active = active AND ($2 OR $3 = 0 OR consecutive_failures + 1 < $3), ...
+//	WHERE id = $1 RETURNING ...
+func (r *Repository) RecordWebhookResult(ctx context.Context, id string, succeeded bool, disableAfter int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hook, ok := r.webhooks[id]
+	if !ok {
+		return false, ErrNotFound
+	}
+
+	disabled := false
+	if succeeded {
+		hook.ConsecutiveFailures = 0
+	} else {
+		hook.ConsecutiveFailures++
+		if disableAfter > 0 && hook.ConsecutiveFailures >= disableAfter && hook.Active {
+			now := time.Now().UTC()
+			hook.Active = false
+			hook.DisabledAt = &now
+			hook.DisabledReason = fmt.Sprintf("%d consecutive failed deliveries", hook.ConsecutiveFailures)
+			disabled = true
+		}
+	}
+	r.webhooks[id] = hook
+
+	return disabled, nil
+}
+
+// CreateDelivery stores a pending delivery, reporting false with the existing
+// delivery when the event was already recorded for the webhook, so an event
+// delivered to the service twice is sent once; redeliveries are always stored
+// INSERT INTO webhook_deliveries (...) VALUES (...) ON CONFLICT DO NOTHING
+func (r *Repository) CreateDelivery(ctx context.Context, delivery model.WebhookDelivery) (*model.WebhookDelivery, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[delivery.WebhookID]; !ok {
+		return nil, false, ErrNotFound
+	}
+	if delivery.RedeliveryOf == "" {
+		for _, existing := range r.deliveries {
+			if existing.WebhookID == delivery.WebhookID && existing.EventID == delivery.EventID && existing.RedeliveryOf == "" {
+				return cloneDelivery(existing), false, nil
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	delivery.ID = rand.Text()
+	delivery.Status = model.DeliveryPending
+	delivery.Attempts = nil
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+	r.deliveries[delivery.ID] = &delivery
+
+	return cloneDelivery(&delivery), true, nil
+}
+
+// GetDelivery retrieves a delivery by ID
+func (r *Repository) GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneDelivery(delivery), nil
+}
+
+// ListDeliveries returns up to limit deliveries of a webhook, newest first
+// SELECT ... FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY created_at DESC LIMIT $2
+func (r *Repository) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []model.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, *cloneDelivery(delivery))
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b model.WebhookDelivery) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// RecordDeliveryAttempt appends an attempt to a delivery's log and sets its status
+// UPDATE webhook_deliveries SET attempts = attempts || $2, status = $3, updated_at = now() WHERE id = $1
+func (r *Repository) RecordDeliveryAttempt(ctx context.Context, id string, attempt model.WebhookAttempt, status string) (*model.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.Status = status
+	delivery.UpdatedAt = time.Now().UTC()
+
+	return cloneDelivery(delivery), nil
+}
+
+// cloneWebhook copies a webhook so callers cannot change the stored one
+func cloneWebhook(hook model.Webhook) *model.Webhook {
+	hook.Events = slices.Clone(hook.Events)
+	if hook.DisabledAt != nil {
+		at := *hook.DisabledAt
+		hook.DisabledAt = &at
+	}
+	return &hook
+}
+
+// cloneDelivery copies a delivery so callers cannot change the stored one
+func cloneDelivery(delivery *model.WebhookDelivery) *model.WebhookDelivery {
+	out := *delivery
+	out.Attempts = slices.Clone(delivery.Attempts)
+	return &out
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"strings"
+
+	"github.com/ahxar/go-backend-service/internal/config"
+	"github.com/ahxar/go-backend-service/internal/version"
+)
+
+// NewAdmin creates the admin HTTP server exposing profiling and runtime diagnostics
+// It listens on its own address and requires the configured admin token
+func NewAdmin(cfg *config.Config, logger *slog.Logger) (*http.Server, error) {
+	if cfg.AdminToken == "" {
+		return nil, errors.New("admin server requires ADMIN_TOKEN to be set")
+	}
+
+	mux := http.NewServeMux()
+
+	// Register pprof endpoints
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+
+	// Register runtime diagnostics
+	mux.HandleFunc("GET /debug/goroutines", goroutineDump(logger))
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.HandleFunc("GET /debug/build", adminJSON(logger, func() any { return version.Get() }))
+	mux.HandleFunc("GET /debug/config", adminJSON(logger, func() any { return cfg.Redacted() }))
+
+	server := &http.Server{
+		Addr:              cfg.AdminAddr,
+		Handler:           adminAuth(cfg.AdminToken)(mux),
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		// No write timeout: CPU profiles and execution traces stream for
+		// as long as the requested duration
+	}
+
+	return server, nil
+}
+
+// adminAuth requires the admin token as a bearer token or basic auth password
+func adminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				_, provided, _ = r.BasicAuth()
+			}
+
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// goroutineDump writes the stacks of all goroutines in plain text
+func goroutineDump(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := runtimepprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+			logger.ErrorContext(r.Context(), "failed to write goroutine dump",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// adminJSON serves the value returned by fn as indented JSON
+func adminJSON(logger *slog.Logger, fn func() any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(fn()); err != nil {
+			logger.ErrorContext(r.Context(), "failed to encode response",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahxar/go-backend-service/internal/config"
+)
+
+func setupTestAdmin(t *testing.T) http.Handler {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(nil, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	cfg := &config.Config{
+		AdminAddr:   "127.0.0.1:0",
+		AdminToken:  "secret",
+		OtelHeaders: map[string]string{"api-key": "collector-key"},
+	}
+
+	srv, err := NewAdmin(cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create admin server: %v", err)
+	}
+	return srv.Handler
+}
+
+func TestNewAdmin_RequiresToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(nil, nil))
+
+	if _, err := NewAdmin(&config.Config{}, logger); err == nil {
+		t.Error("expected error without admin token")
+	}
+}
+
+func TestAdmin_Auth(t *testing.T) {
+	h := setupTestAdmin(t)
+
+	tests := []struct {
+		name     string
+		setAuth  func(r *http.Request)
+		wantCode int
+	}{
+		{name: "no credentials", setAuth: func(*http.Request) {}, wantCode: http.StatusUnauthorized},
+		{name: "wrong bearer", setAuth: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, wantCode: http.StatusUnauthorized},
+		{name: "bearer token", setAuth: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, wantCode: http.StatusOK},
+		{name: "basic auth", setAuth: func(r *http.Request) { r.SetBasicAuth("admin", "secret") }, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/build", http.NoBody)
+			tt.setAuth(req)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAdmin_ConfigIsRedacted(t *testing.T) {
+	h := setupTestAdmin(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", http.NoBody)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response config.Config
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.AdminToken != "REDACTED" {
+		t.Errorf("expected admin token to be redacted, got %s", response.AdminToken)
+	}
+
+	if response.OtelHeaders["api-key"] != "REDACTED" {
+		t.Errorf("expected header to be redacted, got %s", response.OtelHeaders["api-key"])
+	}
+}
@@ -3,34 +3,105 @@ package server
 import (
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 
 	"github.com/ahxar/go-backend-service/internal/config"
 	"github.com/ahxar/go-backend-service/internal/handler"
 	"github.com/ahxar/go-backend-service/internal/middleware"
+	"github.com/ahxar/go-backend-service/pkg/httpcache"
+	"github.com/ahxar/go-backend-service/pkg/idempotency"
+	"github.com/ahxar/go-backend-service/pkg/otel"
+	"github.com/ahxar/go-backend-service/pkg/profiling"
+	"github.com/ahxar/go-backend-service/pkg/webhook"
 
 	_ "github.com/ahxar/go-backend-service/docs"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
+// Deps holds the optional components of the HTTP server; nil fields are disabled
+type Deps struct {
+	// Spans is the in-memory span buffer used in local telemetry mode
+	Spans *otel.SpanBuffer
+	// Profiler is the continuous profiler
+	Profiler *profiling.Profiler
+	// AccessLog replaces the structured request log with access log lines
+	AccessLog *middleware.AccessLogger
+	// WebSocket serves WebSocket sessions
+	WebSocket *handler.WebSocket
+	// ResponseCache is the shared response cache
+	ResponseCache *httpcache.ResponseCache
+	// Idempotency deduplicates retried unsafe requests
+	Idempotency *idempotency.Guard
+	// Inbound receives third-party webhooks
+	Inbound *webhook.Receiver
+}
+
 // New creates and configures the HTTP server
-func New(cfg *config.Config, logger *slog.Logger, h *handler.Handler) *http.Server {
+func New(cfg *config.Config, logger *slog.Logger, h *handler.Handler, deps Deps) *http.Server {
 	mux := http.NewServeMux()
 
 	// Register routes
 	mux.HandleFunc("GET /health", h.Health)
 	mux.HandleFunc("GET /ready", h.Ready)
 	mux.HandleFunc("GET /api/example", h.Example)
+	mux.HandleFunc("POST /api/examples", h.CreateExample)
+	mux.HandleFunc("GET /api/examples/{id}", h.GetExample)
+	mux.HandleFunc("PUT /api/examples/{id}", h.UpdateExample)
+	mux.HandleFunc("PATCH /api/examples/{id}", h.PatchExample)
+	mux.HandleFunc("DELETE /api/examples/{id}", h.DeleteExample)
+	mux.HandleFunc("POST /api/webhooks", h.CreateWebhook)
+	mux.HandleFunc("GET /api/webhooks", h.ListWebhooks)
+	mux.HandleFunc("GET /api/webhooks/{id}", h.GetWebhook)
+	mux.HandleFunc("PATCH /api/webhooks/{id}", h.UpdateWebhook)
+	mux.HandleFunc("DELETE /api/webhooks/{id}", h.DeleteWebhook)
+	mux.HandleFunc("GET /api/webhooks/{id}/deliveries", h.ListDeliveries)
+	mux.HandleFunc("GET /api/webhooks/{id}/deliveries/{delivery}", h.GetDelivery)
+	mux.HandleFunc("POST /api/webhooks/{id}/deliveries/{delivery}/redeliver", h.Redeliver)
+	mux.HandleFunc("GET /api/events", h.Events)
+	if deps.WebSocket != nil {
+		mux.Handle("GET /api/ws", deps.WebSocket)
+	}
+	if deps.Inbound != nil {
+		mux.Handle("POST /webhooks/{provider}", deps.Inbound)
+	}
 
 	// Register Swagger UI endpoint
 	mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
 
-	// Apply middleware chain: tracing (otel with trace ID) -> recovery -> logging
+	// Apply middleware chain: tracing (otel with trace ID) -> request ID -> route -> profiling labels -> recovery -> logging (or access log) -> cache control -> response cache -> idempotency
 	var httpHandler http.Handler = mux
-	httpHandler = middleware.Logging(logger)(httpHandler)
+	if deps.Idempotency != nil {
+		httpHandler = deps.Idempotency.Middleware(httpHandler)
+	}
+	if deps.ResponseCache != nil {
+		httpHandler = deps.ResponseCache.Middleware(httpHandler)
+	}
+	httpHandler = middleware.CacheControl(cfg.HTTPCacheControl, cfg.HTTPCacheControlDefault, mux)(httpHandler)
+	if deps.AccessLog != nil {
+		httpHandler = deps.AccessLog.Middleware(mux)(httpHandler)
+	} else {
+		httpHandler = middleware.Logging(logger)(httpHandler)
+	}
 	httpHandler = middleware.Recovery(logger)(httpHandler)
+	if deps.Profiler != nil {
+		httpHandler = middleware.Profiling(deps.Profiler, mux)(httpHandler)
+	}
+	httpHandler = middleware.Route(mux)(httpHandler)
+	httpHandler = middleware.RequestID()(httpHandler)
 	httpHandler = middleware.Tracing(cfg.OtelServiceName)(httpHandler)
 
+	// Serve the local trace viewer outside the middleware chain so viewing
+	// traces does not record new ones, and only to loopback clients since it
+	// shares the public listener
+	if deps.Spans != nil {
+		root := http.NewServeMux()
+		root.Handle("GET /debug/traces", loopbackOnly(deps.Spans))
+		root.Handle("/", httpHandler)
+		httpHandler = root
+	}
+
 	// Configure server with explicit timeouts
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Port),
@@ -42,3 +113,20 @@ func New(cfg *config.Config, logger *slog.Logger, h *handler.Handler) *http.Serv
 
 	return server
 }
+
+// loopbackOnly answers 404 to clients not connecting from the local host
+func loopbackOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !addr.Unmap().IsLoopback() {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/ahxar/go-backend-service/internal/repository"
+)
+
+// AnyVersion makes a write unconditional, e.g. for If-Match: *
+const AnyVersion = repository.AnyVersion
+
+// ErrNotFound is returned when the requested resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrVersionMismatch is returned when a write's expected version is not the current one
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// ErrInvalidInput is returned when a request fails validation
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrWebhookDisabled is returned when redelivering to a disabled webhook
+var ErrWebhookDisabled = errors.New("webhook disabled")
+
+// ErrWebhooksUnavailable is returned when webhooks cannot be delivered because
+// background jobs or the outbox relay are disabled
+var ErrWebhooksUnavailable = errors.New("webhooks unavailable")
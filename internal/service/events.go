@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ahxar/go-backend-service/internal/repository"
+	"github.com/ahxar/go-backend-service/pkg/outbox"
+)
+
+// Event topics
+const (
+	TopicExamples = "examples"
+)
+
+// Event types
+const (
+	EventExampleCreated = "example.created"
+	EventExampleUpdated = "example.updated"
+	EventExampleDeleted = "example.deleted"
+)
+
+// publish sends a change event to live subscribers
+// Publishing is best effort and never fails the operation that caused it
+func (s *Service) publish(ctx context.Context, topic, eventType string, payload any) {
+	if s.events == nil {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to encode event",
+			slog.String("type", eventType),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	s.events.Publish(topic, eventType, data)
+}
+
+// raise records a domain event in the outbox of tx, so it is relayed to the
+// broker if and only if the change commits
+// key is the ID of the entity the event is about
+// Nothing is recorded when the outbox is disabled
+func (s *Service) raise(ctx context.Context, tx *repository.Tx, topic, eventType, key string, payload any) error {
+	if !s.outbox {
+		return nil
+	}
+	msg, err := outbox.NewMessage(ctx, topic, eventType, key, payload)
+	if err != nil {
+		return err
+	}
+	if err := tx.AddOutbox(ctx, msg); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
@@ -2,19 +2,28 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/ahxar/go-backend-service/internal/model"
+	"github.com/ahxar/go-backend-service/internal/repository"
 )
 
 // ExampleService defines business logic for example operations
 type ExampleService interface {
 	ProcessExample(ctx context.Context, name string) (*model.ExampleResponse, error)
+	CreateExample(ctx context.Context, input model.ExampleInput) (*model.Example, error)
+	GetExample(ctx context.Context, id string) (*model.Example, error)
+	UpdateExample(ctx context.Context, id string, version int64, input model.ExampleInput) (*model.Example, error)
+	PatchExample(ctx context.Context, id string, version int64, patch model.ExamplePatch) (*model.Example, error)
+	DeleteExample(ctx context.Context, id string, version int64) error
 }
 
 // ProcessExample processes an example request with business logic
+// Results are served from the cache when one is configured
 func (s *Service) ProcessExample(ctx context.Context, name string) (*model.ExampleResponse, error) {
 	// Check if context is already canceled
 	select {
@@ -24,10 +33,20 @@ func (s *Service) ProcessExample(ctx context.Context, name string) (*model.Examp
 	}
 
 	// Log with context (includes trace ID from middleware)
-	s.logger.InfoContext(ctx, "processing example request",
-		slog.String("name", name),
-	)
+	// The name is caller-supplied and may be personal data, so it is not logged
+	s.logger.InfoContext(ctx, "processing example request")
+
+	if s.examples == nil {
+		return s.processExample(ctx, name)
+	}
 
+	return s.examples.Get(ctx, name, func(ctx context.Context) (*model.ExampleResponse, error) {
+		return s.processExample(ctx, name)
+	})
+}
+
+// processExample loads and processes an example
+func (s *Service) processExample(ctx context.Context, name string) (*model.ExampleResponse, error) {
 	// Call repository layer for data access
 	data, err := s.repo.GetData(ctx, name)
 	if err != nil {
@@ -57,9 +76,132 @@ func (s *Service) ProcessExample(ctx context.Context, name string) (*model.Examp
 
 	// Log successful processing
 	s.logger.InfoContext(ctx, "example request processed",
-		slog.String("name", name),
-		slog.Any("data", data),
+		slog.Any("status", data["status"]),
 	)
 
 	return response, nil
 }
+
+// CreateExample validates and stores a new example
+// Changes raise domain events on TopicExamples through the outbox in the same transaction
+func (s *Service) CreateExample(ctx context.Context, input model.ExampleInput) (*model.Example, error) {
+	if err := validateExample(input); err != nil {
+		return nil, err
+	}
+
+	var example *model.Example
+	err := s.repo.WithTx(ctx, func(tx *repository.Tx) error {
+		var err error
+		if example, err = tx.CreateExample(ctx, input); err != nil {
+			return err
+		}
+		return s.raise(ctx, tx, TopicExamples, EventExampleCreated, example.ID, example)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create example: %w", err)
+	}
+
+	s.publish(ctx, TopicExamples, EventExampleCreated, example)
+	s.enqueue(ctx, JobExampleCreated, ExampleCreatedJob{ID: example.ID})
+	s.logger.InfoContext(ctx, "example created",
+		slog.String("id", example.ID),
+	)
+	return example, nil
+}
+
+// GetExample returns an example by ID
+func (s *Service) GetExample(ctx context.Context, id string) (*model.Example, error) {
+	example, err := s.repo.GetExample(ctx, id)
+	if err != nil {
+		return nil, mapRepositoryError("failed to get example", err)
+	}
+	return example, nil
+}
+
+// UpdateExample replaces an example if it is still at version
+// version is AnyVersion to skip the check
+func (s *Service) UpdateExample(ctx context.Context, id string, version int64, input model.ExampleInput) (*model.Example, error) {
+	if err := validateExample(input); err != nil {
+		return nil, err
+	}
+
+	var example *model.Example
+	err := s.repo.WithTx(ctx, func(tx *repository.Tx) error {
+		var err error
+		if example, err = tx.UpdateExample(ctx, id, version, input); err != nil {
+			return err
+		}
+		return s.raise(ctx, tx, TopicExamples, EventExampleUpdated, example.ID, example)
+	})
+	if err != nil {
+		return nil, mapRepositoryError("failed to update example", err)
+	}
+
+	s.publish(ctx, TopicExamples, EventExampleUpdated, example)
+	s.logger.InfoContext(ctx, "example updated",
+		slog.String("id", example.ID),
+		slog.Int64("version", example.Version),
+	)
+	return example, nil
+}
+
+// PatchExample changes the fields set in patch if the example is still at version
+// The write is conditional on the version read, so a concurrent change between
+// the read and the write is reported as ErrVersionMismatch rather than lost
+func (s *Service) PatchExample(ctx context.Context, id string, version int64, patch model.ExamplePatch) (*model.Example, error) {
+	current, err := s.repo.GetExample(ctx, id)
+	if err != nil {
+		return nil, mapRepositoryError("failed to get example", err)
+	}
+	if version != AnyVersion && current.Version != version {
+		return nil, fmt.Errorf("failed to patch example: %w", ErrVersionMismatch)
+	}
+
+	input := model.ExampleInput{Name: current.Name, Description: current.Description}
+	if patch.Name != nil {
+		input.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		input.Description = *patch.Description
+	}
+	return s.UpdateExample(ctx, id, current.Version, input)
+}
+
+// DeleteExample removes an example if it is still at version
+func (s *Service) DeleteExample(ctx context.Context, id string, version int64) error {
+	err := s.repo.WithTx(ctx, func(tx *repository.Tx) error {
+		if err := tx.DeleteExample(ctx, id, version); err != nil {
+			return err
+		}
+		return s.raise(ctx, tx, TopicExamples, EventExampleDeleted, id, map[string]string{"id": id})
+	})
+	if err != nil {
+		return mapRepositoryError("failed to delete example", err)
+	}
+
+	s.publish(ctx, TopicExamples, EventExampleDeleted, map[string]string{"id": id})
+	s.logger.InfoContext(ctx, "example deleted",
+		slog.String("id", id),
+	)
+	return nil
+}
+
+// validateExample checks the fields of an example
+func validateExample(input model.ExampleInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("name is required: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// mapRepositoryError translates repository errors into service errors
+func mapRepositoryError(msg string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return fmt.Errorf("%s: %w", msg, ErrNotFound)
+	case errors.Is(err, repository.ErrVersionConflict):
+		return fmt.Errorf("%s: %w", msg, ErrVersionMismatch)
+	default:
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+}
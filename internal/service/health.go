@@ -2,8 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
 )
 
+// HealthConfig holds the external dependencies checked by CheckReady
+type HealthConfig struct {
+	// Client sends the checks; nil uses a client with default settings
+	Client *http.Client
+	// Dependencies maps names to URLs that must answer GET with a 2xx status
+	Dependencies map[string]string
+}
+
 // HealthService defines business logic for health checks
 type HealthService interface {
 	CheckHealth(ctx context.Context) error
@@ -18,13 +30,50 @@ func (s *Service) CheckHealth(ctx context.Context) error {
 	}
 
 	// Add additional health checks here
-	// - External service connectivity
 	// - Cache availability
 	// - etc.
 
 	return nil
 }
 
+// checkDependencies checks every external dependency concurrently and reports all failures
+func (s *Service) checkDependencies(ctx context.Context) error {
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for name, url := range s.health.Dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.checkDependency(ctx, url); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("dependency %s unhealthy: %w", name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// checkDependency requires url to answer GET with a 2xx status
+func (s *Service) checkDependency(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := s.health.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
 // CheckReady performs comprehensive readiness check
 func (s *Service) CheckReady(ctx context.Context) error {
 	// Check repository layer readiness
@@ -37,5 +86,7 @@ func (s *Service) CheckReady(ctx context.Context) error {
 	// - Required data seeded
 	// - etc.
 
-	return nil
+	// An unavailable dependency takes the instance out of rotation rather
+	// than getting it restarted, which would not help
+	return s.checkDependencies(ctx)
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+	"github.com/ahxar/go-backend-service/pkg/webhook"
+)
+
+// JobInboundWebhook processes one verified inbound webhook
+const JobInboundWebhook = "webhook.inbound"
+
+// InboundWebhookJob is the payload of JobInboundWebhook
+type InboundWebhookJob struct {
+	Provider string `json:"provider"`
+	ID       string `json:"id"`
+	// Body is the raw request body as received
+	Body []byte `json:"body"`
+}
+
+// inboundEvent holds the fields providers commonly use to name the event type
+type inboundEvent struct {
+	Type  string `json:"type"`
+	Event string `json:"event"`
+	// Action is GitHub's sub-type, e.g. "opened"
+	Action string `json:"action"`
+}
+
+// ReceiveWebhook queues a verified inbound webhook for processing
+// It is the Handle function of every provider registered on the receiver
+func (s *Service) ReceiveWebhook(ctx context.Context, d webhook.Delivery) error {
+	if s.jobs == nil {
+		return errors.New("background jobs are disabled")
+	}
+
+	job := InboundWebhookJob{Provider: d.Provider, ID: d.ID, Body: d.Body}
+	if _, err := jobs.Enqueue(ctx, s.jobs, JobInboundWebhook, job); err != nil {
+		return fmt.Errorf("failed to enqueue inbound webhook: %w", err)
+	}
+	return nil
+}
+
+// handleInboundWebhook decodes a verified payload and dispatches it
+// Provider-specific processing hooks in here, keyed by job.Provider
+func (s *Service) handleInboundWebhook(ctx context.Context, job InboundWebhookJob) error {
+	var event inboundEvent
+	if err := json.Unmarshal(job.Body, &event); err != nil {
+		// Retrying cannot fix a malformed payload
+		return jobs.Permanent(fmt.Errorf("failed to decode %s webhook %s: %w", job.Provider, job.ID, err))
+	}
+
+	eventType := event.Type
+	if eventType == "" {
+		eventType = event.Event
+	}
+	s.logger.InfoContext(ctx, "inbound webhook received",
+		slog.String("provider", job.Provider),
+		slog.String("delivery_id", job.ID),
+		slog.String("type", eventType),
+		slog.String("action", event.Action),
+	)
+	return nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ahxar/go-backend-service/internal/repository"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+	"github.com/ahxar/go-backend-service/pkg/webhook"
+)
+
+func TestReceiveWebhook_QueuesRawBody(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	queue := jobs.NewMemory()
+	svc := New(logger, repository.New(logger), Options{Jobs: queue})
+
+	body := []byte(`{"type":"order.paid", "id":7}`)
+	if err := svc.ReceiveWebhook(ctx, webhook.Delivery{Provider: "partner", ID: "evt-1", Body: body}); err != nil {
+		t.Fatalf("expected the delivery to be queued, got %v", err)
+	}
+
+	job, err := queue.Claim(ctx, time.Minute)
+	if err != nil || job.Type != JobInboundWebhook {
+		t.Fatalf("expected an inbound webhook job, got %+v %v", job, err)
+	}
+	var payload InboundWebhookJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if payload.Provider != "partner" || payload.ID != "evt-1" || string(payload.Body) != string(body) {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	if err := svc.handleInboundWebhook(ctx, payload); err != nil {
+		t.Errorf("expected the payload to be processed, got %v", err)
+	}
+	payload.Body = []byte("not json")
+	if err := svc.handleInboundWebhook(ctx, payload); !jobs.IsPermanent(err) {
+		t.Errorf("expected a malformed payload to fail permanently, got %v", err)
+	}
+}
+
+func TestReceiveWebhook_RequiresJobs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := New(logger, repository.New(logger), Options{})
+
+	if err := svc.ReceiveWebhook(context.Background(), webhook.Delivery{Provider: "partner", ID: "evt-1"}); err == nil {
+		t.Error("expected an error when background jobs are disabled")
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/ahxar/go-backend-service/internal/repository"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+)
+
+// Job types
+const (
+	JobExampleCreated = "example.created"
+)
+
+// ExampleCreatedJob is the payload of JobExampleCreated
+type ExampleCreatedJob struct {
+	ID string `json:"id"`
+}
+
+// RegisterJobs registers the service's background job handlers
+func (s *Service) RegisterJobs(w *jobs.Workers) {
+	jobs.Handle(w, JobExampleCreated, s.handleExampleCreated)
+	jobs.Handle(w, JobWebhookDelivery, s.handleWebhookDelivery)
+	jobs.Handle(w, JobInboundWebhook, s.handleInboundWebhook)
+}
+
+// enqueue schedules background work
+// Failures are logged rather than returned because the change that caused the
+// job has already been committed
+func (s *Service) enqueue(ctx context.Context, jobType string, payload any, opts ...jobs.Option) {
+	if s.jobs == nil {
+		return
+	}
+
+	if _, err := jobs.Enqueue(ctx, s.jobs, jobType, payload, opts...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to enqueue job",
+			slog.String("type", jobType),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// handleExampleCreated processes a new example in the background so its
+// result is cached before the first request for it
+func (s *Service) handleExampleCreated(ctx context.Context, job ExampleCreatedJob) error {
+	example, err := s.repo.GetExample(ctx, job.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Deleted before the job ran; nothing to do
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ProcessExample(ctx, example.Name)
+	return err
+}
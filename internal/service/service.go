@@ -3,19 +3,72 @@ package service
 import (
 	"log/slog"
 
+	"github.com/ahxar/go-backend-service/internal/model"
 	"github.com/ahxar/go-backend-service/internal/repository"
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/httpclient"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+	"github.com/ahxar/go-backend-service/pkg/sse"
+	"github.com/ahxar/go-backend-service/pkg/webhook"
 )
 
 // Service contains business logic and dependencies
 type Service struct {
 	logger *slog.Logger
 	repo   *repository.Repository
+	events *sse.Broker
+	// examples caches processed examples by name
+	examples *cache.Cache[*model.ExampleResponse]
+	jobs     jobs.Queue
+	// outbox records domain events for the relay; without it none are written
+	outbox bool
+	// subscribed is set by Subscribe at startup once webhooks receive domain events
+	subscribed bool
+	webhooks   WebhookConfig
+	health     HealthConfig
+}
+
+// Options holds the optional dependencies of a Service; zero values disable them
+type Options struct {
+	// Events receives change events for live subscribers
+	Events *sse.Broker
+	// CacheStore backs the result cache configured by Cache
+	CacheStore cache.Store
+	Cache      cache.Config
+	// Jobs receives background jobs; without it jobs are skipped and webhooks are unavailable
+	Jobs jobs.Queue
+	// Outbox records domain events for a running relay; leave it unset when no
+	// relay runs, since unrelayed rows are never purged
+	Outbox   bool
+	Webhooks WebhookConfig
+	Health   HealthConfig
 }
 
 // New creates a new Service instance
-func New(logger *slog.Logger, repo *repository.Repository) *Service {
-	return &Service{
-		logger: logger,
-		repo:   repo,
+func New(logger *slog.Logger, repo *repository.Repository, opts Options) *Service {
+	webhookCfg := opts.Webhooks
+	if webhookCfg.Sender == nil {
+		webhookCfg.Sender = webhook.NewSender(nil, webhook.SenderConfig{})
+	}
+	if webhookCfg.MaxAttempts <= 0 {
+		webhookCfg.MaxAttempts = 8
+	}
+	healthCfg := opts.Health
+	if healthCfg.Client == nil {
+		healthCfg.Client = httpclient.New(nil, httpclient.Config{Name: "health"})
+	}
+
+	s := &Service{
+		logger:   logger,
+		repo:     repo,
+		events:   opts.Events,
+		jobs:     opts.Jobs,
+		outbox:   opts.Outbox,
+		webhooks: webhookCfg,
+		health:   healthCfg,
+	}
+	if opts.CacheStore != nil {
+		s.examples = cache.New[*model.ExampleResponse]("examples", opts.CacheStore, opts.Cache)
 	}
+	return s
 }
@@ -2,11 +2,23 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ahxar/go-backend-service/internal/model"
 	"github.com/ahxar/go-backend-service/internal/repository"
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/httpclient"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+	"github.com/ahxar/go-backend-service/pkg/sse"
 )
 
 func setupTestService() *Service {
@@ -14,7 +26,7 @@ func setupTestService() *Service {
 		Level: slog.LevelError,
 	}))
 	repo := repository.New(logger)
-	return New(logger, repo)
+	return New(logger, repo, Options{})
 }
 
 func TestProcessExample(t *testing.T) {
@@ -68,6 +80,29 @@ func TestCheckHealth(t *testing.T) {
 	}
 }
 
+func TestCheckReady_Dependencies(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := httpclient.New(httpclient.NewReplayer(
+		httpclient.Interaction{Method: http.MethodGet, URL: "http://billing.internal/health", StatusCode: http.StatusOK},
+		httpclient.Interaction{Method: http.MethodGet, URL: "http://search.internal/health", StatusCode: http.StatusServiceUnavailable},
+	), httpclient.Config{})
+	svc := New(logger, repository.New(logger), Options{Health: HealthConfig{
+		Client: client,
+		Dependencies: map[string]string{
+			"billing": "http://billing.internal/health",
+			"search":  "http://search.internal/health",
+		},
+	}})
+
+	if err := svc.CheckHealth(context.Background()); err != nil {
+		t.Errorf("expected liveness not to depend on dependencies, got %v", err)
+	}
+	err := svc.CheckReady(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "search") || strings.Contains(err.Error(), "billing") {
+		t.Errorf("expected only the search dependency to fail, got %v", err)
+	}
+}
+
 func TestCheckReady(t *testing.T) {
 	svc := setupTestService()
 	ctx := context.Background()
@@ -76,3 +111,227 @@ func TestCheckReady(t *testing.T) {
 		t.Errorf("expected no error, got %v", err)
 	}
 }
+
+func TestExampleChanges_PublishEvents(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	events := sse.NewBroker(sse.Config{})
+	svc := New(logger, repository.New(logger), Options{Events: events})
+	ctx := context.Background()
+
+	sub, _ := events.Subscribe([]string{TopicExamples}, "")
+	defer sub.Close()
+
+	// Reads are not changes, whether or not they are cached
+	if _, err := svc.ProcessExample(ctx, "Test"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	created, err := svc.CreateExample(ctx, model.ExampleInput{Name: "first"})
+	if err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+	updated, err := svc.UpdateExample(ctx, created.ID, created.Version, model.ExampleInput{Name: "second"})
+	if err != nil {
+		t.Fatalf("failed to update example: %v", err)
+	}
+	if err := svc.DeleteExample(ctx, updated.ID, updated.Version); err != nil {
+		t.Fatalf("failed to delete example: %v", err)
+	}
+
+	for _, want := range []string{EventExampleCreated, EventExampleUpdated, EventExampleDeleted} {
+		select {
+		case event := <-sub.Events():
+			if event.Type != want {
+				t.Errorf("expected %s event, got %s", want, event.Type)
+			}
+			if !strings.Contains(string(event.Data), created.ID) {
+				t.Errorf("expected event data to contain the example ID, got %s", event.Data)
+			}
+		default:
+			t.Fatalf("expected a %s event to be published", want)
+		}
+	}
+}
+
+func TestProcessExample_Cached(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	svc := New(logger, repository.New(logger), Options{CacheStore: cache.NewMemory(10), Cache: cache.Config{TTL: time.Minute}})
+	ctx := context.Background()
+
+	first, err := svc.ProcessExample(ctx, "Test")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	start := time.Now()
+	second, err := svc.ProcessExample(ctx, "Test")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if time.Since(start) >= 100*time.Millisecond {
+		t.Error("expected the cached result to skip processing")
+	}
+	if !second.Timestamp.Equal(first.Timestamp) {
+		t.Errorf("expected the cached response, got timestamps %v and %v", first.Timestamp, second.Timestamp)
+	}
+}
+
+func TestUpdateExample_VersionConflict(t *testing.T) {
+	svc := setupTestService()
+	ctx := context.Background()
+
+	created, err := svc.CreateExample(ctx, model.ExampleInput{Name: "first"})
+	if err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+	if created.Version != 1 {
+		t.Fatalf("expected version 1, got %d", created.Version)
+	}
+
+	updated, err := svc.UpdateExample(ctx, created.ID, created.Version, model.ExampleInput{Name: "second"})
+	if err != nil {
+		t.Fatalf("expected update to succeed, got %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("expected version 2, got %d", updated.Version)
+	}
+
+	// A writer still holding version 1 must not overwrite the change
+	name := "stale"
+	if _, err := svc.PatchExample(ctx, created.ID, created.Version, model.ExamplePatch{Name: &name}); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch from patch, got %v", err)
+	}
+	if err := svc.DeleteExample(ctx, created.ID, created.Version); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch from delete, got %v", err)
+	}
+
+	current, err := svc.GetExample(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("failed to get example: %v", err)
+	}
+	if current.Name != "second" {
+		t.Errorf("expected name second, got %s", current.Name)
+	}
+}
+
+func TestUpdateExample_ConcurrentWritersOneWins(t *testing.T) {
+	svc := setupTestService()
+	ctx := context.Background()
+
+	created, err := svc.CreateExample(ctx, model.ExampleInput{Name: "initial"})
+	if err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			input := model.ExampleInput{Name: fmt.Sprintf("writer %d", i)}
+			if _, err := svc.UpdateExample(ctx, created.ID, created.Version, input); err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded.Load() != 1 {
+		t.Errorf("expected exactly 1 writer to succeed, got %d", succeeded.Load())
+	}
+}
+
+func TestCreateExample_Validation(t *testing.T) {
+	svc := setupTestService()
+
+	if _, err := svc.CreateExample(context.Background(), model.ExampleInput{Name: " "}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestCreateExample_EnqueuesJob(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(nil, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	queue := jobs.NewMemory()
+	svc := New(logger, repository.New(logger), Options{Jobs: queue})
+	ctx := context.Background()
+
+	created, err := svc.CreateExample(ctx, model.ExampleInput{Name: "queued"})
+	if err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+
+	job, err := queue.Claim(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("expected a queued job, got %v", err)
+	}
+	if job.Type != JobExampleCreated {
+		t.Errorf("expected job type %s, got %s", JobExampleCreated, job.Type)
+	}
+
+	if err := svc.handleExampleCreated(ctx, ExampleCreatedJob{ID: created.ID}); err != nil {
+		t.Errorf("expected the job to succeed, got %v", err)
+	}
+}
+
+func TestExampleChanges_RaiseDomainEvents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(nil, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	repo := repository.New(logger)
+	svc := New(logger, repo, Options{Outbox: true})
+	ctx := context.Background()
+
+	created, err := svc.CreateExample(ctx, model.ExampleInput{Name: "first"})
+	if err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+	updated, err := svc.UpdateExample(ctx, created.ID, created.Version, model.ExampleInput{Name: "second"})
+	if err != nil {
+		t.Fatalf("failed to update example: %v", err)
+	}
+	// A rejected write changes nothing and raises nothing
+	if _, err := svc.UpdateExample(ctx, created.ID, created.Version, model.ExampleInput{Name: "stale"}); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch, got %v", err)
+	}
+	if err := svc.DeleteExample(ctx, created.ID, updated.Version); err != nil {
+		t.Fatalf("failed to delete example: %v", err)
+	}
+
+	msgs, err := repo.Outbox().Pending(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to read outbox: %v", err)
+	}
+	var types []string
+	for _, msg := range msgs {
+		types = append(types, msg.Type)
+		if msg.Topic != TopicExamples || msg.Key != created.ID {
+			t.Errorf("expected an %s event keyed by %s, got %s keyed by %s", TopicExamples, created.ID, msg.Topic, msg.Key)
+		}
+	}
+	want := []string{EventExampleCreated, EventExampleUpdated, EventExampleDeleted}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("expected events %v, got %v", want, types)
+	}
+}
+
+func TestExampleChanges_OutboxDisabled(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(nil, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	repo := repository.New(logger)
+	svc := New(logger, repo, Options{})
+	ctx := context.Background()
+
+	if _, err := svc.CreateExample(ctx, model.ExampleInput{Name: "first"}); err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+
+	// Without a relay nothing would publish or purge the rows
+	if msgs, _ := repo.Outbox().Pending(ctx, 10); len(msgs) != 0 {
+		t.Errorf("expected no outbox rows, got %d", len(msgs))
+	}
+}
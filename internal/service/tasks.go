@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Scheduled task names
+const (
+	TaskExamplesReport = "examples.report"
+	TaskOutboxCleanup  = "outbox.cleanup"
+)
+
+// outboxRetention is how long published outbox messages are kept, e.g. for
+// inspecting what was relayed
+const outboxRetention = 24 * time.Hour
+
+// Tasks returns the service's periodic tasks by name; their schedules come from configuration
+func (s *Service) Tasks() map[string]func(ctx context.Context) error {
+	return map[string]func(ctx context.Context) error{
+		TaskExamplesReport: s.reportExamples,
+		TaskOutboxCleanup:  s.cleanupOutbox,
+	}
+}
+
+// reportExamples logs how many examples are stored
+func (s *Service) reportExamples(ctx context.Context) error {
+	count, err := s.repo.CountExamples(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count examples: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "examples report",
+		slog.Int("examples", count),
+	)
+	return nil
+}
+
+// cleanupOutbox deletes outbox messages published more than outboxRetention ago
+func (s *Service) cleanupOutbox(ctx context.Context) error {
+	purged, err := s.repo.Outbox().Purge(ctx, time.Now().Add(-outboxRetention))
+	if err != nil {
+		return fmt.Errorf("failed to purge outbox: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "outbox cleaned up",
+		slog.Int("purged", purged),
+	)
+	return nil
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/ahxar/go-backend-service/internal/model"
+	"github.com/ahxar/go-backend-service/internal/repository"
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+	"github.com/ahxar/go-backend-service/pkg/outbox"
+	"github.com/ahxar/go-backend-service/pkg/webhook"
+)
+
+// JobWebhookDelivery sends one webhook delivery
+const JobWebhookDelivery = "webhook.delivery"
+
+// webhookEvents are the event types webhooks can subscribe to; "*" subscribes to all
+var webhookEvents = []string{EventExampleCreated, EventExampleUpdated, EventExampleDeleted}
+
+// webhookDedupeTTL is how long event IDs are remembered to skip redelivered events
+const webhookDedupeTTL = 24 * time.Hour
+
+// WebhookConfig holds webhook delivery configuration
+type WebhookConfig struct {
+	// Sender signs and posts deliveries; nil uses a sender with default settings
+	Sender *webhook.Sender
+	// MaxAttempts is the number of attempts per delivery; retries back off as configured for jobs
+	MaxAttempts int
+	// DisableAfter is the number of consecutive failed attempts after which a webhook is disabled; 0 never disables
+	DisableAfter int
+}
+
+// WebhookDeliveryJob is the payload of JobWebhookDelivery
+type WebhookDeliveryJob struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// webhookEvent is the body of a webhook request
+type webhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Subscribe registers the service's consumers of domain events on bus
+// seen remembers handled event IDs so events relayed twice are processed once
+// Webhooks are only dispatched when background jobs are enabled; a dispatcher
+// unable to schedule deliveries would fail every event and stall the relay
+// Until it is called, creating webhooks fails since no events would reach them
+func (s *Service) Subscribe(bus *outbox.Memory, seen cache.Store) {
+	if s.jobs == nil {
+		return
+	}
+	bus.Subscribe(TopicExamples, outbox.Deduplicate(seen, webhookDedupeTTL, s.dispatchWebhooks))
+	s.subscribed = true
+}
+
+// CreateWebhook validates and stores a webhook with a new signing secret
+// The returned webhook is the only one carrying the secret
+func (s *Service) CreateWebhook(ctx context.Context, input model.WebhookInput) (*model.Webhook, error) {
+	if err := validateWebhook(input.URL, input.Events); err != nil {
+		return nil, err
+	}
+	if !s.subscribed {
+		return nil, fmt.Errorf("failed to create webhook: %w", ErrWebhooksUnavailable)
+	}
+
+	hook, err := s.repo.CreateWebhook(ctx, input, webhook.NewSecret())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "webhook created",
+		slog.String("id", hook.ID),
+	)
+	return hook, nil
+}
+
+// ListWebhooks returns every webhook
+func (s *Service) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	hooks, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+// GetWebhook returns a webhook by ID
+func (s *Service) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	hook, err := s.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, mapRepositoryError("failed to get webhook", err)
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+// UpdateWebhook changes the fields set in patch
+func (s *Service) UpdateWebhook(ctx context.Context, id string, patch model.WebhookPatch) (*model.Webhook, error) {
+	current, err := s.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, mapRepositoryError("failed to get webhook", err)
+	}
+	target, events := current.URL, current.Events
+	if patch.URL != nil {
+		target = *patch.URL
+	}
+	if patch.Events != nil {
+		events = *patch.Events
+	}
+	if err := validateWebhook(target, events); err != nil {
+		return nil, err
+	}
+
+	hook, err := s.repo.UpdateWebhook(ctx, id, patch)
+	if err != nil {
+		return nil, mapRepositoryError("failed to update webhook", err)
+	}
+
+	s.logger.InfoContext(ctx, "webhook updated",
+		slog.String("id", hook.ID),
+		slog.Bool("active", hook.Active),
+	)
+	hook.Secret = ""
+	return hook, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (s *Service) DeleteWebhook(ctx context.Context, id string) error {
+	if err := s.repo.DeleteWebhook(ctx, id); err != nil {
+		return mapRepositoryError("failed to delete webhook", err)
+	}
+
+	s.logger.InfoContext(ctx, "webhook deleted",
+		slog.String("id", id),
+	)
+	return nil
+}
+
+// ListDeliveries returns up to limit recent deliveries of a webhook, newest first
+func (s *Service) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	if _, err := s.repo.GetWebhook(ctx, webhookID); err != nil {
+		return nil, mapRepositoryError("failed to get webhook", err)
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDelivery returns a delivery of a webhook
+func (s *Service) GetDelivery(ctx context.Context, webhookID, id string) (*model.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, mapRepositoryError("failed to get delivery", err)
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, fmt.Errorf("failed to get delivery: %w", ErrNotFound)
+	}
+	return delivery, nil
+}
+
+// Redeliver sends the event of a delivery again as a new delivery
+func (s *Service) Redeliver(ctx context.Context, webhookID, id string) (*model.WebhookDelivery, error) {
+	if s.jobs == nil {
+		return nil, fmt.Errorf("failed to redeliver: %w", ErrWebhooksUnavailable)
+	}
+	original, err := s.GetDelivery(ctx, webhookID, id)
+	if err != nil {
+		return nil, err
+	}
+	hook, err := s.repo.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, mapRepositoryError("failed to get webhook", err)
+	}
+	if !hook.Active {
+		return nil, fmt.Errorf("failed to redeliver: %w", ErrWebhookDisabled)
+	}
+
+	delivery, _, err := s.repo.CreateDelivery(ctx, model.WebhookDelivery{
+		WebhookID:    webhookID,
+		EventID:      original.EventID,
+		EventType:    original.EventType,
+		Payload:      original.Payload,
+		RedeliveryOf: original.ID,
+	})
+	if err != nil {
+		return nil, mapRepositoryError("failed to create delivery", err)
+	}
+	if err := s.enqueueDelivery(ctx, delivery.ID); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "webhook redelivery scheduled",
+		slog.String("webhook_id", webhookID),
+		slog.String("delivery_id", delivery.ID),
+		slog.String("redelivery_of", original.ID),
+	)
+	return delivery, nil
+}
+
+// dispatchWebhooks records a delivery of a domain event for each subscribed
+// webhook and schedules it; an error makes the outbox relay the event again
+func (s *Service) dispatchWebhooks(ctx context.Context, msg outbox.Message) error {
+	hooks, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	payload, err := json.Marshal(webhookEvent{
+		ID:        msg.ID,
+		Type:      msg.Type,
+		CreatedAt: msg.CreatedAt,
+		Data:      msg.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	var errs []error
+	for _, hook := range hooks {
+		if !hook.Active || !(slices.Contains(hook.Events, msg.Type) || slices.Contains(hook.Events, "*")) {
+			continue
+		}
+
+		delivery, _, err := s.repo.CreateDelivery(ctx, model.WebhookDelivery{
+			WebhookID: hook.ID,
+			EventID:   msg.ID,
+			EventType: msg.Type,
+			Payload:   payload,
+		})
+		if errors.Is(err, repository.ErrNotFound) {
+			// Deleted meanwhile
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create delivery: %w", err))
+			continue
+		}
+		// A delivery recorded on an earlier relay of the event may not have been scheduled
+		if delivery.Status == model.DeliveryPending {
+			if err := s.enqueueDelivery(ctx, delivery.ID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enqueueDelivery schedules a delivery job
+func (s *Service) enqueueDelivery(ctx context.Context, deliveryID string) error {
+	if s.jobs == nil {
+		return fmt.Errorf("failed to schedule webhook delivery: %w", ErrWebhooksUnavailable)
+	}
+	_, err := jobs.Enqueue(ctx, s.jobs, JobWebhookDelivery, WebhookDeliveryJob{DeliveryID: deliveryID},
+		jobs.MaxAttempts(s.webhooks.MaxAttempts),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// handleWebhookDelivery makes one attempt of a delivery
+// Failed attempts are retried by the job queue until MaxAttempts, and a webhook
+// failing DisableAfter times in a row is disabled, failing its pending deliveries
+func (s *Service) handleWebhookDelivery(ctx context.Context, job WebhookDeliveryJob) error {
+	delivery, err := s.repo.GetDelivery(ctx, job.DeliveryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// The webhook was deleted with its deliveries
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != model.DeliveryPending {
+		// Already finished, e.g. by a duplicate job
+		return nil
+	}
+
+	hook, err := s.repo.GetWebhook(ctx, delivery.WebhookID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !hook.Active {
+		_, err := s.repo.RecordDeliveryAttempt(ctx, delivery.ID, model.WebhookAttempt{
+			At:    time.Now().UTC(),
+			Error: "webhook disabled",
+		}, model.DeliveryFailed)
+		return err
+	}
+
+	at := time.Now().UTC()
+	result, sendErr := s.webhooks.Sender.Send(ctx, hook.URL, []byte(hook.Secret), delivery.EventID, delivery.Payload)
+	attempt := model.WebhookAttempt{
+		At:         at,
+		StatusCode: result.StatusCode,
+		DurationMS: result.Duration.Milliseconds(),
+	}
+
+	status := model.DeliverySucceeded
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+		status = model.DeliveryPending
+		// An endpoint resolving to an internal address is not retried
+		if len(delivery.Attempts)+1 >= s.webhooks.MaxAttempts || errors.Is(sendErr, webhook.ErrAddressNotAllowed) {
+			status = model.DeliveryFailed
+		}
+	}
+
+	disabled, err := s.repo.RecordWebhookResult(ctx, hook.ID, sendErr == nil, s.webhooks.DisableAfter)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if disabled {
+		status = model.DeliveryFailed
+		s.logger.WarnContext(ctx, "webhook disabled after consecutive failures",
+			slog.String("webhook_id", hook.ID),
+			slog.Int("disable_after", s.webhooks.DisableAfter),
+		)
+	}
+
+	if _, err := s.repo.RecordDeliveryAttempt(ctx, delivery.ID, attempt, status); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	switch {
+	case sendErr == nil:
+		return nil
+	case status == model.DeliveryFailed:
+		return jobs.Permanent(sendErr)
+	default:
+		return sendErr
+	}
+}
+
+// validateWebhook checks the endpoint URL and subscribed events of a webhook
+func validateWebhook(target string, events []string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL: %w", ErrInvalidInput)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("events are required: %w", ErrInvalidInput)
+	}
+	for _, event := range events {
+		if event != "*" && !slices.Contains(webhookEvents, event) {
+			return fmt.Errorf("unknown event %q: %w", event, ErrInvalidInput)
+		}
+	}
+	return nil
+}
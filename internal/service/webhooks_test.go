@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahxar/go-backend-service/internal/model"
+	"github.com/ahxar/go-backend-service/internal/repository"
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/jobs"
+	"github.com/ahxar/go-backend-service/pkg/outbox"
+	"github.com/ahxar/go-backend-service/pkg/webhook"
+)
+
+// webhookFixture is a service whose domain events are dispatched to webhooks
+// with the relay and workers driven by hand
+type webhookFixture struct {
+	svc   *Service
+	repo  *repository.Repository
+	queue *jobs.Memory
+	relay *outbox.Relay
+}
+
+func newWebhookFixture(cfg WebhookConfig) *webhookFixture {
+	// The test endpoints listen on loopback, which webhooks may not reach by default
+	if cfg.Sender == nil {
+		loopback := webhook.AddressPolicy{Allow: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}}
+		cfg.Sender = webhook.NewSender(webhook.NewTransport(loopback), webhook.SenderConfig{Timeout: time.Second})
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.New(logger)
+	queue := jobs.NewMemory()
+	svc := New(logger, repo, Options{Jobs: queue, Outbox: true, Webhooks: cfg})
+
+	bus := outbox.NewMemory()
+	svc.Subscribe(bus, cache.NewMemory(100))
+	return &webhookFixture{
+		svc:   svc,
+		repo:  repo,
+		queue: queue,
+		relay: outbox.NewRelay(repo.Outbox(), bus, logger, outbox.RelayConfig{}),
+	}
+}
+
+// deliver relays pending events and runs one delivery job, as the worker would
+func (f *webhookFixture) deliver(t *testing.T) error {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := f.relay.RelayOnce(ctx); err != nil {
+		t.Fatalf("failed to relay events: %v", err)
+	}
+
+	return f.runNext(t)
+}
+
+// runNext runs the next delivery job, completing other jobs such as example processing
+func (f *webhookFixture) runNext(t *testing.T) error {
+	t.Helper()
+	ctx := context.Background()
+	for {
+		job, err := f.queue.Claim(ctx, time.Minute)
+		if err != nil {
+			t.Fatalf("expected a delivery job, got %v", err)
+		}
+		_ = f.queue.Complete(ctx, job.ID, job.Lease)
+		if job.Type == JobWebhookDelivery {
+			return f.run(job)
+		}
+	}
+}
+
+// run calls the delivery handler with a job's payload
+func (f *webhookFixture) run(job *jobs.Job) error {
+	var payload WebhookDeliveryJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+	return f.svc.handleWebhookDelivery(context.Background(), payload)
+}
+
+func TestWebhooks_DeliversSignedEvents(t *testing.T) {
+	var verifyErr atomic.Value
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		secret := r.URL.Query().Get("secret")
+		if err := webhook.Verify([]byte(secret), r.Header, body, time.Minute, time.Now()); err != nil {
+			verifyErr.Store(err)
+		}
+	}))
+	defer endpoint.Close()
+
+	f := newWebhookFixture(WebhookConfig{MaxAttempts: 3})
+	ctx := context.Background()
+
+	// The endpoint learns its secret from the URL so it can verify
+	hook, err := f.svc.CreateWebhook(ctx, model.WebhookInput{URL: endpoint.URL, Events: []string{EventExampleCreated}})
+	if err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	if hook.Secret == "" {
+		t.Fatal("expected the secret to be returned on create")
+	}
+	target := endpoint.URL + "?secret=" + hook.Secret
+	if _, err := f.svc.UpdateWebhook(ctx, hook.ID, model.WebhookPatch{URL: &target}); err != nil {
+		t.Fatalf("failed to update webhook: %v", err)
+	}
+	if got, _ := f.svc.GetWebhook(ctx, hook.ID); got.Secret != "" {
+		t.Error("expected the secret to be hidden after create")
+	}
+
+	example, err := f.svc.CreateExample(ctx, model.ExampleInput{Name: "hooked"})
+	if err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+	if err := f.deliver(t); err != nil {
+		t.Fatalf("expected delivery to succeed, got %v", err)
+	}
+	if err, _ := verifyErr.Load().(error); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+
+	deliveries, err := f.svc.ListDeliveries(ctx, hook.ID, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d: %v", len(deliveries), err)
+	}
+	delivery := deliveries[0]
+	if delivery.Status != model.DeliverySucceeded || delivery.EventType != EventExampleCreated {
+		t.Errorf("expected a succeeded %s delivery, got %s %s", EventExampleCreated, delivery.Status, delivery.EventType)
+	}
+	if len(delivery.Attempts) != 1 || delivery.Attempts[0].StatusCode != http.StatusOK {
+		t.Errorf("expected 1 successful attempt, got %+v", delivery.Attempts)
+	}
+
+	// Updates are not subscribed
+	if _, err := f.svc.UpdateExample(ctx, example.ID, AnyVersion, model.ExampleInput{Name: "renamed"}); err != nil {
+		t.Fatalf("failed to update example: %v", err)
+	}
+	if _, err := f.relay.RelayOnce(ctx); err != nil {
+		t.Fatalf("failed to relay events: %v", err)
+	}
+	if f.queue.Len() != 0 {
+		t.Errorf("expected no jobs for unsubscribed events, got %d", f.queue.Len())
+	}
+}
+
+func TestWebhooks_InternalAddressesFailWithoutRetry(t *testing.T) {
+	f := newWebhookFixture(WebhookConfig{
+		Sender:      webhook.NewSender(nil, webhook.SenderConfig{Timeout: time.Second}),
+		MaxAttempts: 5,
+	})
+	ctx := context.Background()
+
+	hook, err := f.svc.CreateWebhook(ctx, model.WebhookInput{URL: "http://169.254.169.254/latest/meta-data", Events: []string{"*"}})
+	if err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	if _, err := f.svc.CreateExample(ctx, model.ExampleInput{Name: "metadata"}); err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+
+	if err := f.deliver(t); !jobs.IsPermanent(err) || !errors.Is(err, webhook.ErrAddressNotAllowed) {
+		t.Fatalf("expected a permanent ErrAddressNotAllowed, got %v", err)
+	}
+	deliveries, _ := f.svc.ListDeliveries(ctx, hook.ID, 10)
+	if len(deliveries) != 1 || deliveries[0].Status != model.DeliveryFailed {
+		t.Errorf("expected one failed delivery, got %+v", deliveries)
+	}
+}
+
+func TestWebhooks_RetriesDisablesAndRedelivers(t *testing.T) {
+	var healthy atomic.Bool
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		}
+	}))
+	defer endpoint.Close()
+
+	f := newWebhookFixture(WebhookConfig{MaxAttempts: 5, DisableAfter: 2})
+	ctx := context.Background()
+
+	hook, err := f.svc.CreateWebhook(ctx, model.WebhookInput{URL: endpoint.URL, Events: []string{"*"}})
+	if err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	if _, err := f.svc.CreateExample(ctx, model.ExampleInput{Name: "hooked"}); err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+
+	// The first failure is retried
+	err = f.deliver(t)
+	if err == nil || jobs.IsPermanent(err) {
+		t.Fatalf("expected a retryable failure, got %v", err)
+	}
+	// Run the retry as the worker would after backing off
+	deliveries, _ := f.svc.ListDeliveries(ctx, hook.ID, 10)
+	if err := f.svc.handleWebhookDelivery(ctx, WebhookDeliveryJob{DeliveryID: deliveries[0].ID}); !jobs.IsPermanent(err) {
+		t.Fatalf("expected the second failure to disable the webhook permanently, got %v", err)
+	}
+
+	got, _ := f.svc.GetWebhook(ctx, hook.ID)
+	if got.Active || got.DisabledAt == nil || got.ConsecutiveFailures != 2 {
+		t.Errorf("expected the webhook to be disabled after 2 failures, got %+v", got)
+	}
+	delivery, _ := f.svc.GetDelivery(ctx, hook.ID, deliveries[0].ID)
+	if delivery.Status != model.DeliveryFailed || len(delivery.Attempts) != 2 {
+		t.Errorf("expected a failed delivery with 2 attempts, got %s with %d", delivery.Status, len(delivery.Attempts))
+	}
+
+	if _, err := f.svc.Redeliver(ctx, hook.ID, delivery.ID); !errors.Is(err, ErrWebhookDisabled) {
+		t.Fatalf("expected ErrWebhookDisabled, got %v", err)
+	}
+
+	// Re-enable and redeliver once the endpoint recovers
+	healthy.Store(true)
+	active := true
+	if got, _ := f.svc.UpdateWebhook(ctx, hook.ID, model.WebhookPatch{Active: &active}); !got.Active || got.ConsecutiveFailures != 0 {
+		t.Fatalf("expected the webhook to be re-enabled, got %+v", got)
+	}
+	redelivery, err := f.svc.Redeliver(ctx, hook.ID, delivery.ID)
+	if err != nil {
+		t.Fatalf("failed to redeliver: %v", err)
+	}
+	if redelivery.RedeliveryOf != delivery.ID || redelivery.EventID != delivery.EventID {
+		t.Errorf("expected a redelivery of %s with event %s, got %+v", delivery.ID, delivery.EventID, redelivery)
+	}
+
+	if err := f.runNext(t); err != nil {
+		t.Fatalf("expected the redelivery to succeed, got %v", err)
+	}
+	if got, _ := f.svc.GetDelivery(ctx, hook.ID, redelivery.ID); got.Status != model.DeliverySucceeded {
+		t.Errorf("expected the redelivery to succeed, got %s", got.Status)
+	}
+}
+
+func TestWebhooks_Validation(t *testing.T) {
+	svc := setupTestService()
+	ctx := context.Background()
+
+	for _, input := range []model.WebhookInput{
+		{URL: "ftp://example.com", Events: []string{"*"}},
+		{URL: "/relative", Events: []string{"*"}},
+		{URL: "https://example.com"},
+		{URL: "https://example.com", Events: []string{"example.renamed"}},
+	} {
+		if _, err := svc.CreateWebhook(ctx, input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%+v: expected ErrInvalidInput, got %v", input, err)
+		}
+	}
+}
+
+func TestWebhooks_RequireJobs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.New(logger)
+	svc := New(logger, repo, Options{Outbox: true})
+	ctx := context.Background()
+
+	input := model.WebhookInput{URL: "https://example.com/hook", Events: []string{"*"}}
+	if _, err := svc.CreateWebhook(ctx, input); !errors.Is(err, ErrWebhooksUnavailable) {
+		t.Errorf("expected ErrWebhooksUnavailable, got %v", err)
+	}
+
+	// Webhooks stored while jobs were enabled must not stall the relay
+	if _, err := repo.CreateWebhook(ctx, input, webhook.NewSecret()); err != nil {
+		t.Fatalf("failed to store webhook: %v", err)
+	}
+	bus := outbox.NewMemory()
+	svc.Subscribe(bus, cache.NewMemory(10))
+	relay := outbox.NewRelay(repo.Outbox(), bus, logger, outbox.RelayConfig{})
+	if _, err := svc.CreateExample(ctx, model.ExampleInput{Name: "unhooked"}); err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+	if _, err := relay.RelayOnce(ctx); err != nil {
+		t.Errorf("expected the relay to publish, got %v", err)
+	}
+	if pending, _ := repo.Outbox().Pending(ctx, 10); len(pending) != 0 {
+		t.Errorf("expected no pending messages, got %d", len(pending))
+	}
+}
+
+func TestWebhooks_RequireEventSource(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := New(logger, repository.New(logger), Options{Jobs: jobs.NewMemory()})
+	ctx := context.Background()
+
+	// Jobs alone are not enough when no relay feeds the bus
+	input := model.WebhookInput{URL: "https://example.com/hook", Events: []string{"*"}}
+	if _, err := svc.CreateWebhook(ctx, input); !errors.Is(err, ErrWebhooksUnavailable) {
+		t.Errorf("expected ErrWebhooksUnavailable, got %v", err)
+	}
+
+	svc.Subscribe(outbox.NewMemory(), cache.NewMemory(10))
+	if _, err := svc.CreateWebhook(ctx, input); err != nil {
+		t.Errorf("expected webhook to be created once subscribed, got %v", err)
+	}
+}
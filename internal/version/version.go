@@ -0,0 +1,43 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information injected at link time:
+//
+//	-ldflags "-X github.com/ahxar/go-backend-service/internal/version.Version=v1.2.3"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information, falling back to the VCS revision
+// embedded by the Go toolchain when no commit was injected
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+	}
+
+	if info.Commit == "" {
+		if build, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range build.Settings {
+				if setting.Key == "vcs.revision" {
+					info.Commit = setting.Value
+				}
+			}
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+// instrumentationName identifies the instruments created by this package
+const instrumentationName = "github.com/ahxar/go-backend-service/pkg/cache"
+
+// ErrNotFound is returned by loaders for missing values; it is cached for Config.NegativeTTL
+var ErrNotFound = errors.New("not found")
+
+// Lookup results recorded in the cache.requests metric
+const (
+	ResultHit      = "hit"
+	ResultStale    = "stale"
+	ResultNegative = "negative"
+	ResultMiss     = "miss"
+)
+
+// Config holds cache configuration
+type Config struct {
+	// TTL is how long a loaded value is served without reloading
+	TTL time.Duration
+	// StaleTTL is how long after TTL a value is still served while it is reloaded in the background; 0 disables
+	StaleTTL time.Duration
+	// NegativeTTL is how long ErrNotFound is cached; 0 disables negative caching
+	NegativeTTL time.Duration
+	// LoadTimeout bounds each load, which is shared by all coalesced callers
+	LoadTimeout time.Duration
+}
+
+// entry is the encoded form of a cached value
+type entry struct {
+	Value      json.RawMessage `json:"v,omitempty"`
+	NotFound   bool            `json:"n,omitempty"`
+	FreshUntil time.Time       `json:"f"`
+}
+
+// Cache is a read-through cache of values of type T
+// Concurrent misses for a key are coalesced into a single load
+type Cache[T any] struct {
+	name     string
+	store    Store
+	cfg      Config
+	group    singleflight.Group
+	now      func() time.Time
+	requests metric.Int64Counter
+	loads    metric.Float64Histogram
+}
+
+// New creates a cache named name, which labels its metrics, over store
+func New[T any](name string, store Store, cfg Config) *Cache[T] {
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+	if cfg.LoadTimeout <= 0 {
+		cfg.LoadTimeout = 30 * time.Second
+	}
+
+	meter := otel.Meter(instrumentationName)
+	requests, err := meter.Int64Counter("cache.requests",
+		metric.WithDescription("Cache lookups by result"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	loads, err := meter.Float64Histogram("cache.load.duration",
+		metric.WithDescription("Duration of loads on cache misses and refreshes"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &Cache[T]{
+		name:     name,
+		store:    store,
+		cfg:      cfg,
+		now:      time.Now,
+		requests: requests,
+		loads:    loads,
+	}
+}
+
+// Get returns the value for key, calling load on a miss
+// Stale values are returned immediately while one background load refreshes them
+// Store failures are treated as misses so the cache never makes a lookup fail
+func (c *Cache[T]) Get(ctx context.Context, key string, load func(context.Context) (T, error)) (T, error) {
+	var zero T
+
+	if e, ok := c.lookup(ctx, key); ok {
+		fresh := c.now().Before(e.FreshUntil)
+		switch {
+		case e.NotFound:
+			c.record(ctx, ResultNegative)
+			return zero, ErrNotFound
+		case !fresh:
+			c.record(ctx, ResultStale)
+			c.group.DoChan(key, c.loadFunc(ctx, key, load))
+		default:
+			c.record(ctx, ResultHit)
+		}
+
+		var value T
+		if err := json.Unmarshal(e.Value, &value); err == nil {
+			return value, nil
+		}
+		// An undecodable entry is reloaded below
+	}
+
+	c.record(ctx, ResultMiss)
+	select {
+	case res := <-c.group.DoChan(key, c.loadFunc(ctx, key, load)):
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Delete invalidates key, e.g. after the underlying data changes
+func (c *Cache[T]) Delete(ctx context.Context, key string) error {
+	return c.store.Delete(ctx, key)
+}
+
+// lookup reads and decodes the entry for key
+func (c *Cache[T]) lookup(ctx context.Context, key string) (entry, bool) {
+	data, ok, err := c.store.Get(ctx, key)
+	if err != nil || !ok {
+		return entry{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// loadFunc returns the shared load for key
+// The load runs detached from the caller's cancellation so one caller giving
+// up does not fail the others waiting on it
+func (c *Cache[T]) loadFunc(ctx context.Context, key string, load func(context.Context) (T, error)) func() (any, error) {
+	return func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.cfg.LoadTimeout)
+		defer cancel()
+
+		start := c.now()
+		value, err := load(ctx)
+		c.loads.Record(ctx, c.now().Sub(start).Seconds(), metric.WithAttributes(attribute.String("cache.name", c.name)))
+
+		switch {
+		case errors.Is(err, ErrNotFound):
+			if c.cfg.NegativeTTL > 0 {
+				_ = c.store.Set(ctx, key, c.encode(entry{NotFound: true, FreshUntil: c.now().Add(c.cfg.NegativeTTL)}), c.cfg.NegativeTTL)
+			}
+			return value, err
+		case err != nil:
+			return value, err
+		}
+
+		data, err := json.Marshal(value)
+		if err == nil {
+			e := entry{Value: data, FreshUntil: c.now().Add(c.cfg.TTL)}
+			// Caching is best effort; the loaded value is returned either way
+			_ = c.store.Set(ctx, key, c.encode(e), c.cfg.TTL+c.cfg.StaleTTL)
+		}
+		return value, nil
+	}
+}
+
+func (c *Cache[T]) encode(e entry) []byte {
+	data, _ := json.Marshal(e)
+	return data
+}
+
+func (c *Cache[T]) record(ctx context.Context, result string) {
+	c.requests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache.name", c.name),
+		attribute.String("cache.result", result),
+	))
+}
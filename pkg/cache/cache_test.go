@@ -0,0 +1,270 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// fakeClock is a controllable time source shared by a cache and its store
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(cfg Config) (*Cache[string], *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemory(100)
+	store.now = clock.Now
+	c := New[string]("test", store, cfg)
+	c.now = clock.Now
+	return c, clock
+}
+
+func TestMemory_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+
+	_ = m.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = m.Set(ctx, "b", []byte("2"), time.Minute)
+	_, _, _ = m.Get(ctx, "a")
+	_ = m.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := m.Get(ctx, "b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := m.Get(ctx, key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+}
+
+func TestMemory_Expiry(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	m := NewMemory(10)
+	m.now = clock.Now
+
+	_ = m.Set(ctx, "a", []byte("1"), time.Second)
+	clock.Advance(time.Second)
+
+	if _, ok, _ := m.Get(ctx, "a"); ok {
+		t.Error("expected expired entry to be missing")
+	}
+	if m.Len() != 0 {
+		t.Errorf("expected expired entry to be removed, got %d entries", m.Len())
+	}
+}
+
+func TestCache_CoalescesConcurrentMisses(t *testing.T) {
+	c, _ := newTestCache(Config{TTL: time.Minute})
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.Get(context.Background(), "key", load)
+		}()
+	}
+
+	// Let every caller join the in-flight load before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads.Load() != 1 {
+		t.Errorf("expected 1 load, got %d", loads.Load())
+	}
+	for i, result := range results {
+		if result != "value" {
+			t.Errorf("caller %d: expected value, got %q", i, result)
+		}
+	}
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	c, clock := newTestCache(Config{TTL: time.Minute, StaleTTL: time.Minute})
+	ctx := context.Background()
+
+	var version atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	load := func(context.Context) (string, error) {
+		v := version.Add(1)
+		if v > 1 {
+			refreshed <- struct{}{}
+		}
+		return fmt.Sprintf("v%d", v), nil
+	}
+
+	if got, _ := c.Get(ctx, "key", load); got != "v1" {
+		t.Fatalf("expected v1, got %s", got)
+	}
+
+	clock.Advance(90 * time.Second)
+
+	// The stale value is served while the refresh runs in the background
+	if got, _ := c.Get(ctx, "key", load); got != "v1" {
+		t.Errorf("expected stale v1, got %s", got)
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("expected a background refresh")
+	}
+
+	// Wait for the refreshed value to be stored
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, _ := c.Get(ctx, "key", load)
+		if got == "v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected refreshed v2, got %s", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Past the stale window the value is reloaded in the foreground
+	clock.Advance(3 * time.Minute)
+	if got, _ := c.Get(ctx, "key", load); got != "v3" {
+		t.Errorf("expected v3 after expiry, got %s", got)
+	}
+}
+
+func TestCache_NegativeCaching(t *testing.T) {
+	c, clock := newTestCache(Config{TTL: time.Minute, NegativeTTL: 10 * time.Second})
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	load := func(context.Context) (string, error) {
+		loads.Add(1)
+		return "", fmt.Errorf("lookup: %w", ErrNotFound)
+	}
+
+	for range 3 {
+		if _, err := c.Get(ctx, "missing", load); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if loads.Load() != 1 {
+		t.Errorf("expected the miss to be cached, got %d loads", loads.Load())
+	}
+
+	clock.Advance(10 * time.Second)
+	_, _ = c.Get(ctx, "missing", load)
+	if loads.Load() != 2 {
+		t.Errorf("expected a reload after the negative TTL, got %d loads", loads.Load())
+	}
+}
+
+func TestCache_ErrorsAreNotCached(t *testing.T) {
+	c, _ := newTestCache(Config{TTL: time.Minute, NegativeTTL: time.Minute})
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	load := func(context.Context) (string, error) {
+		loads.Add(1)
+		return "", errors.New("database unavailable")
+	}
+
+	_, _ = c.Get(ctx, "key", load)
+	_, _ = c.Get(ctx, "key", load)
+	if loads.Load() != 2 {
+		t.Errorf("expected errors to be retried, got %d loads", loads.Load())
+	}
+}
+
+func TestCache_Metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(provider)
+	defer otel.SetMeterProvider(previous)
+
+	c, _ := newTestCache(Config{TTL: time.Minute})
+	ctx := context.Background()
+	load := func(context.Context) (string, error) { return "value", nil }
+
+	_, _ = c.Get(ctx, "key", load)
+	_, _ = c.Get(ctx, "key", load)
+	_, _ = c.Get(ctx, "key", load)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	counts := make(map[string]int64)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "cache.requests" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				result, _ := dp.Attributes.Value(attribute.Key("cache.result"))
+				counts[result.AsString()] = dp.Value
+			}
+		}
+	}
+
+	if counts[ResultMiss] != 1 || counts[ResultHit] != 2 {
+		t.Errorf("expected 1 miss and 2 hits, got %v", counts)
+	}
+}
+
+func TestTiered_FillsLocalFromRemote(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemory(10)
+	remote := NewMemory(10)
+	tiered := NewTiered(local, remote, time.Minute)
+
+	_ = remote.Set(ctx, "key", []byte("value"), time.Hour)
+
+	value, ok, err := tiered.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "value" {
+		t.Fatalf("expected remote value, got %q %v %v", value, ok, err)
+	}
+	if _, ok, _ := local.Get(ctx, "key"); !ok {
+		t.Error("expected the local store to be filled")
+	}
+
+	_ = tiered.Delete(ctx, "key")
+	if _, ok, _ := remote.Get(ctx, "key"); ok {
+		t.Error("expected delete to reach the remote store")
+	}
+}
@@ -0,0 +1,156 @@
+// Package cache provides read-through caching with request coalescing,
+// stale-while-revalidate and negative caching over pluggable stores
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Store holds encoded cache entries
+// Remote caches such as Redis or Memcached implement it to be shared across instances
+type Store interface {
+	// Get returns the value stored under key, reporting false when it is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key until ttl elapses
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key
+	Delete(ctx context.Context, key string) error
+}
+
+// Memory is an in-process LRU store with per-entry expiry
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	// order holds entries most recently used first
+	order *list.List
+	now   func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory creates a store holding at most maxEntries, evicting the least recently used
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &Memory{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get returns an unexpired value and marks it as recently used
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !m.now().Before(entry.expiresAt) {
+		m.remove(elem)
+		return nil, false, nil
+	}
+
+	m.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores a value, evicting the least recently used entry when full
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := m.now().Add(ttl)
+	if elem, ok := m.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.order.MoveToFront(elem)
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// Delete removes a value
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.items[key]; ok {
+		m.remove(elem)
+	}
+	return nil
+}
+
+// Len returns the number of stored entries, including expired ones not yet evicted
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// remove deletes an element; the caller holds m.mu
+func (m *Memory) remove(elem *list.Element) {
+	m.order.Remove(elem)
+	delete(m.items, elem.Value.(*memoryEntry).key)
+}
+
+// Tiered checks a local store before a remote one and fills the local store
+// from remote hits, so hot keys are served in-process while instances share entries
+type Tiered struct {
+	local    Store
+	remote   Store
+	localTTL time.Duration
+}
+
+// NewTiered layers local over remote; entries copied from remote are kept locally for localTTL
+func NewTiered(local, remote Store, localTTL time.Duration) *Tiered {
+	if localTTL <= 0 {
+		localTTL = 10 * time.Second
+	}
+	return &Tiered{local: local, remote: remote, localTTL: localTTL}
+}
+
+// Get reads the local store, then the remote store
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if value, ok, err := t.local.Get(ctx, key); err == nil && ok {
+		return value, true, nil
+	}
+
+	value, ok, err := t.remote.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	_ = t.local.Set(ctx, key, value, t.localTTL)
+	return value, true, nil
+}
+
+// Set writes both stores
+func (t *Tiered) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_ = t.local.Set(ctx, key, value, min(ttl, t.localTTL))
+	return t.remote.Set(ctx, key, value, ttl)
+}
+
+// Delete removes the key from both stores
+func (t *Tiered) Delete(ctx context.Context, key string) error {
+	_ = t.local.Delete(ctx, key)
+	return t.remote.Delete(ctx, key)
+}
@@ -0,0 +1,236 @@
+package httpcache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/httpcapture"
+)
+
+// Config holds shared response cache configuration
+type Config struct {
+	// MaxBodyBytes is the largest response body stored; larger responses pass through uncached
+	MaxBodyBytes int64
+}
+
+// ResponseCache is a shared HTTP cache for GET and HEAD responses
+// Responses are stored when their Cache-Control allows shared caching
+// (public with s-maxage or max-age) and are keyed by the request headers named in Vary
+type ResponseCache struct {
+	store   cache.Store
+	maxBody int64
+	now     func() time.Time
+}
+
+// storedResponse is a cached response
+type storedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// variants records the Vary header of the responses cached for a URL
+type variants struct {
+	Vary []string `json:"vary"`
+}
+
+// New creates a response cache over store
+func New(store cache.Store, cfg Config) *ResponseCache {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 1 << 20
+	}
+	return &ResponseCache{
+		store:   store,
+		maxBody: cfg.MaxBodyBytes,
+		now:     time.Now,
+	}
+}
+
+// Middleware serves cached responses and stores cacheable ones
+// Requests with credentials are never served from or stored in the shared cache
+func (c *ResponseCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		base := r.Host + " " + r.URL.RequestURI()
+		directives := ParseCacheControl(r.Header.Get("Cache-Control"))
+		_, noCache := directives["no-cache"]
+		_, noStore := directives["no-store"]
+
+		if !noCache && !noStore {
+			if stored, ok := c.lookup(ctx, base, r); ok {
+				c.serve(w, r, stored)
+				return
+			}
+		}
+
+		w.Header().Set("X-Cache", "MISS")
+		capture := httpcapture.NewCapture(w, c.maxBody)
+		next.ServeHTTP(capture, r)
+
+		if !noStore {
+			c.save(ctx, base, r, capture)
+		}
+	})
+}
+
+// lookup finds the stored variant matching the request
+func (c *ResponseCache) lookup(ctx context.Context, base string, r *http.Request) (*storedResponse, bool) {
+	data, ok, err := c.store.Get(ctx, base)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var v variants
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, false
+	}
+
+	data, ok, err = c.store.Get(ctx, variantKey(base, v.Vary, r))
+	if err != nil || !ok {
+		return nil, false
+	}
+	var stored storedResponse
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, false
+	}
+	return &stored, true
+}
+
+// serve writes a stored response, answering conditional requests with 304
+// Headers already set by outer middleware, such as request and trace IDs, are kept
+func (c *ResponseCache) serve(w http.ResponseWriter, r *http.Request, stored *storedResponse) {
+	header := w.Header()
+	for key, values := range stored.Header {
+		if _, ok := header[key]; !ok {
+			header[key] = slices.Clone(values)
+		}
+	}
+	header.Set("Age", strconv.FormatInt(int64(c.now().Sub(stored.StoredAt).Seconds()), 10))
+	header.Set("X-Cache", "HIT")
+
+	if NotModified(r, header) {
+		WriteNotModified(w)
+		return
+	}
+
+	w.WriteHeader(stored.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(stored.Body)
+	}
+}
+
+// save stores the captured response if it may be shared
+func (c *ResponseCache) save(ctx context.Context, base string, r *http.Request, capture *httpcapture.Writer) {
+	if capture.Status() != http.StatusOK || capture.Overflowed() || capture.Streamed() {
+		return
+	}
+	header := capture.Header()
+	if header.Get("Set-Cookie") != "" {
+		return
+	}
+	ttl, ok := sharedTTL(header.Get("Cache-Control"))
+	if !ok {
+		return
+	}
+
+	vary := varyHeaders(header)
+	if slices.Contains(vary, "*") {
+		return
+	}
+
+	stored := storedResponse{
+		Status:   capture.Status(),
+		Header:   header.Clone(),
+		Body:     capture.Body(),
+		StoredAt: c.now(),
+	}
+	stored.Header.Del("X-Cache")
+	stored.Header.Del("Age")
+
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return
+	}
+	index, err := json.Marshal(&variants{Vary: vary})
+	if err != nil {
+		return
+	}
+
+	// Caching is best effort; the response has already been sent
+	_ = c.store.Set(ctx, base, index, ttl)
+	_ = c.store.Set(ctx, variantKey(base, vary, r), data, ttl)
+}
+
+// sharedTTL returns how long a response with the given Cache-Control may be stored in a shared cache
+func sharedTTL(cacheControl string) (time.Duration, bool) {
+	directives := ParseCacheControl(cacheControl)
+	for _, deny := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[deny]; ok {
+			return 0, false
+		}
+	}
+
+	age, ok := directives["s-maxage"]
+	if !ok {
+		age, ok = directives["max-age"]
+	}
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(age)
+	if err != nil || seconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// ParseCacheControl parses a Cache-Control header into lowercase directives and their values
+func ParseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for part := range strings.SplitSeq(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+	}
+	return directives
+}
+
+// varyHeaders returns the canonical header names listed in Vary, sorted
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// variantKey extends the URL key with the request's values of the Vary headers
+func variantKey(base string, vary []string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, name := range vary {
+		b.WriteString("\x00")
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package httpcache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ahxar/go-backend-service/pkg/cache"
+)
+
+// countingHandler responds with the number of times it has been called
+func countingHandler(cacheControl string, calls *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		w.Header().Set("Vary", "Accept-Language")
+		body := fmt.Sprintf("%s %d", r.Header.Get("Accept-Language"), n)
+		w.Header().Set("ETag", ETag([]byte(body)))
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func get(h http.Handler, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/example?name=test", http.NoBody)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponseCache_HitAndMiss(t *testing.T) {
+	var calls atomic.Int32
+	h := New(cache.NewMemory(100), Config{}).Middleware(countingHandler("public, max-age=60", &calls))
+
+	first := get(h, nil)
+	if first.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("expected first request to miss, got %q", first.Header().Get("X-Cache"))
+	}
+
+	second := get(h, nil)
+	if second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("expected second request to hit, got %q", second.Header().Get("X-Cache"))
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected cached body %q, got %q", first.Body.String(), second.Body.String())
+	}
+	if second.Header().Get("Age") == "" {
+		t.Error("expected an Age header on cached responses")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 handler call, got %d", calls.Load())
+	}
+
+	// A client demanding revalidation bypasses the stored response
+	_ = get(h, map[string]string{"Cache-Control": "no-cache"})
+	if calls.Load() != 2 {
+		t.Errorf("expected no-cache to reach the handler, got %d calls", calls.Load())
+	}
+}
+
+func TestResponseCache_Vary(t *testing.T) {
+	var calls atomic.Int32
+	h := New(cache.NewMemory(100), Config{}).Middleware(countingHandler("public, max-age=60", &calls))
+
+	en := get(h, map[string]string{"Accept-Language": "en"})
+	de := get(h, map[string]string{"Accept-Language": "de"})
+	if en.Body.String() == de.Body.String() {
+		t.Error("expected variants to be cached separately")
+	}
+
+	again := get(h, map[string]string{"Accept-Language": "de"})
+	if again.Header().Get("X-Cache") != "HIT" || again.Body.String() != de.Body.String() {
+		t.Errorf("expected the de variant to be served from cache, got %q", again.Body.String())
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 handler calls, got %d", calls.Load())
+	}
+}
+
+func TestResponseCache_ConditionalHit(t *testing.T) {
+	var calls atomic.Int32
+	h := New(cache.NewMemory(100), Config{}).Middleware(countingHandler("public, max-age=60", &calls))
+
+	first := get(h, nil)
+	rec := get(h, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected status 304, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected an empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestResponseCache_NotStored(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		header       map[string]string
+		maxBody      int64
+	}{
+		{name: "private", cacheControl: "private, max-age=60"},
+		{name: "no-store", cacheControl: "no-store"},
+		{name: "no freshness", cacheControl: "public"},
+		{name: "authorization", cacheControl: "public, max-age=60", header: map[string]string{"Authorization": "Bearer token"}},
+		{name: "request no-store", cacheControl: "public, max-age=60", header: map[string]string{"Cache-Control": "no-store"}},
+		{name: "too large", cacheControl: "public, max-age=60", maxBody: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			h := New(cache.NewMemory(100), Config{MaxBodyBytes: tt.maxBody}).Middleware(countingHandler(tt.cacheControl, &calls))
+
+			_ = get(h, tt.header)
+			_ = get(h, tt.header)
+			if calls.Load() != 2 {
+				t.Errorf("expected the response not to be cached, got %d calls", calls.Load())
+			}
+		})
+	}
+}
+
+func TestResponseCache_KeepsOuterHeaders(t *testing.T) {
+	var calls atomic.Int32
+	inner := New(cache.NewMemory(100), Config{}).Middleware(countingHandler("public, max-age=60", &calls))
+
+	var id atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", fmt.Sprint(id.Add(1)))
+		inner.ServeHTTP(w, r)
+	})
+
+	_ = get(h, nil)
+	rec := get(h, nil)
+	if rec.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("expected a hit, got %q", rec.Header().Get("X-Cache"))
+	}
+	if rec.Header().Get("X-Request-ID") != "2" {
+		t.Errorf("expected the current request ID, got %q", rec.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestResponseCache_StreamedResponsesAreNotStored(t *testing.T) {
+	var calls atomic.Int32
+	h := New(cache.NewMemory(100), Config{}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		_, _ = w.Write([]byte("chunk"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("expected flush to pass through: %v", err)
+		}
+	}))
+
+	_ = get(h, nil)
+	_ = get(h, nil)
+	if calls.Load() != 2 {
+		t.Errorf("expected streamed responses not to be cached, got %d calls", calls.Load())
+	}
+}
@@ -0,0 +1,99 @@
+// Package httpcache implements HTTP validators, conditional requests and a shared response cache
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ETag returns a strong entity tag derived from a hash of body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// VersionETag returns a strong entity tag for a resource version
+func VersionETag(version int64) string {
+	return `"v` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseVersionETag extracts the version from a tag created by VersionETag
+func ParseVersionETag(tag string) (int64, bool) {
+	tag = strings.TrimSpace(tag)
+	value, ok := strings.CutPrefix(tag, `"v`)
+	if !ok {
+		return 0, false
+	}
+	value, ok = strings.CutSuffix(value, `"`)
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(value, 10, 64)
+	return version, err == nil
+}
+
+// NotModified reports whether a GET or HEAD request's validators match the
+// response headers, so a 304 can be sent instead of the body
+// If-None-Match takes precedence over If-Modified-Since as required by RFC 9110
+func NotModified(r *http.Request, header http.Header) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return MatchETag(inm, header.Get("ETag"), true)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	lastModified := header.Get("Last-Modified")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// MatchETag reports whether etag is in the comma-separated list of tags,
+// which may be "*"; weak comparison ignores the W/ prefix
+func MatchETag(list, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	for candidate := range strings.SplitSeq(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+		// Strong comparison never matches weak tags
+		if !strings.HasPrefix(candidate, "W/") && !strings.HasPrefix(etag, "W/") && candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteNotModified sends a 304, dropping headers that describe the omitted body
+func WriteNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	w.WriteHeader(http.StatusNotModified)
+}
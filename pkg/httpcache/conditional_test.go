@@ -0,0 +1,97 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETag_IsStableAndQuoted(t *testing.T) {
+	a := ETag([]byte("hello"))
+	if a != ETag([]byte("hello")) {
+		t.Error("expected the same body to produce the same tag")
+	}
+	if a == ETag([]byte("world")) {
+		t.Error("expected different bodies to produce different tags")
+	}
+	if a[0] != '"' || a[len(a)-1] != '"' {
+		t.Errorf("expected a quoted tag, got %s", a)
+	}
+}
+
+func TestVersionETag_RoundTrip(t *testing.T) {
+	version, ok := ParseVersionETag(VersionETag(42))
+	if !ok || version != 42 {
+		t.Errorf("expected version 42, got %d %v", version, ok)
+	}
+
+	for _, tag := range []string{"", `"42"`, `W/"v42"`, `"vx"`, `"v42`} {
+		if _, ok := ParseVersionETag(tag); ok {
+			t.Errorf("expected %q to be rejected", tag)
+		}
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		list string
+		etag string
+		weak bool
+		want bool
+	}{
+		{`"a"`, `"a"`, false, true},
+		{`"b", "a"`, `"a"`, false, true},
+		{`"b"`, `"a"`, false, false},
+		{`*`, `"a"`, false, true},
+		{`W/"a"`, `"a"`, true, true},
+		{`W/"a"`, `"a"`, false, false},
+		{`"a"`, ``, true, false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchETag(tt.list, tt.etag, tt.weak); got != tt.want {
+			t.Errorf("MatchETag(%q, %q, %v) = %v, want %v", tt.list, tt.etag, tt.weak, got, tt.want)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Set("ETag", `"abc"`)
+	header.Set("Last-Modified", modified.Format(http.TimeFormat))
+
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		want   bool
+	}{
+		{"matching etag", http.MethodGet, map[string]string{"If-None-Match": `"abc"`}, true},
+		{"weak etag", http.MethodHead, map[string]string{"If-None-Match": `W/"abc"`}, true},
+		{"other etag", http.MethodGet, map[string]string{"If-None-Match": `"xyz"`}, false},
+		{"unsafe method", http.MethodPost, map[string]string{"If-None-Match": `"abc"`}, false},
+		{"not modified since", http.MethodGet, map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, true},
+		{"modified since", http.MethodGet, map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, false},
+		{
+			"etag takes precedence",
+			http.MethodGet,
+			map[string]string{"If-None-Match": `"xyz"`, "If-Modified-Since": modified.Format(http.TimeFormat)},
+			false,
+		},
+		{"no validators", http.MethodGet, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", http.NoBody)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if got := NotModified(req, header); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
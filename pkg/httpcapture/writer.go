@@ -0,0 +1,185 @@
+// Package httpcapture wraps response writers to record the status, size and
+// timing of responses and, for middleware that replays them, their body
+package httpcapture
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Writer wraps http.ResponseWriter to record the status code, bytes written
+// and time to first byte, and optionally a copy of the body
+// It always implements http.Flusher, http.Hijacker and io.ReaderFrom, delegating
+// to the underlying writer when it supports them, and exposes Unwrap so
+// http.ResponseController reaches the original writer
+type Writer struct {
+	http.ResponseWriter
+	start       time.Time
+	firstByte   time.Time
+	status      int
+	bytes       int64
+	wroteHeader bool
+
+	// Body capture, enabled by NewCapture
+	capture  bool
+	body     bytes.Buffer
+	max      int64
+	overflow bool
+	streamed bool
+}
+
+// Wrap returns a Writer recording the status, size and timing of w
+// It returns w itself when it is already a Writer so a middleware chain
+// shares a single recorder
+func Wrap(w http.ResponseWriter) *Writer {
+	if cw, ok := w.(*Writer); ok {
+		return cw
+	}
+	return &Writer{ResponseWriter: w, start: time.Now(), status: http.StatusOK}
+}
+
+// NewCapture wraps w like Wrap and also keeps at most maxBody bytes of the body
+// Event streams and flushed or hijacked responses are not buffered, since they
+// cannot be replayed
+func NewCapture(w http.ResponseWriter, maxBody int64) *Writer {
+	return &Writer{ResponseWriter: w, start: time.Now(), status: http.StatusOK, capture: true, max: maxBody}
+}
+
+// Status returns the response status, 200 if nothing was written yet
+func (cw *Writer) Status() int {
+	return cw.status
+}
+
+// BytesWritten returns the number of body bytes written
+func (cw *Writer) BytesWritten() int64 {
+	return cw.bytes
+}
+
+// TimeToFirstByte returns the time from wrapping until the header was written,
+// or 0 if nothing was written
+func (cw *Writer) TimeToFirstByte() time.Duration {
+	if cw.firstByte.IsZero() {
+		return 0
+	}
+	return cw.firstByte.Sub(cw.start)
+}
+
+// Body returns the captured body, empty if it overflowed the limit or streamed
+func (cw *Writer) Body() []byte {
+	return cw.body.Bytes()
+}
+
+// Overflowed reports whether the body exceeded the limit and was discarded
+func (cw *Writer) Overflowed() bool {
+	return cw.overflow
+}
+
+// Streamed reports whether the response was an event stream or the handler
+// flushed or hijacked, which makes the response unreplayable
+func (cw *Writer) Streamed() bool {
+	return cw.streamed
+}
+
+func (cw *Writer) WriteHeader(code int) {
+	// Informational responses may precede the final status
+	if !cw.wroteHeader && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		cw.status = code
+		cw.wroteHeader = true
+		cw.firstByte = time.Now()
+		if strings.HasPrefix(strings.ToLower(cw.Header().Get("Content-Type")), "text/event-stream") {
+			cw.stream()
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *Writer) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	n, err := cw.ResponseWriter.Write(b)
+	cw.bytes += int64(n)
+	cw.keep(b[:n])
+	return n, err
+}
+
+// keep appends b to the captured body until it overflows the limit
+func (cw *Writer) keep(b []byte) {
+	if !cw.capturing() {
+		return
+	}
+	if int64(cw.body.Len()+len(b)) > cw.max {
+		cw.overflow = true
+		cw.body = bytes.Buffer{}
+		return
+	}
+	cw.body.Write(b)
+}
+
+// capturing reports whether written bytes are still buffered
+func (cw *Writer) capturing() bool {
+	return cw.capture && !cw.overflow && !cw.streamed
+}
+
+// stream marks the response unreplayable and releases the captured body
+func (cw *Writer) stream() {
+	cw.streamed = true
+	cw.body = bytes.Buffer{}
+}
+
+// Flush sends buffered data to the client if the underlying writer supports it
+func (cw *Writer) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	cw.stream()
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection, e.g. for WebSockets
+func (cw *Writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	cw.stream()
+	conn, buf, err := http.NewResponseController(cw.ResponseWriter).Hijack()
+	if err == nil && !cw.wroteHeader {
+		cw.status = http.StatusSwitchingProtocols
+		cw.wroteHeader = true
+		cw.firstByte = time.Now()
+	}
+	return conn, buf, err
+}
+
+// ReadFrom copies r through Write while the body is captured; otherwise it
+// hands off to the underlying writer's optimized path, such as sendfile
+func (cw *Writer) ReadFrom(r io.Reader) (int64, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.capturing() {
+		// Hide ReadFrom from io.Copy to avoid recursing into this method
+		return io.Copy(writerOnly{cw}, r)
+	}
+	var n int64
+	var err error
+	if rf, ok := cw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(writerOnly{cw.ResponseWriter}, r)
+	}
+	cw.bytes += n
+	return n, err
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (cw *Writer) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// writerOnly hides every method but Write
+type writerOnly struct {
+	io.Writer
+}
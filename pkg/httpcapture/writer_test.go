@@ -0,0 +1,171 @@
+package httpcapture
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCapture_Captures(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewCapture(rec, 64)
+
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte("hello "))
+	_, _ = io.Copy(w, strings.NewReader("world"))
+
+	if w.Status() != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", w.Status())
+	}
+	if string(w.Body()) != "hello world" {
+		t.Errorf("expected captured body %q, got %q", "hello world", w.Body())
+	}
+	if rec.Body.String() != "hello world" {
+		t.Errorf("expected body passed through, got %q", rec.Body.String())
+	}
+	if w.Overflowed() || w.Streamed() {
+		t.Error("expected a complete, unstreamed capture")
+	}
+}
+
+func TestCapture_Overflow(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewCapture(rec, 4)
+
+	_, _ = io.Copy(w, strings.NewReader("too long"))
+	_, _ = io.Copy(w, strings.NewReader(" still"))
+
+	if !w.Overflowed() {
+		t.Error("expected overflow")
+	}
+	if len(w.Body()) != 0 {
+		t.Errorf("expected discarded body, got %q", w.Body())
+	}
+	if rec.Body.String() != "too long still" {
+		t.Errorf("expected body passed through, got %q", rec.Body.String())
+	}
+}
+
+func TestCapture_ResponseController(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewCapture(rec, 64)
+
+	if err := http.NewResponseController(w).Flush(); err != nil {
+		t.Fatalf("expected flush to reach the recorder: %v", err)
+	}
+	if !rec.Flushed {
+		t.Error("expected recorder to be flushed")
+	}
+	if !w.Streamed() {
+		t.Error("expected flushed response to be marked streamed")
+	}
+	if _, _, err := w.Hijack(); err == nil {
+		t.Error("expected hijack to fail on a recorder")
+	}
+}
+
+func TestCapture_SkipsEventStreams(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewCapture(rec, 64)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	_, _ = w.Write([]byte("data: hello\n\n"))
+
+	if !w.Streamed() {
+		t.Error("expected event stream to be marked streamed")
+	}
+	if len(w.Body()) != 0 {
+		t.Errorf("expected event stream not to be buffered, got %q", w.Body())
+	}
+	if w.BytesWritten() != 13 {
+		t.Errorf("expected 13 bytes recorded, got %d", w.BytesWritten())
+	}
+}
+
+func TestCapture_StopsBufferingAfterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewCapture(rec, 64)
+
+	_, _ = w.Write([]byte("first"))
+	w.Flush()
+	_, _ = w.Write([]byte("second"))
+
+	if len(w.Body()) != 0 {
+		t.Errorf("expected flushed response not to be buffered, got %q", w.Body())
+	}
+	if rec.Body.String() != "firstsecond" {
+		t.Errorf("expected body passed through, got %q", rec.Body.String())
+	}
+}
+
+func TestWrap_ImplicitStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := Wrap(rec)
+
+	_, _ = rw.Write([]byte("hello"))
+	rw.WriteHeader(http.StatusInternalServerError)
+
+	if rw.Status() != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rw.Status())
+	}
+	if rw.BytesWritten() != 5 {
+		t.Errorf("expected 5 bytes, got %d", rw.BytesWritten())
+	}
+	if rw.TimeToFirstByte() <= 0 {
+		t.Error("expected time to first byte to be recorded")
+	}
+}
+
+func TestWrap_InformationalStatus(t *testing.T) {
+	rw := Wrap(httptest.NewRecorder())
+
+	rw.WriteHeader(http.StatusEarlyHints)
+	rw.WriteHeader(http.StatusNotFound)
+
+	if rw.Status() != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rw.Status())
+	}
+}
+
+func TestWrap_ReusesWrapper(t *testing.T) {
+	rw := Wrap(httptest.NewRecorder())
+	if Wrap(rw) != rw {
+		t.Error("expected an existing wrapper to be reused")
+	}
+}
+
+func TestWrap_ReadFrom(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := Wrap(rec)
+
+	n, err := io.Copy(rw, strings.NewReader("streamed body"))
+	if err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	if n != 13 || rw.BytesWritten() != 13 {
+		t.Errorf("expected 13 bytes, got %d (recorded %d)", n, rw.BytesWritten())
+	}
+	if rec.Body.String() != "streamed body" {
+		t.Errorf("unexpected body %q", rec.Body.String())
+	}
+}
+
+func TestWrap_ResponseController(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := Wrap(rec)
+
+	if err := http.NewResponseController(rw).Flush(); err != nil {
+		t.Fatalf("expected flush to reach the recorder: %v", err)
+	}
+	if !rec.Flushed {
+		t.Error("expected recorder to be flushed")
+	}
+
+	// The recorder does not support hijacking
+	if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
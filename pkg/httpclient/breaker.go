@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrCircuitOpen is returned without sending a request while a host's circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// BreakerConfig holds circuit breaker configuration
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed attempts that opens a
+	// host's circuit; negative disables the breaker
+	FailureThreshold int
+	// OpenTimeout is how long an open circuit rejects requests before one probe is let through
+	OpenTimeout time.Duration
+}
+
+// maxCircuits bounds the hosts tracked at once; further hosts go untracked
+// until others recover, so a client reaching many hosts cannot grow without limit
+const maxCircuits = 1024
+
+// Circuit states
+const (
+	stateClosed   = "closed"
+	stateOpen     = "open"
+	stateHalfOpen = "half-open"
+)
+
+// circuit tracks the health of one host
+type circuit struct {
+	state    string
+	failures int
+	openedAt time.Time
+	// probing is set while the one half-open request is in flight
+	probing bool
+}
+
+// breakerTransport fails fast to hosts whose recent attempts failed
+// Transport errors and 5xx responses count as failures; a half-open circuit
+// closes after one successful probe and reopens if it fails
+// Only hosts with recent failures are tracked; a closed circuit is forgotten
+// on the next success
+type breakerTransport struct {
+	next       http.RoundTripper
+	name       string
+	cfg        BreakerConfig
+	mu         sync.Mutex
+	circuits   map[string]*circuit
+	rejections metric.Int64Counter
+	now        func() time.Time
+}
+
+func newBreakerTransport(next http.RoundTripper, name string, cfg BreakerConfig) http.RoundTripper {
+	if cfg.FailureThreshold < 0 {
+		return next
+	}
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+
+	rejections, err := otel.Meter(instrumentationName).Int64Counter("http.client.circuit.rejections",
+		metric.WithDescription("Outgoing HTTP requests rejected by an open circuit"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &breakerTransport{
+		next:       next,
+		name:       name,
+		cfg:        cfg,
+		circuits:   make(map[string]*circuit),
+		rejections: rejections,
+		now:        time.Now,
+	}
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if !t.allow(host) {
+		t.rejections.Add(req.Context(), 1, metric.WithAttributes(
+			attribute.String("http.client.name", t.name),
+			attribute.String("net.peer.name", req.URL.Hostname()),
+		))
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case errors.Is(err, context.Canceled):
+		// The caller gave up, which says nothing about the host
+		t.release(host)
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		t.record(host, false)
+	default:
+		t.record(host, true)
+	}
+	return resp, err
+}
+
+// allow reports whether a request to host may be sent, moving an open
+// circuit to half-open once OpenTimeout has passed
+func (t *breakerTransport) allow(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.circuits[host]
+	if !ok {
+		return true
+	}
+
+	switch c.state {
+	case stateOpen:
+		if t.now().Sub(c.openedAt) < t.cfg.OpenTimeout {
+			return false
+		}
+		c.state = stateHalfOpen
+		c.probing = true
+		return true
+	case stateHalfOpen:
+		if c.probing {
+			return false
+		}
+		c.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates host's circuit with the outcome of an attempt
+func (t *breakerTransport) record(host string, success bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.circuits[host]
+	if success {
+		delete(t.circuits, host)
+		return
+	}
+	if !ok {
+		if len(t.circuits) >= maxCircuits {
+			return
+		}
+		c = &circuit{state: stateClosed}
+		t.circuits[host] = c
+	}
+
+	c.probing = false
+	c.failures++
+	if c.state == stateHalfOpen || c.failures >= t.cfg.FailureThreshold {
+		c.state = stateOpen
+		c.openedAt = t.now()
+	}
+}
+
+// release frees the half-open probe slot without recording an outcome
+func (t *breakerTransport) release(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c, ok := t.circuits[host]; ok {
+		c.probing = false
+	}
+}
@@ -0,0 +1,166 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// RequestBody is matched exactly on replay
+	RequestBody string      `json:"request_body,omitempty"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+// Cassette is a test transport that records interactions with a real
+// service, or replays recorded ones without touching the network
+// Replay serves each interaction once, in recorded order among those matching
+// the method, URL and body, so sequences such as a failure then a success replay faithfully
+type Cassette struct {
+	mu           sync.Mutex
+	base         http.RoundTripper
+	interactions []Interaction
+	used         []bool
+}
+
+// droppedHeaders are removed from recorded responses so cassettes carry no
+// session cookies and do not change each time they are re-recorded
+var droppedHeaders = []string{"Set-Cookie", "Date"}
+
+// NewRecorder creates a cassette recording the interactions sent through base,
+// or http.DefaultTransport when nil
+func NewRecorder(base http.RoundTripper) *Cassette {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Cassette{base: base}
+}
+
+// NewReplayer creates a cassette replaying interactions
+func NewReplayer(interactions ...Interaction) *Cassette {
+	return &Cassette{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+// LoadCassette creates a cassette replaying the interactions saved at path
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is chosen by the test
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette: %w", err)
+	}
+	return NewReplayer(interactions...), nil
+}
+
+// Save writes the recorded interactions to path
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Interactions returns the interactions recorded or loaded so far
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// RoundTrip records or replays one request
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	if c.base == nil {
+		return c.replay(req, string(body))
+	}
+	return c.record(req, body)
+}
+
+// replay serves the first unused interaction matching the request
+func (c *Cassette) replay(req *http.Request, body string) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, in := range c.interactions {
+		if c.used[i] || in.Method != req.Method || in.URL != req.URL.String() || in.RequestBody != body {
+			continue
+		}
+		c.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+			StatusCode:    in.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewBufferString(in.Body)),
+			ContentLength: int64(len(in.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, req.URL)
+}
+
+// record sends the request through base and stores the exchange
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	if body != nil {
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := c.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	for _, name := range droppedHeaders {
+		header.Del(name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, Interaction{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		RequestBody: string(body),
+		StatusCode:  resp.StatusCode,
+		Header:      header,
+		Body:        string(respBody),
+	})
+	c.used = append(c.used, false)
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+// Package httpclient builds outgoing HTTP clients with per-attempt timeouts,
+// jittered retries honoring Retry-After, a circuit breaker per host, client
+// spans with trace-context propagation, request-ID forwarding and metrics
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// instrumentationName identifies the spans and instruments created by this package
+const instrumentationName = "github.com/ahxar/go-backend-service/pkg/httpclient"
+
+// Config holds client configuration
+type Config struct {
+	// Name labels the client's spans and metrics, e.g. the remote service
+	Name string
+	// Timeout bounds a whole call including retries; 0 relies on the request context
+	Timeout time.Duration
+	// AttemptTimeout bounds each attempt, including reading the response body
+	AttemptTimeout time.Duration
+	// MaxRetries is the number of retries after the first attempt; 0 disables retries
+	MaxRetries int
+	// BackoffBase is the delay before the first retry, doubled for each further retry
+	BackoffBase time.Duration
+	// BackoffMax caps the backoff delay
+	BackoffMax time.Duration
+	// MaxRetryAfter is the longest Retry-After honored; a longer one returns the response without retrying
+	MaxRetryAfter time.Duration
+	// Breaker configures the circuit breaker kept per host
+	Breaker BreakerConfig
+}
+
+// New creates a client sending requests through base, or http.DefaultTransport when nil
+// Attempts pass, outermost first, through retries, the circuit breaker,
+// instrumentation and request-ID forwarding
+func New(base http.RoundTripper, cfg Config) *http.Client {
+	if cfg.Name == "" {
+		cfg.Name = "default"
+	}
+	if cfg.AttemptTimeout <= 0 {
+		cfg.AttemptTimeout = 10 * time.Second
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = 100 * time.Millisecond
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = 5 * time.Second
+	}
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = 30 * time.Second
+	}
+
+	var transport http.RoundTripper = requestid.NewTransport(base)
+	transport = newInstrumentedTransport(transport, cfg.Name)
+	transport = newBreakerTransport(transport, cfg.Name, cfg.Breaker)
+	transport = newRetryTransport(transport, cfg)
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}
+}
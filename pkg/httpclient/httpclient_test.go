@@ -0,0 +1,291 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// newTestClient creates a client whose retries record their delays instead of sleeping
+func newTestClient(base http.RoundTripper, cfg Config) (*http.Client, *[]time.Duration) {
+	client := New(base, cfg)
+	var delays []time.Duration
+	client.Transport.(*retryTransport).sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return client, &delays
+}
+
+func TestClient_RetriesHonoringRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, delays := newTestClient(nil, Config{MaxRetries: 2, BackoffBase: time.Millisecond})
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("unexpected response %d %q", resp.StatusCode, body)
+	}
+	if len(*delays) != 1 || (*delays)[0] != 2*time.Second {
+		t.Errorf("expected one retry after the 2s Retry-After, got %v", *delays)
+	}
+}
+
+func TestClient_RetryPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		header     http.Header
+		retryAfter string
+		want       int32
+	}{
+		{"idempotent method", http.MethodGet, nil, "", 3},
+		{"unsafe method", http.MethodPost, nil, "", 1},
+		{"unsafe method with idempotency key", http.MethodPost, http.Header{"Idempotency-Key": {"k1"}}, "", 3},
+		{"retry-after beyond the limit", http.MethodGet, nil, "3600", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if body, _ := io.ReadAll(r.Body); r.Method == http.MethodPost && string(body) != "payload" {
+					t.Errorf("expected the body to be replayed, got %q", body)
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+
+			client, _ := newTestClient(nil, Config{MaxRetries: 2, Breaker: BreakerConfig{FailureThreshold: -1}})
+			req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader("payload"))
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != http.StatusTooManyRequests {
+				t.Errorf("expected the last response to be returned, got %d", resp.StatusCode)
+			}
+			if calls.Load() != tt.want {
+				t.Errorf("expected %d attempts, got %d", tt.want, calls.Load())
+			}
+		})
+	}
+}
+
+func TestClient_AttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(nil, Config{MaxRetries: 2, AttemptTimeout: 50 * time.Millisecond})
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the timed out attempt to be retried, got %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || calls.Load() != 2 {
+		t.Errorf("expected success on the second attempt, got %d after %d attempts", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	client, _ := newTestClient(nil, Config{
+		Breaker: BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute},
+	})
+	client.Transport.(*retryTransport).next.(*breakerTransport).now = func() time.Time { return now }
+
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	_ = get()
+	_ = get()
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after 2 failures, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected the open circuit not to send requests, got %d", calls.Load())
+	}
+
+	// After the open timeout one probe is let through and closes the circuit
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	if err := get(); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if err := get(); err != nil {
+		t.Errorf("expected the circuit to be closed, got %v", err)
+	}
+	if n := len(client.Transport.(*retryTransport).next.(*breakerTransport).circuits); n != 0 {
+		t.Errorf("expected recovered hosts to be forgotten, got %d circuits", n)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBreaker_BoundsTrackedHosts(t *testing.T) {
+	failing := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	breaker := newBreakerTransport(failing, "test", BreakerConfig{}).(*breakerTransport)
+
+	for i := range maxCircuits + 10 {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://host-%d.internal/", i), http.NoBody)
+		_, _ = breaker.RoundTrip(req)
+	}
+	if n := len(breaker.circuits); n != maxCircuits {
+		t.Errorf("expected %d tracked hosts, got %d", maxCircuits, n)
+	}
+}
+
+func TestClient_PropagatesTraceAndRequestID(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	ctx = requestid.NewContext(ctx, "req-1")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := New(nil, Config{Name: "upstream"}).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	parent.End()
+
+	if header.Get(requestid.Header) != "req-1" {
+		t.Errorf("expected the request ID to be forwarded, got %q", header.Get(requestid.Header))
+	}
+	traceID := parent.SpanContext().TraceID().String()
+	if !strings.Contains(header.Get("Traceparent"), traceID) {
+		t.Errorf("expected traceparent of trace %s, got %q", traceID, header.Get("Traceparent"))
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "HTTP GET" || spans[0].Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected a client span under the parent, got %+v", spans)
+	}
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write(append([]byte("echo:"), body...))
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder(nil)
+	resp, err := New(recorder, Config{}).Post(server.URL, "text/plain", strings.NewReader("hi"))
+	if err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	server.Close()
+
+	replayer, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	client := New(replayer, Config{})
+	resp, err = client.Post(server.URL, "text/plain", strings.NewReader("hi"))
+	if err != nil {
+		t.Fatalf("failed to replay: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if string(body) != "echo:hi" || resp.Header.Get("Content-Type") != "text/plain" || calls.Load() != 1 {
+		t.Errorf("unexpected replay %q %v after %d calls", body, resp.Header, calls.Load())
+	}
+
+	// Each interaction is served once
+	if _, err := client.Post(server.URL, "text/plain", strings.NewReader("hi")); err == nil {
+		t.Error("expected no interaction to be left")
+	}
+}
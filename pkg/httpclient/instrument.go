@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedTransport records a client span and the duration of each attempt
+// and propagates the trace context to the remote service
+type instrumentedTransport struct {
+	next     http.RoundTripper
+	name     string
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	now      func() time.Time
+}
+
+func newInstrumentedTransport(next http.RoundTripper, name string) *instrumentedTransport {
+	duration, err := otel.Meter(instrumentationName).Float64Histogram("http.client.duration",
+		metric.WithDescription("Duration of outgoing HTTP attempts until response headers"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &instrumentedTransport{
+		next:     next,
+		name:     name,
+		tracer:   otel.Tracer(instrumentationName),
+		duration: duration,
+		now:      time.Now,
+	}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempt, _ := req.Context().Value(attemptKey{}).(int)
+	ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.client.name", t.name),
+			attribute.String("http.method", req.Method),
+			// Redacted drops any password from the URL
+			attribute.String("http.url", req.URL.Redacted()),
+			attribute.String("net.peer.name", req.URL.Hostname()),
+			attribute.Int("http.resend_count", attempt),
+		),
+	)
+	defer span.End()
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := t.now()
+	resp, err := t.next.RoundTrip(req)
+
+	status := "error"
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		status = strconv.Itoa(resp.StatusCode)
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+	}
+
+	t.duration.Record(ctx, t.now().Sub(start).Seconds(), metric.WithAttributes(
+		attribute.String("http.client.name", t.name),
+		attribute.String("http.method", req.Method),
+		attribute.String("net.peer.name", req.URL.Hostname()),
+		attribute.String("http.status_code", status),
+	))
+	return resp, err
+}
@@ -0,0 +1,189 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// retryTransport retries failed attempts of requests that are safe to repeat
+type retryTransport struct {
+	next    http.RoundTripper
+	cfg     Config
+	retries metric.Int64Counter
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// attemptKey carries the attempt number to the instrumented transport
+type attemptKey struct{}
+
+func newRetryTransport(next http.RoundTripper, cfg Config) *retryTransport {
+	retries, err := otel.Meter(instrumentationName).Int64Counter("http.client.retries",
+		metric.WithDescription("Retried outgoing HTTP attempts"),
+		metric.WithUnit("{retry}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &retryTransport{
+		next:    next,
+		cfg:     cfg,
+		retries: retries,
+		now:     time.Now,
+		sleep:   sleep,
+	}
+}
+
+// RoundTrip sends the request, retrying transport errors, 429 and 502-504
+// responses with jittered exponential backoff, waiting at least as long as Retry-After
+// Only idempotent methods and requests with an Idempotency-Key whose body can be
+// replayed are retried; a breaker rejection or the caller's cancellation ends the retries
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := t.cfg.MaxRetries > 0 && replayable(req)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req, attempt)
+		if !retryable || attempt >= t.cfg.MaxRetries {
+			return resp, err
+		}
+		delay, ok := t.retryDelay(req, resp, err, attempt)
+		if !ok {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain a little of the body so the connection can be reused
+			_, _ = io.CopyN(io.Discard, resp.Body, 4096)
+			_ = resp.Body.Close()
+		}
+		t.retries.Add(req.Context(), 1, metric.WithAttributes(
+			attribute.String("http.client.name", t.cfg.Name),
+			attribute.String("http.method", req.Method),
+			attribute.String("net.peer.name", req.URL.Hostname()),
+		))
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends one attempt bounded by AttemptTimeout
+// The deadline is released when the response body is closed
+func (t *retryTransport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.WithValue(req.Context(), attemptKey{}, attempt), t.cfg.AttemptTimeout)
+	r := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// retryDelay reports whether an attempt's outcome is worth retrying and how long to wait first
+func (t *retryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		if errors.Is(err, ErrCircuitOpen) || req.Context().Err() != nil {
+			return 0, false
+		}
+		return t.backoff(attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	delay := t.backoff(attempt)
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After"), t.now()); ok {
+		if wait > t.cfg.MaxRetryAfter {
+			return 0, false
+		}
+		delay = max(delay, wait)
+	}
+	return delay, true
+}
+
+// backoff returns the delay before retry attempt+1: exponential, capped at
+// BackoffMax, with jitter over its upper half so clients failing together spread out
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.cfg.BackoffBase
+	for i := 0; i < attempt && d < t.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	d = min(d, t.cfg.BackoffMax)
+	half := d / 2
+	return half + rand.N(half+1) //nolint:gosec // jitter does not need a secure source
+}
+
+// replayable reports whether repeating the request is safe and possible
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cancelBody releases an attempt's deadline once its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,26 @@
+// Package httperror writes the service's JSON error responses from packages
+// that cannot use the handler's helpers
+package httperror
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// Response matches the service's JSON error shape (model.ErrorResponse)
+type Response struct {
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write sends message as a JSON error carrying the request ID of r
+func Write(w http.ResponseWriter, r *http.Request, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&Response{
+		Error:     message,
+		RequestID: requestid.FromContext(r.Context()),
+	})
+}
@@ -0,0 +1,177 @@
+// Package idempotency deduplicates retried unsafe requests by their Idempotency-Key header
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/httpcapture"
+	"github.com/ahxar/go-backend-service/pkg/httperror"
+)
+
+// Header is the request header carrying the idempotency key
+const Header = "Idempotency-Key"
+
+// ReplayedHeader marks responses replayed from the store
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLength bounds accepted idempotency keys
+const maxKeyLength = 255
+
+// Config holds idempotency configuration
+type Config struct {
+	// TTL is how long completed responses are replayed
+	TTL time.Duration
+	// LockTTL bounds how long an in-flight request holds its key
+	LockTTL time.Duration
+	// MaxBodyBytes is the largest request body fingerprinted and response body stored
+	MaxBodyBytes int64
+	// Methods are the request methods deduplicated; defaults to POST and PATCH
+	Methods []string
+	// Principal identifies the caller so keys from different callers never collide;
+	// defaults to a hash of the Authorization header
+	Principal func(r *http.Request) string
+}
+
+// Guard is middleware replaying the stored response of a repeated
+// Idempotency-Key instead of running the request again
+type Guard struct {
+	store Store
+	cfg   Config
+}
+
+// New creates a guard over store
+func New(store Store, cfg Config) *Guard {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = time.Minute
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 1 << 20
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.Principal == nil {
+		cfg.Principal = authorizationPrincipal
+	}
+	return &Guard{store: store, cfg: cfg}
+}
+
+// Middleware deduplicates requests carrying an Idempotency-Key
+// The first request runs and its response is stored; retries with the same key
+// and body receive the stored response, a retry while the first is still running
+// receives 409 and reuse of the key with a different request receives 422
+// Server errors release the key so the request can be retried
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" || !slices.Contains(g.cfg.Methods, r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			httperror.Write(w, r, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.cfg.MaxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				httperror.Write(w, r, http.StatusRequestEntityTooLarge, "request body is too large")
+				return
+			}
+			httperror.Write(w, r, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		storeKey := g.cfg.Principal(r) + "\x00" + key
+		fingerprint := fingerprint(r, body)
+
+		record, created, err := g.store.Begin(ctx, storeKey, fingerprint, g.cfg.LockTTL)
+		if err != nil {
+			// Failing open would risk duplicates; the client can retry
+			httperror.Write(w, r, http.StatusServiceUnavailable, "idempotency store unavailable")
+			return
+		}
+		if !created {
+			switch {
+			case record.Fingerprint != fingerprint:
+				httperror.Write(w, r, http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request")
+			case record.Response == nil:
+				httperror.Write(w, r, http.StatusConflict, "a request with this Idempotency-Key is in progress")
+			default:
+				replay(w, record.Response)
+			}
+			return
+		}
+
+		capture := httpcapture.NewCapture(w, g.cfg.MaxBodyBytes)
+		completed := false
+		defer func() {
+			if !completed {
+				// The handler failed or panicked; free the key for a retry.
+				// The request context may be canceled, so release detached from it
+				_ = g.store.Release(context.WithoutCancel(ctx), storeKey)
+			}
+		}()
+
+		next.ServeHTTP(capture, r)
+
+		if capture.Status() >= http.StatusInternalServerError || capture.Overflowed() || capture.Streamed() {
+			return
+		}
+		response := &Response{
+			Status: capture.Status(),
+			Header: capture.Header().Clone(),
+			Body:   capture.Body(),
+		}
+		if err := g.store.Complete(context.WithoutCancel(ctx), storeKey, response, g.cfg.TTL); err == nil {
+			completed = true
+		}
+	})
+}
+
+// replay writes a stored response
+// Headers already set by outer middleware, such as request and trace IDs, are kept
+func replay(w http.ResponseWriter, response *Response) {
+	header := w.Header()
+	for key, values := range response.Header {
+		if _, ok := header[key]; !ok {
+			header[key] = slices.Clone(values)
+		}
+	}
+	header.Set(ReplayedHeader, "true")
+	w.WriteHeader(response.Status)
+	_, _ = w.Write(response.Body)
+}
+
+// fingerprint identifies a request by method, target and body
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\x00"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// authorizationPrincipal identifies callers by a hash of their credentials
+func authorizationPrincipal(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(auth))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,179 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingHandler creates a resource per call and reports its number
+func countingHandler(calls *atomic.Int32, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = fmt.Fprintf(w, `{"id":%d,"body":%q}`, n, body)
+	})
+}
+
+func post(h http.Handler, key, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/examples", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGuard_ReplaysCompletedResponse(t *testing.T) {
+	var calls atomic.Int32
+	h := New(NewMemory(), Config{}).Middleware(countingHandler(&calls, http.StatusCreated))
+
+	first := post(h, "key-1", `{"name":"a"}`, nil)
+	second := post(h, "key-1", `{"name":"a"}`, nil)
+
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 handler call, got %d", calls.Load())
+	}
+	if second.Code != http.StatusCreated {
+		t.Errorf("expected replayed status 201, got %d", second.Code)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected replayed body %q, got %q", first.Body.String(), second.Body.String())
+	}
+	if second.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("expected %s header on the replay", ReplayedHeader)
+	}
+	if first.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("expected no %s header on the original response", ReplayedHeader)
+	}
+}
+
+func TestGuard_KeyReuseWithDifferentBody(t *testing.T) {
+	var calls atomic.Int32
+	h := New(NewMemory(), Config{}).Middleware(countingHandler(&calls, http.StatusCreated))
+
+	_ = post(h, "key-1", `{"name":"a"}`, nil)
+	rec := post(h, "key-1", `{"name":"b"}`, nil)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", rec.Code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 handler call, got %d", calls.Load())
+	}
+}
+
+func TestGuard_ConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := New(NewMemory(), Config{}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(h, "key-1", `{}`, nil) }()
+	<-started
+
+	if rec := post(h, "key-1", `{}`, nil); rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 while in flight, got %d", rec.Code)
+	}
+
+	close(release)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Errorf("expected the first request to complete with 201, got %d", rec.Code)
+	}
+}
+
+func TestGuard_ServerErrorsReleaseTheKey(t *testing.T) {
+	var calls atomic.Int32
+	h := New(NewMemory(), Config{}).Middleware(countingHandler(&calls, http.StatusInternalServerError))
+
+	_ = post(h, "key-1", `{}`, nil)
+	_ = post(h, "key-1", `{}`, nil)
+
+	if calls.Load() != 2 {
+		t.Errorf("expected failed requests to be retried, got %d calls", calls.Load())
+	}
+}
+
+func TestGuard_KeysAreScopedToPrincipal(t *testing.T) {
+	var calls atomic.Int32
+	h := New(NewMemory(), Config{}).Middleware(countingHandler(&calls, http.StatusCreated))
+
+	_ = post(h, "key-1", `{}`, map[string]string{"Authorization": "Bearer alice"})
+	rec := post(h, "key-1", `{}`, map[string]string{"Authorization": "Bearer bob"})
+
+	if calls.Load() != 2 {
+		t.Errorf("expected each principal to run its own request, got %d calls", calls.Load())
+	}
+	if rec.Header().Get(ReplayedHeader) != "" {
+		t.Error("expected another principal's response not to be replayed")
+	}
+}
+
+func TestGuard_PassesThrough(t *testing.T) {
+	var calls atomic.Int32
+	h := New(NewMemory(), Config{}).Middleware(countingHandler(&calls, http.StatusOK))
+
+	// Requests without a key and safe methods are not deduplicated
+	_ = post(h, "", `{}`, nil)
+	_ = post(h, "", `{}`, nil)
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/api/examples/1", http.NoBody)
+		req.Header.Set(Header, "key-1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if calls.Load() != 4 {
+		t.Errorf("expected 4 handler calls, got %d", calls.Load())
+	}
+}
+
+func TestMemory_Expiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	if _, created, _ := m.Begin(ctx, "key", "fp", time.Minute); !created {
+		t.Fatal("expected the record to be created")
+	}
+	if _, created, _ := m.Begin(ctx, "key", "fp", time.Minute); created {
+		t.Error("expected the in-flight record to be kept")
+	}
+
+	// An abandoned in-flight record frees the key once its lock expires
+	now = now.Add(time.Minute)
+	if _, created, _ := m.Begin(ctx, "key", "fp", time.Minute); !created {
+		t.Error("expected an expired record to be replaced")
+	}
+
+	_ = m.Complete(ctx, "key", &Response{Status: http.StatusCreated}, time.Hour)
+	now = now.Add(30 * time.Minute)
+	record, created, _ := m.Begin(ctx, "key", "fp", time.Minute)
+	if created || record.Response == nil {
+		t.Error("expected the completed record to be kept for its TTL")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, created, _ := m.Begin(ctx, "other", "fp", time.Minute); !created {
+		t.Fatal("expected a new record to be created")
+	}
+	if m.Len() != 1 {
+		t.Errorf("expected expired records to be swept, got %d", m.Len())
+	}
+}
@@ -0,0 +1,124 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the stored state of an idempotency key
+type Record struct {
+	// Fingerprint identifies the request first sent with the key
+	Fingerprint string `json:"fingerprint"`
+	// Response is nil while the first request is in flight
+	Response *Response `json:"response,omitempty"`
+}
+
+// Response is a stored response replayed to retries
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Store holds idempotency records
+// Shared stores such as Redis (SET NX) or a database table with a unique key
+// implement it so retries reaching another instance are also deduplicated
+type Store interface {
+	// Begin atomically creates an in-flight record for key unless one exists,
+	// returning the existing record and false in that case; the in-flight
+	// record expires after ttl so a crashed request does not hold the key forever
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error)
+	// Complete stores the response for key, keeping it for ttl
+	Complete(ctx context.Context, key string, response *Response, ttl time.Duration) error
+	// Release removes key so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// Memory is an in-process store with per-record expiry
+type Memory struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	now     func() time.Time
+	// nextSweep is when expired records are next removed
+	nextSweep time.Time
+}
+
+type memoryRecord struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// sweepInterval is how often Memory removes expired records
+const sweepInterval = time.Minute
+
+// NewMemory creates an in-process store
+func NewMemory() *Memory {
+	return &Memory{
+		records: make(map[string]memoryRecord),
+		now:     time.Now,
+	}
+}
+
+// Begin creates an in-flight record unless an unexpired one exists
+func (m *Memory) Begin(_ context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	if existing, ok := m.records[key]; ok && now.Before(existing.expiresAt) {
+		record := existing.record
+		return &record, false, nil
+	}
+
+	record := Record{Fingerprint: fingerprint}
+	m.records[key] = memoryRecord{record: record, expiresAt: now.Add(ttl)}
+	return &record, true, nil
+}
+
+// Complete stores the response of an in-flight record
+func (m *Memory) Complete(_ context.Context, key string, response *Response, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.records[key]
+	if !ok {
+		return nil
+	}
+	existing.record.Response = response
+	existing.expiresAt = m.now().Add(ttl)
+	m.records[key] = existing
+	return nil
+}
+
+// Release removes a record
+func (m *Memory) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
+
+// Len returns the number of stored records, including expired ones not yet removed
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.records)
+}
+
+// sweep removes expired records at most once per sweepInterval; the caller holds m.mu
+func (m *Memory) sweep(now time.Time) {
+	if now.Before(m.nextSweep) {
+		return
+	}
+	m.nextSweep = now.Add(sweepInterval)
+	for key, record := range m.records {
+		if !now.Before(record.expiresAt) {
+			delete(m.records, key)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package jobs runs background work from a queue with a bounded worker pool,
+// retries with exponential backoff and dead-letter storage
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// ErrNoJob is returned by Queue.Claim when no job is due
+var ErrNoJob = errors.New("no job due")
+
+// ErrLeaseLost is returned by Complete, Retry and Fail when the job was claimed
+// again after the caller's lease expired, or is gone
+var ErrLeaseLost = errors.New("job lease lost")
+
+// Job is a unit of background work
+type Job struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	// Metadata carries the trace context and request ID of the enqueuing request
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Attempts counts claims, including the one in progress
+	Attempts int `json:"attempts"`
+	// MaxAttempts is the number of attempts before the job is dead-lettered; 0 uses the worker default
+	MaxAttempts int       `json:"max_attempts"`
+	RunAt       time.Time `json:"run_at"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Lease identifies the claim in progress; it changes with every claim
+	Lease string `json:"-"`
+}
+
+// Queue stores jobs until workers claim them
+// Claimed jobs are leased; a job whose lease expires without Complete, Retry
+// or Fail is claimed again, so delivery is at least once and handlers should be idempotent
+// Complete, Retry and Fail take the lease from Claim and return ErrLeaseLost
+// when the job has since been claimed by another worker
+type Queue interface {
+	// Enqueue stores a job to run at job.RunAt
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim leases the due job with the earliest RunAt, increments its attempts
+	// and sets a new Lease, returning ErrNoJob when none is due
+	Claim(ctx context.Context, lease time.Duration) (*Job, error)
+	// Complete removes a finished job
+	Complete(ctx context.Context, id, lease string) error
+	// Retry releases a job to run again at runAt
+	Retry(ctx context.Context, id, lease string, runAt time.Time, lastErr string) error
+	// Fail moves a job to dead-letter storage
+	Fail(ctx context.Context, id, lease string, lastErr string) error
+	// DeadLetters returns up to limit dead-lettered jobs, most recent first
+	DeadLetters(ctx context.Context, limit int) ([]Job, error)
+}
+
+// Option configures an enqueued job
+type Option func(*Job)
+
+// Delay runs the job after d
+func Delay(d time.Duration) Option {
+	return func(j *Job) { j.RunAt = j.RunAt.Add(d) }
+}
+
+// At runs the job at t
+func At(t time.Time) Option {
+	return func(j *Job) { j.RunAt = t }
+}
+
+// MaxAttempts overrides the worker's default number of attempts
+func MaxAttempts(n int) Option {
+	return func(j *Job) { j.MaxAttempts = n }
+}
+
+// Enqueue adds a job of jobType with a JSON-encoded payload to q
+// The trace context and request ID of ctx are recorded so the run can be correlated
+func Enqueue(ctx context.Context, q Queue, jobType string, payload any, opts ...Option) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	job := &Job{
+		ID:        rand.Text(),
+		Type:      jobType,
+		Payload:   data,
+		Metadata:  map[string]string{},
+		RunAt:     now,
+		CreatedAt: now,
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(job.Metadata))
+	if id := requestid.FromContext(ctx); id != "" {
+		job.Metadata[metadataRequestID] = id
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := q.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// metadataRequestID is the metadata key holding the enqueuing request's ID
+const metadataRequestID = "request_id"
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is dead-lettered without further attempts
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped by Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
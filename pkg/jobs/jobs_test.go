@@ -0,0 +1,299 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestWorkers(q Queue, cfg Config) *Workers {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 5 * time.Millisecond
+	}
+	if cfg.BackoffBase == 0 {
+		cfg.BackoffBase = time.Millisecond
+	}
+	return NewWorkers(q, logger, cfg)
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func TestWorkers_RunsTypedHandler(t *testing.T) {
+	q := NewMemory()
+	w := newTestWorkers(q, Config{})
+
+	got := make(chan string, 1)
+	Handle(w, "greet", func(ctx context.Context, payload greeting) error {
+		got <- payload.Name
+		return nil
+	})
+	w.Start()
+	defer func() { _ = w.Shutdown(context.Background()) }()
+
+	if _, err := Enqueue(context.Background(), q, "greet", greeting{Name: "Go"}); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+
+	select {
+	case name := <-got:
+		if name != "Go" {
+			t.Errorf("expected payload Go, got %s", name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not run")
+	}
+	waitFor(t, func() bool { return q.Len() == 0 })
+}
+
+func TestWorkers_RetriesThenDeadLetters(t *testing.T) {
+	q := NewMemory()
+	w := newTestWorkers(q, Config{MaxAttempts: 3})
+
+	var attempts atomic.Int32
+	w.Register("flaky", func(ctx context.Context, job *Job) error {
+		attempts.Add(1)
+		return errors.New("downstream unavailable")
+	})
+	w.Start()
+	defer func() { _ = w.Shutdown(context.Background()) }()
+
+	_, _ = Enqueue(context.Background(), q, "flaky", nil)
+	waitFor(t, func() bool {
+		dead, _ := q.DeadLetters(context.Background(), 0)
+		return len(dead) == 1
+	})
+
+	if attempts.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts.Load())
+	}
+	dead, _ := q.DeadLetters(context.Background(), 0)
+	if dead[0].LastError != "downstream unavailable" {
+		t.Errorf("expected the last error to be kept, got %q", dead[0].LastError)
+	}
+}
+
+func TestWorkers_PermanentErrorsAreNotRetried(t *testing.T) {
+	q := NewMemory()
+	w := newTestWorkers(q, Config{MaxAttempts: 5})
+
+	var attempts atomic.Int32
+	w.Register("invalid", func(ctx context.Context, job *Job) error {
+		attempts.Add(1)
+		return Permanent(errors.New("bad input"))
+	})
+	// Payloads that do not decode are permanent failures too
+	Handle(w, "typed", func(ctx context.Context, payload greeting) error { return nil })
+	w.Start()
+	defer func() { _ = w.Shutdown(context.Background()) }()
+
+	_, _ = Enqueue(context.Background(), q, "invalid", nil)
+	_ = q.Enqueue(context.Background(), &Job{ID: "raw", Type: "typed", Payload: []byte(`"not an object"`)})
+	_, _ = Enqueue(context.Background(), q, "unregistered", nil)
+
+	waitFor(t, func() bool {
+		dead, _ := q.DeadLetters(context.Background(), 0)
+		return len(dead) == 3
+	})
+	if attempts.Load() != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts.Load())
+	}
+}
+
+func TestMemory_DeadLettersAreBounded(t *testing.T) {
+	q := NewMemory()
+	q.maxDead = 3
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		_ = q.Enqueue(ctx, &Job{ID: id, Type: "failing"})
+		job, _ := q.Claim(ctx, time.Minute)
+		_ = q.Fail(ctx, job.ID, job.Lease, "boom")
+	}
+
+	dead, _ := q.DeadLetters(ctx, 0)
+	var ids []string
+	for _, job := range dead {
+		ids = append(ids, job.ID)
+	}
+	if !slices.Equal(ids, []string{"e", "d", "c"}) {
+		t.Errorf("expected the 3 most recent dead letters, got %v", ids)
+	}
+	if dead, _ := q.DeadLetters(ctx, 2); len(dead) != 2 || dead[0].ID != "e" {
+		t.Errorf("expected limit to keep the most recent, got %v", dead)
+	}
+}
+
+func TestMemory_ExpiredLeaseIsLost(t *testing.T) {
+	q := NewMemory()
+	now := time.Now()
+	q.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = q.Enqueue(ctx, &Job{ID: "slow", Type: "report", RunAt: now})
+	first, _ := q.Claim(ctx, time.Minute)
+
+	// The first run outlives its lease and another worker claims the job
+	now = now.Add(2 * time.Minute)
+	second, err := q.Claim(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("expected the expired job to be claimed again, got %v", err)
+	}
+
+	if err := q.Complete(ctx, first.ID, first.Lease); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost for the stale claim, got %v", err)
+	}
+	if err := q.Retry(ctx, first.ID, first.Lease, now, "late"); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost retrying the stale claim, got %v", err)
+	}
+	if q.Len() != 1 {
+		t.Fatalf("expected the job to stay queued, got %d jobs", q.Len())
+	}
+	if err := q.Complete(ctx, second.ID, second.Lease); err != nil {
+		t.Errorf("expected the current claim to complete, got %v", err)
+	}
+	if err := q.Fail(ctx, second.ID, second.Lease, "gone"); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost for a completed job, got %v", err)
+	}
+}
+
+func TestWorkers_DelayedJobs(t *testing.T) {
+	q := NewMemory()
+	w := newTestWorkers(q, Config{})
+
+	ran := make(chan time.Time, 1)
+	w.Register("later", func(ctx context.Context, job *Job) error {
+		ran <- time.Now()
+		return nil
+	})
+	w.Start()
+	defer func() { _ = w.Shutdown(context.Background()) }()
+
+	enqueued := time.Now()
+	_, _ = Enqueue(context.Background(), q, "later", nil, Delay(50*time.Millisecond))
+
+	select {
+	case at := <-ran:
+		if at.Sub(enqueued) < 50*time.Millisecond {
+			t.Errorf("expected the job to wait for its delay, ran after %s", at.Sub(enqueued))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("delayed job was not run")
+	}
+}
+
+func TestWorkers_ConcurrencyLimit(t *testing.T) {
+	q := NewMemory()
+	w := newTestWorkers(q, Config{Concurrency: 2})
+
+	var running, peak, done atomic.Int32
+	w.Register("slow", func(ctx context.Context, job *Job) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		done.Add(1)
+		return nil
+	})
+
+	for range 6 {
+		_, _ = Enqueue(context.Background(), q, "slow", nil)
+	}
+	w.Start()
+	defer func() { _ = w.Shutdown(context.Background()) }()
+
+	waitFor(t, func() bool { return done.Load() == 6 })
+	if peak.Load() != 2 {
+		t.Errorf("expected at most 2 concurrent jobs, got %d", peak.Load())
+	}
+}
+
+func TestWorkers_ShutdownDrainsRunningJobs(t *testing.T) {
+	q := NewMemory()
+	w := newTestWorkers(q, Config{})
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	w.Register("slow", func(ctx context.Context, job *Job) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+	w.Start()
+
+	_, _ = Enqueue(context.Background(), q, "slow", nil)
+	<-started
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected a clean drain, got %v", err)
+	}
+	if !finished.Load() {
+		t.Error("expected the running job to finish before Shutdown returned")
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected the job to be completed, got %d queued", q.Len())
+	}
+}
+
+func TestWorkers_ShutdownTimeoutCancelsJobs(t *testing.T) {
+	q := NewMemory()
+	w := newTestWorkers(q, Config{MaxAttempts: 5})
+
+	started := make(chan struct{})
+	w.Register("stuck", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	w.Start()
+
+	_, _ = Enqueue(context.Background(), q, "stuck", nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a drain timeout, got %v", err)
+	}
+
+	// The canceled job is kept for a later attempt
+	if q.Len() != 1 {
+		t.Errorf("expected the canceled job to be retried later, got %d queued", q.Len())
+	}
+}
+
+func TestWorkers_Backoff(t *testing.T) {
+	w := newTestWorkers(NewMemory(), Config{BackoffBase: time.Second, BackoffMax: 10 * time.Second})
+
+	for attempts, limit := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: 10 * time.Second} {
+		d := w.backoff(attempts)
+		if d < limit/2 || d > limit {
+			t.Errorf("attempt %d: expected backoff in [%s, %s], got %s", attempts, limit/2, limit, d)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultMaxDeadLetters bounds the dead letters kept by a Memory queue
+const DefaultMaxDeadLetters = 1000
+
+// Memory is an in-process queue; jobs are lost when the process exits
+type Memory struct {
+	mu   sync.Mutex
+	jobs map[string]*memoryJob
+	// dead is a ring buffer of the most recent dead letters
+	dead     []Job
+	deadHead int
+	maxDead  int
+	now      func() time.Time
+}
+
+type memoryJob struct {
+	job         Job
+	lockedUntil time.Time
+}
+
+// held reports whether lease is the job's current claim
+func (j *memoryJob) held(lease string) bool {
+	return j.job.Lease != "" && j.job.Lease == lease
+}
+
+// NewMemory creates an in-process queue
+func NewMemory() *Memory {
+	return &Memory{
+		jobs:    make(map[string]*memoryJob),
+		maxDead: DefaultMaxDeadLetters,
+		now:     time.Now,
+	}
+}
+
+// Enqueue stores a job
+func (m *Memory) Enqueue(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs[job.ID] = &memoryJob{job: *job}
+	return nil
+}
+
+// Claim leases the earliest due job
+func (m *Memory) Claim(_ context.Context, lease time.Duration) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	var next *memoryJob
+	for _, j := range m.jobs {
+		if j.job.RunAt.After(now) || j.lockedUntil.After(now) {
+			continue
+		}
+		if next == nil || j.job.RunAt.Before(next.job.RunAt) {
+			next = j
+		}
+	}
+	if next == nil {
+		return nil, ErrNoJob
+	}
+
+	next.lockedUntil = now.Add(lease)
+	next.job.Attempts++
+	next.job.Lease = rand.Text()
+	job := next.job
+	return &job, nil
+}
+
+// Complete removes a job
+func (m *Memory) Complete(_ context.Context, id, lease string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok || !j.held(lease) {
+		return ErrLeaseLost
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+// Retry releases a job to run at runAt
+func (m *Memory) Retry(_ context.Context, id, lease string, runAt time.Time, lastErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok || !j.held(lease) {
+		return ErrLeaseLost
+	}
+	j.job.RunAt = runAt
+	j.job.LastError = lastErr
+	j.job.Lease = ""
+	j.lockedUntil = time.Time{}
+	return nil
+}
+
+// Fail moves a job to the dead letters, dropping the oldest once DefaultMaxDeadLetters are kept
+func (m *Memory) Fail(_ context.Context, id, lease string, lastErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok || !j.held(lease) {
+		return ErrLeaseLost
+	}
+	j.job.LastError = lastErr
+	j.job.Lease = ""
+	if len(m.dead) < m.maxDead {
+		m.dead = append(m.dead, j.job)
+	} else {
+		m.dead[m.deadHead] = j.job
+		m.deadHead = (m.deadHead + 1) % m.maxDead
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+// DeadLetters returns the most recent dead-lettered jobs
+func (m *Memory) DeadLetters(_ context.Context, limit int) ([]Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dead := slices.Concat(m.dead[m.deadHead:], m.dead[:m.deadHead])
+	slices.Reverse(dead)
+	if limit > 0 && len(dead) > limit {
+		dead = dead[:limit]
+	}
+	return dead, nil
+}
+
+// Len returns the number of pending and running jobs
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.jobs)
+}
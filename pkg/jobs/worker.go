@@ -0,0 +1,316 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ahxar/go-backend-service/pkg/logger"
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// instrumentationName identifies the spans and instruments created by this package
+const instrumentationName = "github.com/ahxar/go-backend-service/pkg/jobs"
+
+// Run results recorded in the jobs.runs metric
+const (
+	ResultCompleted = "completed"
+	ResultRetried   = "retried"
+	ResultFailed    = "failed"
+)
+
+// HandlerFunc processes a job; returning an error retries it unless the error is Permanent
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Config holds worker pool configuration
+type Config struct {
+	// Concurrency is the number of jobs run at once
+	Concurrency int
+	// PollInterval is how long an idle worker waits before checking the queue again
+	PollInterval time.Duration
+	// Timeout bounds each run; the job's lease is Timeout plus a margin
+	Timeout time.Duration
+	// MaxAttempts applies to jobs enqueued without their own limit
+	MaxAttempts int
+	// BackoffBase is the delay before the first retry; each further retry doubles it
+	BackoffBase time.Duration
+	// BackoffMax caps the retry delay
+	BackoffMax time.Duration
+}
+
+// Workers claims jobs from a queue and runs the handler registered for their type
+type Workers struct {
+	queue    Queue
+	logger   *slog.Logger
+	cfg      Config
+	handlers map[string]HandlerFunc
+	tracer   trace.Tracer
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+
+	// stop ends claiming; ctx is canceled to abort running jobs when a drain times out
+	stop   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewWorkers creates a worker pool over queue
+func NewWorkers(queue Queue, logger *slog.Logger, cfg Config) *Workers {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = time.Second
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = 5 * time.Minute
+	}
+
+	meter := otel.Meter(instrumentationName)
+	runs, err := meter.Int64Counter("jobs.runs",
+		metric.WithDescription("Job runs by type and result"),
+		metric.WithUnit("{run}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	duration, err := meter.Float64Histogram("jobs.run.duration",
+		metric.WithDescription("Duration of job runs"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{
+		queue:    queue,
+		logger:   logger,
+		cfg:      cfg,
+		handlers: make(map[string]HandlerFunc),
+		tracer:   otel.Tracer(instrumentationName),
+		runs:     runs,
+		duration: duration,
+		stop:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Register sets the handler for jobType; it must be called before Start
+func (w *Workers) Register(jobType string, h HandlerFunc) {
+	w.handlers[jobType] = h
+}
+
+// Handle registers a handler receiving the job's payload decoded as T
+// Payloads that cannot be decoded are dead-lettered
+func Handle[T any](w *Workers, jobType string, fn func(ctx context.Context, payload T) error) {
+	w.Register(jobType, func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("failed to decode payload: %w", err))
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// Start launches the workers
+func (w *Workers) Start() {
+	for range w.cfg.Concurrency {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.loop()
+		}()
+	}
+}
+
+// Shutdown stops claiming jobs and waits for running ones to finish
+// When ctx ends first, running jobs are canceled and retried later
+func (w *Workers) Shutdown(ctx context.Context) error {
+	w.once.Do(func() { close(w.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.cancel()
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		<-done
+		return fmt.Errorf("failed to drain jobs: %w", ctx.Err())
+	}
+}
+
+// loop claims and runs jobs until Shutdown
+func (w *Workers) loop() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-timer.C:
+		}
+
+		// Drain due jobs before waiting again
+		for {
+			select {
+			case <-w.stop:
+				return
+			default:
+			}
+
+			job, err := w.queue.Claim(w.ctx, w.cfg.Timeout+w.cfg.Timeout/2)
+			if errors.Is(err, ErrNoJob) {
+				break
+			}
+			if err != nil {
+				w.logger.Error("failed to claim job",
+					slog.String("error", err.Error()),
+				)
+				break
+			}
+			w.run(job)
+		}
+
+		timer.Reset(w.cfg.PollInterval)
+	}
+}
+
+// run executes one job and records the outcome in the queue
+func (w *Workers) run(job *Job) {
+	ctx := otel.GetTextMapPropagator().Extract(w.ctx, propagation.MapCarrier(job.Metadata))
+	ctx, span := w.tracer.Start(ctx, "job "+job.Type,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("job.id", job.ID),
+			attribute.String("job.type", job.Type),
+			attribute.Int("job.attempt", job.Attempts),
+		),
+	)
+	defer span.End()
+
+	attrs := []slog.Attr{slog.String("job_id", job.ID), slog.String("job_type", job.Type)}
+	if id := job.Metadata[metadataRequestID]; id != "" {
+		ctx = requestid.NewContext(ctx, id)
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	ctx = logger.WithAttrs(ctx, attrs...)
+
+	start := time.Now()
+	err := w.call(ctx, job)
+	elapsed := time.Since(start)
+
+	// Queue updates must land even when a drain timeout canceled the run
+	qctx := context.WithoutCancel(ctx)
+	maxAttempts := job.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = w.cfg.MaxAttempts
+	}
+
+	var result string
+	switch {
+	case err == nil:
+		result = ResultCompleted
+		err = w.queue.Complete(qctx, job.ID, job.Lease)
+	case IsPermanent(err) || job.Attempts >= maxAttempts:
+		result = ResultFailed
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		w.logger.ErrorContext(ctx, "job failed",
+			slog.Int("attempts", job.Attempts),
+			slog.String("error", err.Error()),
+		)
+		err = w.queue.Fail(qctx, job.ID, job.Lease, err.Error())
+	default:
+		result = ResultRetried
+		delay := w.backoff(job.Attempts)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		w.logger.WarnContext(ctx, "job attempt failed, retrying",
+			slog.Int("attempts", job.Attempts),
+			slog.Duration("retry_in", delay),
+			slog.String("error", err.Error()),
+		)
+		err = w.queue.Retry(qctx, job.ID, job.Lease, time.Now().UTC().Add(delay), err.Error())
+	}
+	switch {
+	case errors.Is(err, ErrLeaseLost):
+		// The run outlived its lease and another worker owns the job now
+		w.logger.WarnContext(ctx, "job lease lost, leaving the job to its new claim",
+			slog.String("result", result),
+		)
+	case err != nil:
+		w.logger.ErrorContext(ctx, "failed to update job",
+			slog.String("error", err.Error()),
+		)
+	}
+
+	metricAttrs := metric.WithAttributes(
+		attribute.String("job.type", job.Type),
+		attribute.String("job.result", result),
+	)
+	w.runs.Add(qctx, 1, metricAttrs)
+	w.duration.Record(qctx, elapsed.Seconds(), metricAttrs)
+}
+
+// call runs the job's handler with the run timeout, turning panics into errors
+func (w *Workers) call(ctx context.Context, job *Job) (err error) {
+	h, ok := w.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return h(ctx, job)
+}
+
+// backoff returns the delay before the next attempt: exponential in the
+// attempts made, capped at BackoffMax, with jitter over its upper half so
+// retries of jobs that failed together spread out
+func (w *Workers) backoff(attempts int) time.Duration {
+	d := w.cfg.BackoffBase
+	for i := 1; i < attempts && d < w.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	d = min(d, w.cfg.BackoffMax)
+	half := d / 2
+	return half + rand.N(half+1) //nolint:gosec // jitter does not need a secure source
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+type attrsKey struct{}
+
+// WithAttrs returns a context carrying attrs in addition to any already attached
+// Every record logged with the returned context, or one derived from it, includes them
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := AttrsFromContext(ctx)
+	// Clip so appending never writes into a slice shared with the parent context
+	return context.WithValue(ctx, attrsKey{}, append(slices.Clip(existing), attrs...))
+}
+
+// AttrsFromContext returns the attributes attached to the context
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds the attributes attached with WithAttrs to every record
+type contextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler wraps a handler so records include the attributes attached
+// to their context with WithAttrs
+func NewContextHandler(next slog.Handler) slog.Handler {
+	return &contextHandler{next: next}
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := AttrsFromContext(ctx); len(attrs) > 0 {
+		r.AddAttrs(attrs...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewContextHandler(slog.NewTextHandler(&buf, nil)))
+
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "req-1"))
+	ctx = WithAttrs(ctx, slog.String("route", "GET /api/example"))
+
+	log.InfoContext(ctx, "with attributes")
+	if !strings.Contains(buf.String(), `request_id=req-1 route="GET /api/example"`) {
+		t.Errorf("expected context attributes in output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	log.InfoContext(context.Background(), "without attributes")
+	if strings.Contains(buf.String(), "request_id") {
+		t.Errorf("expected no context attributes, got %q", buf.String())
+	}
+}
+
+func TestWithAttrs_DoesNotLeakBetweenSiblings(t *testing.T) {
+	parent := WithAttrs(context.Background(), slog.String("a", "1"), slog.String("b", "2"))
+
+	first := WithAttrs(parent, slog.String("user_id", "first"))
+	second := WithAttrs(parent, slog.String("user_id", "second"))
+
+	if got := AttrsFromContext(parent); len(got) != 2 {
+		t.Errorf("expected parent to keep 2 attributes, got %d", len(got))
+	}
+	if got := AttrsFromContext(first)[2].Value.String(); got != "first" {
+		t.Errorf("expected first sibling user_id first, got %s", got)
+	}
+	if got := AttrsFromContext(second)[2].Value.String(); got != "second" {
+		t.Errorf("expected second sibling user_id second, got %s", got)
+	}
+}
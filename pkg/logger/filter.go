@@ -0,0 +1,254 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedMessages bounds the per-message state kept by the sampling and dedupe handlers
+const maxTrackedMessages = 4096
+
+// correlationKeys are ignored when comparing records, as they differ per request
+var correlationKeys = map[string]bool{
+	"trace_id":   true,
+	"span_id":    true,
+	"request_id": true,
+}
+
+// recordKey identifies a record by level, message and, optionally, its attributes
+func recordKey(r slog.Record, withAttrs bool) string {
+	var b strings.Builder
+	b.WriteString(r.Level.String())
+	b.WriteByte('|')
+	b.WriteString(r.Message)
+	if withAttrs {
+		r.Attrs(func(a slog.Attr) bool {
+			if !correlationKeys[a.Key] {
+				b.WriteByte('|')
+				b.WriteString(a.String())
+			}
+			return true
+		})
+	}
+	return b.String()
+}
+
+// SamplingConfig limits how many records with the same level and message are logged per tick
+type SamplingConfig struct {
+	// Initial records per message are logged each tick
+	Initial int
+	// Thereafter every Nth record is logged; 0 drops the rest of the tick
+	Thereafter int
+	Tick       time.Duration
+}
+
+// samplingState is shared by a sampling handler and the handlers derived from it
+type samplingState struct {
+	mu       sync.Mutex
+	counters map[string]*samplingCounter
+}
+
+type samplingCounter struct {
+	start time.Time
+	count int
+}
+
+// samplingHandler drops repeated records below warn level beyond the configured rate
+type samplingHandler struct {
+	cfg   SamplingConfig
+	state *samplingState
+	next  slog.Handler
+}
+
+// NewSamplingHandler wraps a handler so that, per message and tick, the first
+// Initial records and then every Thereafter-th one are logged
+// Warnings and errors are never sampled
+func NewSamplingHandler(cfg SamplingConfig, next slog.Handler) slog.Handler {
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Second
+	}
+	return &samplingHandler{
+		cfg:   cfg,
+		state: &samplingState{counters: make(map[string]*samplingCounter)},
+		next:  next,
+	}
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn || h.allow(r) {
+		return h.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+// allow counts the record and reports whether it falls within the sampling rate
+func (h *samplingHandler) allow(r slog.Record) bool {
+	key := recordKey(r, false)
+	now := time.Now()
+
+	h.state.mu.Lock()
+	c, ok := h.state.counters[key]
+	if !ok || now.Sub(c.start) >= h.cfg.Tick {
+		if !ok && len(h.state.counters) >= maxTrackedMessages {
+			h.state.counters = make(map[string]*samplingCounter)
+		}
+		c = &samplingCounter{start: now}
+		h.state.counters[key] = c
+	}
+	c.count++
+	n := c.count
+	h.state.mu.Unlock()
+
+	if n <= h.cfg.Initial {
+		return true
+	}
+	return h.cfg.Thereafter > 0 && (n-h.cfg.Initial)%h.cfg.Thereafter == 0
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{cfg: h.cfg, state: h.state, next: h.next.WithAttrs(attrs)}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{cfg: h.cfg, state: h.state, next: h.next.WithGroup(name)}
+}
+
+// routeFilterHandler drops records below warn level logged for excluded routes
+type routeFilterHandler struct {
+	routes map[string]bool
+	next   slog.Handler
+}
+
+// NewRouteFilterHandler wraps a handler so info and debug records logged with a
+// context whose route attribute matches one of routes are dropped
+// Routes match either the full pattern ("GET /health") or its path ("/health")
+func NewRouteFilterHandler(routes []string, next slog.Handler) slog.Handler {
+	set := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		set[route] = true
+	}
+	return &routeFilterHandler{routes: set, next: next}
+}
+
+func (h *routeFilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *routeFilterHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn && h.excluded(ctx) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// excluded reports whether the context's route is excluded
+func (h *routeFilterHandler) excluded(ctx context.Context) bool {
+	for _, a := range AttrsFromContext(ctx) {
+		if a.Key != "route" {
+			continue
+		}
+		route := a.Value.String()
+		if h.routes[route] {
+			return true
+		}
+		// Strip the method from patterns such as "GET /health"
+		if _, path, ok := strings.Cut(route, " "); ok && h.routes[path] {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *routeFilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &routeFilterHandler{routes: h.routes, next: h.next.WithAttrs(attrs)}
+}
+
+func (h *routeFilterHandler) WithGroup(name string) slog.Handler {
+	return &routeFilterHandler{routes: h.routes, next: h.next.WithGroup(name)}
+}
+
+// dedupeState is shared by a dedupe handler and the handlers derived from it
+type dedupeState struct {
+	mu      sync.Mutex
+	entries map[string]*dedupeEntry
+}
+
+type dedupeEntry struct {
+	first      time.Time
+	suppressed int
+}
+
+// dedupeHandler suppresses identical records within a time window
+type dedupeHandler struct {
+	window time.Duration
+	state  *dedupeState
+	next   slog.Handler
+}
+
+// NewDedupeHandler wraps a handler so a record identical to one logged less
+// than window ago is suppressed
+// The next record logged after the window carries a repeated attribute with
+// the number of records suppressed in between
+func NewDedupeHandler(window time.Duration, next slog.Handler) slog.Handler {
+	return &dedupeHandler{
+		window: window,
+		state:  &dedupeState{entries: make(map[string]*dedupeEntry)},
+		next:   next,
+	}
+}
+
+func (h *dedupeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *dedupeHandler) Handle(ctx context.Context, r slog.Record) error {
+	key := recordKey(r, true)
+	now := time.Now()
+
+	h.state.mu.Lock()
+	e, ok := h.state.entries[key]
+	if ok && now.Sub(e.first) < h.window {
+		e.suppressed++
+		h.state.mu.Unlock()
+		return nil
+	}
+
+	var suppressed int
+	if ok {
+		suppressed = e.suppressed
+	} else if len(h.state.entries) >= maxTrackedMessages {
+		h.prune(now)
+	}
+	h.state.entries[key] = &dedupeEntry{first: now}
+	h.state.mu.Unlock()
+
+	if suppressed > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int("repeated", suppressed))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// prune drops entries whose window has passed; the state lock must be held
+func (h *dedupeHandler) prune(now time.Time) {
+	for key, e := range h.state.entries {
+		if now.Sub(e.first) >= h.window {
+			delete(h.state.entries, key)
+		}
+	}
+}
+
+func (h *dedupeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dedupeHandler{window: h.window, state: h.state, next: h.next.WithAttrs(attrs)}
+}
+
+func (h *dedupeHandler) WithGroup(name string) slog.Handler {
+	return &dedupeHandler{window: h.window, state: h.state, next: h.next.WithGroup(name)}
+}
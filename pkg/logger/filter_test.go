@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewSamplingHandler(SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Hour}, slog.NewTextHandler(&buf, nil)))
+
+	for range 8 {
+		log.Info("http request")
+	}
+	log.Error("failed")
+	log.Error("failed")
+
+	// Records 1, 2, then every 3rd after the initial ones: 5 and 8
+	if got := strings.Count(buf.String(), "http request"); got != 4 {
+		t.Errorf("expected 4 sampled info records, got %d", got)
+	}
+	if got := strings.Count(buf.String(), "failed"); got != 2 {
+		t.Errorf("expected errors to never be sampled, got %d", got)
+	}
+}
+
+func TestRouteFilterHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewRouteFilterHandler([]string{"/health"}, slog.NewTextHandler(&buf, nil)))
+
+	health := WithAttrs(context.Background(), slog.String("route", "GET /health"))
+	example := WithAttrs(context.Background(), slog.String("route", "GET /api/example"))
+
+	log.InfoContext(health, "health probe")
+	log.ErrorContext(health, "health check failed")
+	log.InfoContext(example, "example request")
+
+	if strings.Contains(buf.String(), "health probe") {
+		t.Errorf("expected info record for excluded route to be dropped, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "health check failed") {
+		t.Errorf("expected error record for excluded route to be kept, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "example request") {
+		t.Errorf("expected record for other routes to be kept, got %q", buf.String())
+	}
+}
+
+func TestDedupeHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewDedupeHandler(50*time.Millisecond, slog.NewTextHandler(&buf, nil))
+	log := slog.New(handler)
+
+	for i := range 3 {
+		log.Error("connection refused", slog.String("host", "db"), slog.Int("request_id", i))
+	}
+	log.Error("connection refused", slog.String("host", "cache"))
+
+	if got := strings.Count(buf.String(), "host=db"); got != 1 {
+		t.Errorf("expected duplicates to be suppressed, got %d records", got)
+	}
+	if !strings.Contains(buf.String(), "host=cache") {
+		t.Errorf("expected records with different attributes to be kept, got %q", buf.String())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	buf.Reset()
+	log.Error("connection refused", slog.String("host", "db"))
+
+	if !strings.Contains(buf.String(), "repeated=2") {
+		t.Errorf("expected suppressed count on next record, got %q", buf.String())
+	}
+}
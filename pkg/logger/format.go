@@ -0,0 +1,233 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Log formats
+const (
+	FormatJSON    = "json"
+	FormatText    = "text"
+	FormatLogfmt  = "logfmt"
+	FormatConsole = "console"
+)
+
+// newFormatHandler creates a handler writing records to w in the given format
+// omitTime drops the timestamp for destinations that add their own, such as syslog
+func newFormatHandler(format string, w io.Writer, level slog.Leveler, omitTime bool) (slog.Handler, error) {
+	var replace func([]string, slog.Attr) slog.Attr
+	if omitTime {
+		replace = func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		}
+	}
+
+	switch format {
+	case FormatJSON:
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: replace}), nil
+	case FormatText, "":
+		return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: replace}), nil
+	case FormatLogfmt:
+		return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: logfmtReplacer(replace)}), nil
+	case FormatConsole:
+		return newConsoleHandler(w, level, omitTime), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+// logfmtReplacer uses the conventional logfmt keys: ts in RFC 3339 and lowercase level
+func logfmtReplacer(next func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if next != nil {
+			if a = next(groups, a); a.Key == "" {
+				return a
+			}
+		}
+		if len(groups) > 0 {
+			return a
+		}
+		switch a.Key {
+		case slog.TimeKey:
+			return slog.String("ts", a.Value.Time().Format(time.RFC3339Nano))
+		case slog.LevelKey:
+			return slog.String(slog.LevelKey, strings.ToLower(a.Value.String()))
+		}
+		return a
+	}
+}
+
+// ANSI colors for console levels
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+// consoleHandler pretty-prints records for humans: time, short level, message, attributes
+type consoleHandler struct {
+	mu       *sync.Mutex
+	w        io.Writer
+	level    slog.Leveler
+	color    bool
+	omitTime bool
+	// attrs holds attributes preformatted by WithAttrs
+	attrs string
+	// group is the dotted prefix of the current group
+	group string
+}
+
+// newConsoleHandler colors output when w is a terminal
+func newConsoleHandler(w io.Writer, level slog.Leveler, omitTime bool) *consoleHandler {
+	return &consoleHandler{
+		mu:       &sync.Mutex{},
+		w:        w,
+		level:    level,
+		color:    isTerminal(w),
+		omitTime: omitTime,
+	}
+}
+
+// isTerminal reports whether w is a character device such as a TTY
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+
+	if !h.omitTime && !r.Time.IsZero() {
+		h.paint(&b, colorGray, r.Time.Format("15:04:05.000"))
+		b.WriteByte(' ')
+	}
+
+	label, color := consoleLevel(r.Level)
+	h.paint(&b, color, label)
+	b.WriteByte(' ')
+	b.WriteString(r.Message)
+
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(&b, h.group, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// paint writes s wrapped in color when coloring is enabled
+func (h *consoleHandler) paint(b *strings.Builder, color, s string) {
+	if h.color {
+		b.WriteString(color)
+		b.WriteString(s)
+		b.WriteString(colorReset)
+		return
+	}
+	b.WriteString(s)
+}
+
+// appendAttr writes " key=value", flattening groups into dotted keys
+func (h *consoleHandler) appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, attr := range a.Value.Group() {
+			h.appendAttr(b, prefix, attr)
+		}
+		return
+	}
+
+	b.WriteByte(' ')
+	h.paint(b, colorGray, prefix+a.Key+"=")
+	b.WriteString(consoleValue(a.Value))
+}
+
+// consoleValue formats a value, quoting strings that contain spaces or quotes
+func consoleValue(v slog.Value) string {
+	var s string
+	switch v.Kind() {
+	case slog.KindTime:
+		s = v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			s = err.Error()
+		} else {
+			s = fmt.Sprint(v.Any())
+		}
+	default:
+		s = v.String()
+	}
+
+	if s == "" || strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == '"' || r == '=' }) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// consoleLevel returns the short label and color of a level
+func consoleLevel(level slog.Level) (string, string) {
+	switch {
+	case level >= slog.LevelError:
+		return "ERR", colorRed
+	case level >= slog.LevelWarn:
+		return "WRN", colorYellow
+	case level >= slog.LevelInfo:
+		return "INF", colorGreen
+	default:
+		return "DBG", colorGray
+	}
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		h.appendAttr(&b, h.group, a)
+	}
+
+	clone := *h
+	clone.attrs = b.String()
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.group += name + "."
+	return &clone
+}
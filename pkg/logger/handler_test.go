@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewTraceHandler(slog.NewTextHandler(&buf, nil)))
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	log.InfoContext(ctx, "with span")
+	if !strings.Contains(buf.String(), "trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7") {
+		t.Errorf("expected trace and span IDs in output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	log.InfoContext(context.Background(), "without span")
+	if strings.Contains(buf.String(), "trace_id") {
+		t.Errorf("expected no trace ID without a span, got %q", buf.String())
+	}
+}
+
+func TestFanoutHandler(t *testing.T) {
+	var all, errorsOnly bytes.Buffer
+	log := slog.New(NewFanoutHandler(
+		slog.NewTextHandler(&all, nil),
+		NewLevelHandler(slog.LevelError, slog.NewTextHandler(&errorsOnly, nil)),
+	))
+
+	log.Info("info message")
+	log.Error("error message")
+
+	if !strings.Contains(all.String(), "info message") || !strings.Contains(all.String(), "error message") {
+		t.Errorf("expected both records in first handler, got %q", all.String())
+	}
+
+	if strings.Contains(errorsOnly.String(), "info message") || !strings.Contains(errorsOnly.String(), "error message") {
+		t.Errorf("expected only the error record in second handler, got %q", errorsOnly.String())
+	}
+}
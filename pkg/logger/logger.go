@@ -1,31 +1,95 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
-	"os"
 	"strings"
+	"time"
 )
 
-// New creates a structured logger based on environment and log level
-func New(environment, logLevel string) *slog.Logger {
-	var handler slog.Handler
+// Config holds logger configuration
+type Config struct {
+	// Level is the default minimum level for sinks and the level of Extra handlers
+	Level string
+	// Format is the default format for sinks: json, text, logfmt or console
+	Format string
+	// Sinks lists the destinations records are written to; empty writes to stdout
+	Sinks []SinkConfig
+	// Sampling limits info and debug records per message; disabled when Initial is 0
+	Sampling SamplingConfig
+	// ExcludeRoutes lists routes whose info and debug records are dropped
+	ExcludeRoutes []string
+	// DedupeWindow suppresses identical records within the window; 0 disables it
+	DedupeWindow time.Duration
+	// RedactKeys lists attribute keys whose values are always redacted
+	RedactKeys []string
+	// Extra handlers receive every record in addition to the sinks, e.g. the OTLP logs bridge
+	Extra []slog.Handler
+}
+
+// New creates a structured logger writing to the configured sinks
+// The returned function closes file and syslog sinks and should be called on shutdown
+func New(cfg Config) (*slog.Logger, func() error, error) {
+	level := ParseLevel(cfg.Level)
+
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Type: SinkStdout}}
+	}
 
-	// Choose handler based on environment
-	if environment == "production" {
-		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: parseLogLevel(logLevel),
-		})
-	} else {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: parseLogLevel(logLevel),
-		})
+	var handlers []slog.Handler
+	var closers []io.Closer
+	closeAll := func() error {
+		var errs []error
+		for _, c := range closers {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, sink := range sinks {
+		h, closer, err := newSink(sink, cfg.Format, level)
+		if err != nil {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("failed to create %s log sink: %w", sink.Type, err)
+		}
+		handlers = append(handlers, h)
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+	for _, extra := range cfg.Extra {
+		handlers = append(handlers, NewLevelHandler(level, extra))
+	}
+
+	handler := handlers[0]
+	if len(handlers) > 1 {
+		handler = NewFanoutHandler(handlers...)
+	}
+
+	// Filters run before redaction so they see the original records and
+	// every output receives the same redacted record
+	handler = NewRedactHandler(cfg.RedactKeys, handler)
+	if cfg.DedupeWindow > 0 {
+		handler = NewDedupeHandler(cfg.DedupeWindow, handler)
+	}
+	if cfg.Sampling.Initial > 0 {
+		handler = NewSamplingHandler(cfg.Sampling, handler)
+	}
+	if len(cfg.ExcludeRoutes) > 0 {
+		handler = NewRouteFilterHandler(cfg.ExcludeRoutes, handler)
 	}
 
-	return slog.New(handler)
+	// Include request-scoped attributes and correlate every record with the active OpenTelemetry span
+	return slog.New(NewContextHandler(NewTraceHandler(handler))), closeAll, nil
 }
 
-// parseLogLevel converts string log level to slog.Level
-func parseLogLevel(level string) slog.Level {
+// ParseLevel converts string log level to slog.Level
+func ParseLevel(level string) slog.Level {
 	switch strings.ToLower(level) {
 	case "debug":
 		return slog.LevelDebug
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces the value of attributes with redacted keys
+const Redacted = "[REDACTED]"
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+// redactHandler masks sensitive attribute values before they are written
+type redactHandler struct {
+	keys map[string]bool
+	next slog.Handler
+}
+
+// NewRedactHandler wraps a handler so attributes whose key is in keys
+// (case-insensitive) are replaced with [REDACTED], and emails, bearer/basic
+// credentials and JWTs inside string values are masked
+func NewRedactHandler(keys []string, next slog.Handler) slog.Handler {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = true
+	}
+	return &redactHandler{keys: set, next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redact(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// redact masks a single attribute, descending into groups
+func (h *redactHandler) redact(a slog.Attr) slog.Attr {
+	if h.keys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		attrs := value.Group()
+		masked := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			masked[i] = h.redact(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(masked...)}
+	case slog.KindString:
+		return slog.String(a.Key, redactString(value.String()))
+	case slog.KindAny:
+		// Errors and other values are logged by their string form
+		if err, ok := value.Any().(error); ok {
+			return slog.String(a.Key, redactString(err.Error()))
+		}
+		// Maps keyed by strings are logged as groups so their entries are redacted too
+		if attrs, ok := mapAttrs(value.Any()); ok {
+			masked := make([]slog.Attr, len(attrs))
+			for i, attr := range attrs {
+				masked[i] = h.redact(attr)
+			}
+			return slog.Attr{Key: a.Key, Value: slog.GroupValue(masked...)}
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
+
+// mapAttrs converts a map with string keys into attributes sorted by key
+func mapAttrs(v any) ([]slog.Attr, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	attrs := make([]slog.Attr, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		attrs = append(attrs, slog.Any(iter.Key().String(), iter.Value().Interface()))
+	}
+	slices.SortFunc(attrs, func(a, b slog.Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return attrs, true
+}
+
+// redactString masks emails and credentials in a free-form value
+func redactString(s string) string {
+	s = emailPattern.ReplaceAllString(s, "[EMAIL]")
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Redacted)
+	s = jwtPattern.ReplaceAllString(s, Redacted)
+	return s
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = h.redact(a)
+	}
+	return &redactHandler{keys: h.keys, next: h.next.WithAttrs(masked)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{keys: h.keys, next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		attr    slog.Attr
+		want    string
+		notWant string
+	}{
+		{name: "configured key", attr: slog.String("Password", "hunter2"), want: "Password=[REDACTED]", notWant: "hunter2"},
+		{name: "email in value", attr: slog.String("msg", "sent to jane@example.com"), want: `"sent to [EMAIL]"`, notWant: "jane@"},
+		{name: "bearer token", attr: slog.String("header", "Bearer abc.def"), want: `"Bearer [REDACTED]"`, notWant: "abc.def"},
+		{name: "jwt", attr: slog.String("raw", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig"), want: "raw=[REDACTED]", notWant: "eyJ"},
+		{name: "error value", attr: slog.Any("error", errors.New("user bob@example.com not found")), want: `"user [EMAIL] not found"`, notWant: "bob@"},
+		{name: "group", attr: slog.Group("user", slog.String("token", "t0k3n")), want: "user.token=[REDACTED]", notWant: "t0k3n"},
+		{name: "map", attr: slog.Any("data", map[string]any{"token": "t0k3n", "owner": "ann@example.com"}), want: "data.token=[REDACTED]", notWant: "t0k3n"},
+		{name: "nested map", attr: slog.Any("data", map[string]map[string]string{"user": {"email": "ann@example.com"}}), want: "data.user.email=[EMAIL]", notWant: "ann@"},
+		{name: "untouched", attr: slog.Int("status", 200), want: "status=200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := slog.New(NewRedactHandler([]string{"password", "token"}, slog.NewTextHandler(&buf, nil)))
+
+			log.Info("message", tt.attr)
+
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("expected %q in output, got %q", tt.want, buf.String())
+			}
+			if tt.notWant != "" && strings.Contains(buf.String(), tt.notWant) {
+				t.Errorf("expected %q to be redacted, got %q", tt.notWant, buf.String())
+			}
+		})
+	}
+}
+
+func TestRedactHandler_WithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewRedactHandler([]string{"api_key"}, slog.NewTextHandler(&buf, nil)))
+
+	log.With(slog.String("api_key", "secret")).Info("message")
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected logger attributes to be redacted, got %q", buf.String())
+	}
+}
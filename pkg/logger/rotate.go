@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is used in rotated file names and sorts chronologically
+const backupTimeFormat = "20060102T150405.000"
+
+// FileConfig holds rotating file sink configuration
+type FileConfig struct {
+	Path string
+	// MaxSizeMB rotates the file once it would grow beyond this size; 0 disables rotation
+	MaxSizeMB int
+	// MaxAge removes rotated files older than this; 0 keeps them regardless of age
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept; 0 keeps all
+	MaxBackups int
+	// Compress gzips rotated files
+	Compress bool
+}
+
+// RotatingFile is an io.WriteCloser that rotates the file by size and prunes old backups
+type RotatingFile struct {
+	cfg FileConfig
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// rotated feeds backups to the worker, which compresses and prunes them one at a time
+	rotated chan string
+	done    chan struct{}
+
+	// pending holds the backups queued for compression, which pruning leaves alone
+	pendingMu sync.Mutex
+	pending   map[string]bool
+}
+
+// OpenRotatingFile opens or creates the log file for appending
+func OpenRotatingFile(cfg FileConfig) (*RotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("file sink requires a path")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	f := &RotatingFile{
+		cfg:     cfg,
+		rotated: make(chan string, 16),
+		done:    make(chan struct{}),
+		pending: make(map[string]bool),
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	go f.work()
+	return f, nil
+}
+
+// open opens the current log file; the lock must be held
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if it would exceed the maximum size
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	maxSize := int64(f.cfg.MaxSizeMB) << 20
+	if maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, moves it aside and starts a new one
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// rotate implements Rotate; the lock must be held
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	ext := filepath.Ext(f.cfg.Path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.cfg.Path, ext), time.Now().UTC().Format(backupTimeFormat), ext)
+	if err := os.Rename(f.cfg.Path, backup); err != nil {
+		// Keep logging to the original file rather than a closed one
+		if openErr := f.open(); openErr != nil {
+			f.file = nil
+			return errors.Join(fmt.Errorf("failed to rotate log file: %w", err), openErr)
+		}
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	// Compress and prune in the background so logging is not blocked
+	if f.cfg.Compress {
+		f.pendingMu.Lock()
+		f.pending[filepath.Base(backup)] = true
+		f.pendingMu.Unlock()
+	}
+	f.rotated <- backup
+
+	return nil
+}
+
+// work compresses and prunes rotated files in order until the file is closed
+// Running both on one goroutine keeps pruning from removing a file while it is compressed
+func (f *RotatingFile) work() {
+	defer close(f.done)
+	for backup := range f.rotated {
+		if f.cfg.Compress {
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to compress rotated log file: %v\n", err)
+			}
+			f.pendingMu.Lock()
+			delete(f.pending, filepath.Base(backup))
+			f.pendingMu.Unlock()
+		}
+		f.prune()
+	}
+}
+
+// prune removes rotated files beyond MaxBackups or older than MaxAge
+func (f *RotatingFile) prune() {
+	if f.cfg.MaxBackups <= 0 && f.cfg.MaxAge <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(f.cfg.Path)
+	ext := filepath.Ext(f.cfg.Path)
+	prefix := strings.TrimSuffix(filepath.Base(f.cfg.Path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+".gz") {
+			backups = append(backups, name)
+		}
+	}
+
+	// Newest first; the timestamp in the name sorts chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	f.pendingMu.Lock()
+	defer f.pendingMu.Unlock()
+
+	cutoff := time.Now().Add(-f.cfg.MaxAge)
+	for i, name := range backups {
+		// Backups still queued for compression count towards MaxBackups but are kept
+		if f.pending[name] {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		remove := f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups
+		if !remove && f.cfg.MaxAge > 0 {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+				remove = true
+			}
+		}
+		if remove {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// Close closes the file and waits for background compression and pruning to finish
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+		close(f.rotated)
+	}
+	f.mu.Unlock()
+
+	<-f.done
+	return err
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path) //nolint:gosec // path is a rotated log file we created
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
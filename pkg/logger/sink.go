@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Sink types
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+)
+
+// SinkConfig describes one log destination
+type SinkConfig struct {
+	// Type is stdout, stderr, file or syslog
+	Type string
+	// Format is json, text, logfmt or console; empty uses Config.Format
+	Format string
+	// Level is the minimum level written to this sink; empty uses Config.Level
+	Level  string
+	File   FileConfig
+	Syslog SyslogConfig
+}
+
+// newSink creates the handler for a sink and the closer releasing its resources
+func newSink(cfg SinkConfig, defaultFormat string, defaultLevel slog.Level) (slog.Handler, io.Closer, error) {
+	format := cfg.Format
+	if format == "" {
+		format = defaultFormat
+	}
+	level := defaultLevel
+	if cfg.Level != "" {
+		level = ParseLevel(cfg.Level)
+	}
+
+	switch cfg.Type {
+	case SinkStdout, "":
+		h, err := newFormatHandler(format, os.Stdout, level, false)
+		return h, nil, err
+	case SinkStderr:
+		h, err := newFormatHandler(format, os.Stderr, level, false)
+		return h, nil, err
+	case SinkFile:
+		file, err := OpenRotatingFile(cfg.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		h, err := newFormatHandler(format, file, level, false)
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+		return h, file, nil
+	case SinkSyslog:
+		// Validate the format before connecting
+		if _, err := newFormatHandler(format, io.Discard, level, true); err != nil {
+			return nil, nil, err
+		}
+		conn, err := dialSyslog(cfg.Syslog)
+		if err != nil {
+			return nil, nil, err
+		}
+		h := newSyslogHandler(conn, func(w io.Writer) slog.Handler {
+			h, _ := newFormatHandler(format, w, level, true)
+			return h
+		})
+		return h, conn, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown log sink type %q", cfg.Type)
+	}
+}
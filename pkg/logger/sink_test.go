@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFormatHandler(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: FormatJSON, want: []string{`"level":"INFO"`, `"msg":"hello"`, `"user":"jane"`}},
+		{format: FormatText, want: []string{"level=INFO", "msg=hello", "user=jane"}},
+		{format: FormatLogfmt, want: []string{"ts=", "level=info", "msg=hello", "user=jane"}},
+		{format: FormatConsole, want: []string{"INF hello", "req.user=jane"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			h, err := newFormatHandler(tt.format, &buf, slog.LevelInfo, false)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			log := slog.New(h)
+			if tt.format == FormatConsole {
+				log = log.WithGroup("req")
+			}
+			log.Info("hello", slog.String("user", "jane"))
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected %q in output, got %q", want, buf.String())
+				}
+			}
+		})
+	}
+
+	if _, err := newFormatHandler("xml", io.Discard, slog.LevelInfo, false); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "service.log")
+
+	f, err := OpenRotatingFile(FileConfig{Path: path, MaxSizeMB: 1, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+
+	line := bytes.Repeat([]byte("x"), 600<<10)
+	for range 4 {
+		if _, err := f.Write(line); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+		// Backups are named by millisecond timestamp
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if entry.Name() != "service.log" {
+			backups = append(backups, entry.Name())
+		}
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups after pruning, got %v", backups)
+	}
+
+	for _, name := range backups {
+		if !strings.HasSuffix(name, ".log.gz") {
+			t.Errorf("expected compressed backup, got %s", name)
+			continue
+		}
+		gzFile, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to open backup: %v", err)
+		}
+		r, err := gzip.NewReader(gzFile)
+		if err != nil {
+			t.Fatalf("invalid gzip backup: %v", err)
+		}
+		data, _ := io.ReadAll(r)
+		_ = gzFile.Close()
+		if len(data) != len(line) {
+			t.Errorf("expected backup with %d bytes, got %d", len(line), len(data))
+		}
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = pc.Close() }()
+
+	h, closer, err := newSink(SinkConfig{
+		Type:   SinkSyslog,
+		Format: FormatLogfmt,
+		Syslog: SyslogConfig{Network: "udp", Address: pc.LocalAddr().String(), Tag: "svc"},
+	}, FormatText, slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	defer func() { _ = closer.Close() }()
+
+	slog.New(h).Warn("disk almost full", slog.Int("percent", 91))
+
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read syslog message: %v", err)
+	}
+	msg := string(buf[:n])
+
+	// user facility (1) * 8 + warning severity (4)
+	if !strings.HasPrefix(msg, "<12>1 ") {
+		t.Errorf("expected warning priority, got %q", msg)
+	}
+	if !strings.Contains(msg, " svc ") || !strings.Contains(msg, `msg="disk almost full" percent=91`) {
+		t.Errorf("unexpected syslog message %q", msg)
+	}
+	if strings.Contains(msg, "ts=") {
+		t.Errorf("expected timestamp to be left to the syslog header, got %q", msg)
+	}
+}
+
+func TestSyslogConn_Reconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	c := &syslogConn{cfg: SyslogConfig{Network: "tcp", Address: addr, Tag: "svc"}}
+	if err := c.send(syslogInfo, []byte("first")); err == nil || errors.Is(err, errSyslogDown) {
+		t.Fatalf("expected dial error, got %v", err)
+	}
+	// The next attempt is not due yet, so callers fail fast
+	if err := c.send(syslogInfo, []byte("second")); !errors.Is(err, errSyslogDown) {
+		t.Errorf("expected errSyslogDown, got %v", err)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("failed to listen on %s again: %v", addr, err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	c.mu.Lock()
+	c.nextDial = time.Time{}
+	c.mu.Unlock()
+	if err := c.send(syslogInfo, []byte("third")); err != nil {
+		t.Fatalf("expected reconnect, got %v", err)
+	}
+	_ = c.Close()
+}
+
+func TestNew_PerSinkLevel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "errors.log")
+
+	log, closeLogs, err := New(Config{
+		Level: "debug",
+		Sinks: []SinkConfig{
+			{Type: SinkFile, Format: FormatJSON, Level: "error", File: FileConfig{Path: path}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	log.Info("routine")
+	log.Error("broken")
+	if err := closeLogs(); err != nil {
+		t.Fatalf("failed to close sinks: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Contains(string(data), "routine") || !strings.Contains(string(data), `"msg":"broken"`) {
+		t.Errorf("expected only error records in file, got %q", data)
+	}
+}
@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Syslog severities used for slog levels (RFC 5424)
+const (
+	syslogError   = 3
+	syslogWarning = 4
+	syslogInfo    = 6
+	syslogDebug   = 7
+	// syslogFacility is the "user-level messages" facility
+	syslogFacility = 1
+)
+
+// syslogRedialInterval is the minimum time between reconnect attempts
+const syslogRedialInterval = time.Second
+
+// errSyslogDown is returned while the connection is down and another
+// caller is reconnecting or the next attempt is not yet due
+var errSyslogDown = errors.New("syslog connection is down")
+
+// SyslogConfig holds syslog sink configuration
+type SyslogConfig struct {
+	// Network is udp, tcp, unix or unixgram
+	Network string
+	// Address is host:port for udp/tcp or a socket path for unix/unixgram
+	Address string
+	// Tag is the APP-NAME of each message
+	Tag string
+}
+
+// syslogConn is a connection shared by the writers of one syslog sink
+type syslogConn struct {
+	cfg      SyslogConfig
+	hostname string
+
+	// mu guards the connection and is held while writing, never while dialing
+	mu       sync.Mutex
+	conn     net.Conn
+	nextDial time.Time
+	closed   bool
+	dialing  atomic.Bool
+}
+
+// dialSyslog connects to the syslog server
+func dialSyslog(cfg SyslogConfig) (*syslogConn, error) {
+	if cfg.Network == "" {
+		cfg.Network = "udp"
+	}
+	if cfg.Address == "" {
+		if strings.HasPrefix(cfg.Network, "unix") {
+			cfg.Address = "/dev/log"
+		} else {
+			cfg.Address = "localhost:514"
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+
+	c := &syslogConn{cfg: cfg, hostname: hostname}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect (re)establishes the connection without holding the lock, so a
+// dead server only delays the caller that dials while others fail fast
+func (c *syslogConn) connect() error {
+	if !c.dialing.CompareAndSwap(false, true) {
+		return errSyslogDown
+	}
+	defer c.dialing.Store(false)
+
+	c.mu.Lock()
+	due := !time.Now().Before(c.nextDial)
+	c.mu.Unlock()
+	if !due {
+		return errSyslogDown
+	}
+
+	conn, err := net.DialTimeout(c.cfg.Network, c.cfg.Address, 5*time.Second)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.nextDial = time.Now().Add(syslogRedialInterval)
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	if c.closed {
+		_ = conn.Close()
+		return net.ErrClosed
+	}
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+	c.conn = conn
+	return nil
+}
+
+// send writes one message with the given severity, reconnecting once on failure
+func (c *syslogConn) send(severity int, msg []byte) error {
+	line := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		syslogFacility*8+severity,
+		time.Now().Format(time.RFC3339Nano),
+		c.hostname,
+		c.cfg.Tag,
+		os.Getpid(),
+		strings.TrimSuffix(string(msg), "\n"),
+	)
+	// Stream transports need a delimiter between messages
+	if c.cfg.Network == "tcp" || c.cfg.Network == "unix" {
+		line += "\n"
+	}
+
+	if c.write(line) == nil {
+		return nil
+	}
+	if err := c.connect(); err != nil {
+		return err
+	}
+	return c.write(line)
+}
+
+// write sends line on the current connection, dropping the connection if it fails
+func (c *syslogConn) write(line string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return errSyslogDown
+	}
+	if _, err := io.WriteString(c.conn, line); err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (c *syslogConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// syslogWriter sends every write as a message with a fixed severity
+type syslogWriter struct {
+	conn     *syslogConn
+	severity int
+}
+
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	if err := w.conn.send(w.severity, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// syslogHandler formats records with one handler per severity so each
+// message carries the priority of its level
+type syslogHandler struct {
+	debug, info, warn, error slog.Handler
+}
+
+// newSyslogHandler builds a handler per severity with newFormat
+func newSyslogHandler(conn *syslogConn, newFormat func(io.Writer) slog.Handler) slog.Handler {
+	return &syslogHandler{
+		debug: newFormat(&syslogWriter{conn: conn, severity: syslogDebug}),
+		info:  newFormat(&syslogWriter{conn: conn, severity: syslogInfo}),
+		warn:  newFormat(&syslogWriter{conn: conn, severity: syslogWarning}),
+		error: newFormat(&syslogWriter{conn: conn, severity: syslogError}),
+	}
+}
+
+// forLevel returns the handler writing with the severity of level
+func (h *syslogHandler) forLevel(level slog.Level) slog.Handler {
+	switch {
+	case level >= slog.LevelError:
+		return h.error
+	case level >= slog.LevelWarn:
+		return h.warn
+	case level >= slog.LevelInfo:
+		return h.info
+	default:
+		return h.debug
+	}
+}
+
+func (h *syslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.forLevel(level).Enabled(ctx, level)
+}
+
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.forLevel(r.Level).Handle(ctx, r)
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{
+		debug: h.debug.WithAttrs(attrs),
+		info:  h.info.WithAttrs(attrs),
+		warn:  h.warn.WithAttrs(attrs),
+		error: h.error.WithAttrs(attrs),
+	}
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{
+		debug: h.debug.WithGroup(name),
+		info:  h.info.WithGroup(name),
+		warn:  h.warn.WithGroup(name),
+		error: h.error.WithGroup(name),
+	}
+}
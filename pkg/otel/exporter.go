@@ -0,0 +1,321 @@
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// Supported OTLP transport protocols
+const (
+	ProtocolHTTP = "http/protobuf"
+	ProtocolGRPC = "grpc"
+)
+
+// ExporterConfig holds OTLP exporter transport configuration
+type ExporterConfig struct {
+	// Protocol is either http/protobuf or grpc
+	Protocol string
+	// Endpoint is the base endpoint for all signals; the scheme selects
+	// plaintext (http://) or TLS (https://)
+	Endpoint string
+	// Insecure disables TLS for endpoints given without a scheme
+	Insecure bool
+	// Certificate is a PEM file with the CA used to verify the collector
+	Certificate string
+	// ClientCertificate and ClientKey are PEM files used for mutual TLS
+	ClientCertificate string
+	ClientKey         string
+	Headers           map[string]string
+	// Compression is either gzip or none
+	Compression string
+	Timeout     time.Duration
+	// Traces, Metrics and Logs override the shared settings per signal, like
+	// the OTEL_EXPORTER_OTLP_{TRACES,METRICS,LOGS}_* variables
+	Traces  SignalConfig
+	Metrics SignalConfig
+	Logs    SignalConfig
+}
+
+// SignalConfig holds the settings of one signal that take precedence over the shared ones
+type SignalConfig struct {
+	// Endpoint is used as-is instead of the base endpoint plus the signal path
+	Endpoint string
+	// Headers replace the shared headers when set
+	Headers map[string]string
+	// Timeout replaces the shared timeout when positive
+	Timeout time.Duration
+}
+
+// exporterOptions holds the settings shared by every signal, resolved once
+type exporterOptions struct {
+	cfg ExporterConfig
+	tls *tls.Config
+}
+
+// signalOptions holds the settings of one signal's exporter
+type signalOptions struct {
+	endpoint
+	tls     *tls.Config
+	headers map[string]string
+	timeout time.Duration
+	gzip    bool
+}
+
+// newExporterOptions validates the protocol and loads the TLS configuration
+func newExporterOptions(cfg ExporterConfig) (*exporterOptions, error) {
+	if cfg.Protocol == "" {
+		cfg.Protocol = ProtocolHTTP
+	}
+	if cfg.Protocol != ProtocolHTTP && cfg.Protocol != ProtocolGRPC {
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", cfg.Protocol)
+	}
+	tlsCfg, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &exporterOptions{cfg: cfg, tls: tlsCfg}, nil
+}
+
+// signal applies the overrides of one signal to the shared settings
+func (o *exporterOptions) signal(override SignalConfig, signalPath string) (signalOptions, error) {
+	ep, err := resolveEndpoint(o.cfg.Endpoint, override.Endpoint, signalPath, o.cfg.Insecure)
+	if err != nil {
+		return signalOptions{}, err
+	}
+	opts := signalOptions{
+		endpoint: ep,
+		tls:      o.tls,
+		headers:  o.cfg.Headers,
+		timeout:  o.cfg.Timeout,
+		gzip:     o.cfg.Compression == "gzip",
+	}
+	if len(override.Headers) > 0 {
+		opts.headers = override.Headers
+	}
+	if override.Timeout > 0 {
+		opts.timeout = override.Timeout
+	}
+	return opts, nil
+}
+
+// endpoint is a resolved exporter destination
+type endpoint struct {
+	host     string
+	path     string
+	insecure bool
+}
+
+// resolveEndpoint picks the signal-specific endpoint over the base one and
+// derives TLS from the URL scheme, appending the signal path to base endpoints
+func resolveEndpoint(base, signal, signalPath string, insecure bool) (endpoint, error) {
+	raw, path := base, signalPath
+	if signal != "" {
+		raw, path = signal, ""
+	}
+
+	// Endpoints without a scheme are plain host:port pairs
+	if !strings.Contains(raw, "://") {
+		if path == "" {
+			path = signalPath
+		}
+		return endpoint{host: raw, path: path, insecure: insecure}, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return endpoint{}, fmt.Errorf("invalid endpoint %q: %w", raw, err)
+	}
+
+	ep := endpoint{host: u.Host}
+	switch u.Scheme {
+	case "http":
+		ep.insecure = true
+	case "https":
+		ep.insecure = false
+	default:
+		return endpoint{}, fmt.Errorf("unsupported endpoint scheme %q", u.Scheme)
+	}
+
+	if path == "" {
+		ep.path = u.Path
+		if ep.path == "" {
+			ep.path = signalPath
+		}
+	} else {
+		ep.path = strings.TrimSuffix(u.Path, "/") + path
+	}
+
+	return ep, nil
+}
+
+// newTLSConfig loads the custom CA and client certificate, returning nil
+// when neither is configured so exporters fall back to system defaults
+func newTLSConfig(cfg ExporterConfig) (*tls.Config, error) {
+	if cfg.Certificate == "" && cfg.ClientCertificate == "" {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.Certificate != "" {
+		pem, err := os.ReadFile(cfg.Certificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.Certificate)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.ClientCertificate != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertificate, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// newTraceExporter creates an OTLP trace exporter for the configured protocol
+func newTraceExporter(ctx context.Context, exp *exporterOptions) (trace.SpanExporter, error) {
+	o, err := exp.signal(exp.cfg.Traces, "/v1/traces")
+	if err != nil {
+		return nil, err
+	}
+
+	if exp.cfg.Protocol == ProtocolGRPC {
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(o.host),
+			otlptracegrpc.WithHeaders(o.headers),
+			otlptracegrpc.WithTimeout(o.timeout),
+		}
+		if o.insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else if o.tls != nil {
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(o.tls)))
+		}
+		if o.gzip {
+			opts = append(opts, otlptracegrpc.WithCompressor("gzip"))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(o.host),
+		otlptracehttp.WithURLPath(o.path),
+		otlptracehttp.WithHeaders(o.headers),
+		otlptracehttp.WithTimeout(o.timeout),
+	}
+	if o.insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	} else if o.tls != nil {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(o.tls))
+	}
+	if o.gzip {
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
+
+// newMetricExporter creates an OTLP metric exporter for the configured protocol
+func newMetricExporter(ctx context.Context, exp *exporterOptions) (metric.Exporter, error) {
+	o, err := exp.signal(exp.cfg.Metrics, "/v1/metrics")
+	if err != nil {
+		return nil, err
+	}
+
+	if exp.cfg.Protocol == ProtocolGRPC {
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(o.host),
+			otlpmetricgrpc.WithHeaders(o.headers),
+			otlpmetricgrpc.WithTimeout(o.timeout),
+		}
+		if o.insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		} else if o.tls != nil {
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(o.tls)))
+		}
+		if o.gzip {
+			opts = append(opts, otlpmetricgrpc.WithCompressor("gzip"))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	}
+
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(o.host),
+		otlpmetrichttp.WithURLPath(o.path),
+		otlpmetrichttp.WithHeaders(o.headers),
+		otlpmetrichttp.WithTimeout(o.timeout),
+	}
+	if o.insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	} else if o.tls != nil {
+		opts = append(opts, otlpmetrichttp.WithTLSClientConfig(o.tls))
+	}
+	if o.gzip {
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+	return otlpmetrichttp.New(ctx, opts...)
+}
+
+// newLogExporter creates an OTLP log exporter for the configured protocol
+func newLogExporter(ctx context.Context, exp *exporterOptions) (sdklog.Exporter, error) {
+	o, err := exp.signal(exp.cfg.Logs, "/v1/logs")
+	if err != nil {
+		return nil, err
+	}
+
+	if exp.cfg.Protocol == ProtocolGRPC {
+		opts := []otlploggrpc.Option{
+			otlploggrpc.WithEndpoint(o.host),
+			otlploggrpc.WithHeaders(o.headers),
+			otlploggrpc.WithTimeout(o.timeout),
+		}
+		if o.insecure {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		} else if o.tls != nil {
+			opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(o.tls)))
+		}
+		if o.gzip {
+			opts = append(opts, otlploggrpc.WithCompressor("gzip"))
+		}
+		return otlploggrpc.New(ctx, opts...)
+	}
+
+	opts := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(o.host),
+		otlploghttp.WithURLPath(o.path),
+		otlploghttp.WithHeaders(o.headers),
+		otlploghttp.WithTimeout(o.timeout),
+	}
+	if o.insecure {
+		opts = append(opts, otlploghttp.WithInsecure())
+	} else if o.tls != nil {
+		opts = append(opts, otlploghttp.WithTLSClientConfig(o.tls))
+	}
+	if o.gzip {
+		opts = append(opts, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+	}
+	return otlploghttp.New(ctx, opts...)
+}
@@ -0,0 +1,96 @@
+package otel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		signal   string
+		insecure bool
+		want     endpoint
+	}{
+		{
+			name: "http base endpoint is plaintext with signal path",
+			base: "http://localhost:4318",
+			want: endpoint{host: "localhost:4318", path: "/v1/traces", insecure: true},
+		},
+		{
+			name: "https base endpoint keeps TLS and path prefix",
+			base: "https://collector.example.com/otlp/",
+			want: endpoint{host: "collector.example.com", path: "/otlp/v1/traces", insecure: false},
+		},
+		{
+			name:   "signal endpoint is used as-is",
+			base:   "http://localhost:4318",
+			signal: "https://traces.example.com/custom",
+			want:   endpoint{host: "traces.example.com", path: "/custom", insecure: false},
+		},
+		{
+			name:     "endpoint without scheme honors insecure flag",
+			base:     "localhost:4317",
+			insecure: true,
+			want:     endpoint{host: "localhost:4317", path: "/v1/traces", insecure: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveEndpoint(tt.base, tt.signal, "/v1/traces", tt.insecure)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveEndpoint_UnsupportedScheme(t *testing.T) {
+	if _, err := resolveEndpoint("ftp://localhost:4318", "", "/v1/traces", false); err == nil {
+		t.Error("expected error for unsupported scheme, got nil")
+	}
+}
+
+func TestExporterOptions_SignalOverrides(t *testing.T) {
+	exp, err := newExporterOptions(ExporterConfig{
+		Endpoint: "http://collector:4318",
+		Headers:  map[string]string{"api-key": "shared"},
+		Timeout:  10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	shared, err := exp.signal(SignalConfig{}, "/v1/metrics")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shared.headers["api-key"] != "shared" || shared.timeout != 10*time.Second {
+		t.Errorf("expected shared headers and timeout, got %v and %s", shared.headers, shared.timeout)
+	}
+	if shared.endpoint.path != "/v1/metrics" {
+		t.Errorf("expected signal path appended, got %q", shared.endpoint.path)
+	}
+
+	traces, err := exp.signal(SignalConfig{
+		Headers: map[string]string{"api-key": "traces"},
+		Timeout: 2 * time.Second,
+	}, "/v1/traces")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if traces.headers["api-key"] != "traces" || traces.timeout != 2*time.Second {
+		t.Errorf("expected per-signal headers and timeout, got %v and %s", traces.headers, traces.timeout)
+	}
+}
+
+func TestNewExporterOptions_UnsupportedProtocol(t *testing.T) {
+	if _, err := newExporterOptions(ExporterConfig{Protocol: "http/json"}); err == nil {
+		t.Error("expected error for unsupported protocol, got nil")
+	}
+}
@@ -0,0 +1,282 @@
+package otel
+
+import (
+	"context"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Telemetry modes
+const (
+	// ModeOTLP exports telemetry to an OTLP collector
+	ModeOTLP = "otlp"
+	// ModeLocal keeps recent spans in memory for the /debug/traces page
+	ModeLocal = "local"
+)
+
+// SpanRecord is a snapshot of a finished span
+type SpanRecord struct {
+	TraceID       string
+	SpanID        string
+	ParentID      string
+	Name          string
+	Kind          string
+	Start         time.Time
+	End           time.Time
+	Error         bool
+	StatusMessage string
+	Attributes    []attribute.KeyValue
+}
+
+// Duration returns how long the span took
+func (s SpanRecord) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// TraceRecord groups the buffered spans of one trace
+type TraceRecord struct {
+	TraceID  string
+	Root     SpanRecord
+	Spans    []SpanTreeNode
+	Start    time.Time
+	Duration time.Duration
+	Error    bool
+}
+
+// SpanTreeNode is a span with its depth in the trace tree
+type SpanTreeNode struct {
+	SpanRecord
+	Depth int
+	// Offset and Width position the span relative to the trace, in percent
+	Offset float64
+	Width  float64
+}
+
+// SpanBuffer is a span exporter that keeps the most recent spans in a ring buffer
+type SpanBuffer struct {
+	mu    sync.RWMutex
+	spans []SpanRecord
+	next  int
+	full  bool
+}
+
+// NewSpanBuffer creates a span buffer holding up to size spans
+func NewSpanBuffer(size int) *SpanBuffer {
+	if size <= 0 {
+		size = 1000
+	}
+	return &SpanBuffer{spans: make([]SpanRecord, size)}
+}
+
+// ExportSpans stores snapshots of the finished spans, overwriting the oldest
+func (b *SpanBuffer) ExportSpans(_ context.Context, spans []trace.ReadOnlySpan) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range spans {
+		record := SpanRecord{
+			TraceID:       s.SpanContext().TraceID().String(),
+			SpanID:        s.SpanContext().SpanID().String(),
+			Name:          s.Name(),
+			Kind:          s.SpanKind().String(),
+			Start:         s.StartTime(),
+			End:           s.EndTime(),
+			Error:         s.Status().Code == codes.Error,
+			StatusMessage: s.Status().Description,
+			Attributes:    s.Attributes(),
+		}
+		if s.Parent().IsValid() {
+			record.ParentID = s.Parent().SpanID().String()
+		}
+
+		b.spans[b.next] = record
+		b.next = (b.next + 1) % len(b.spans)
+		if b.next == 0 {
+			b.full = true
+		}
+	}
+	return nil
+}
+
+// Shutdown implements trace.SpanExporter
+func (b *SpanBuffer) Shutdown(context.Context) error {
+	return nil
+}
+
+// Traces returns the buffered traces, most recent first
+func (b *SpanBuffer) Traces() []TraceRecord {
+	b.mu.RLock()
+	spans := make([]SpanRecord, 0, len(b.spans))
+	if b.full {
+		spans = append(spans, b.spans[b.next:]...)
+	}
+	spans = append(spans, b.spans[:b.next]...)
+	b.mu.RUnlock()
+
+	byTrace := make(map[string][]SpanRecord)
+	for _, s := range spans {
+		byTrace[s.TraceID] = append(byTrace[s.TraceID], s)
+	}
+
+	traces := make([]TraceRecord, 0, len(byTrace))
+	for traceID, spans := range byTrace {
+		traces = append(traces, buildTrace(traceID, spans))
+	}
+
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].Start.After(traces[j].Start)
+	})
+	return traces
+}
+
+// Trace returns a single buffered trace
+func (b *SpanBuffer) Trace(traceID string) (TraceRecord, bool) {
+	for _, t := range b.Traces() {
+		if t.TraceID == traceID {
+			return t, true
+		}
+	}
+	return TraceRecord{}, false
+}
+
+// buildTrace orders the spans of a trace depth-first from its roots
+func buildTrace(traceID string, spans []SpanRecord) TraceRecord {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+
+	known := make(map[string]bool, len(spans))
+	children := make(map[string][]SpanRecord)
+	for _, s := range spans {
+		known[s.SpanID] = true
+	}
+
+	var roots []SpanRecord
+	for _, s := range spans {
+		// Spans whose parent was evicted or lives in another service are shown as roots
+		if s.ParentID == "" || !known[s.ParentID] {
+			roots = append(roots, s)
+			continue
+		}
+		children[s.ParentID] = append(children[s.ParentID], s)
+	}
+
+	t := TraceRecord{TraceID: traceID, Root: roots[0], Start: roots[0].Start}
+	end := roots[0].End
+	for _, s := range spans {
+		if s.Start.Before(t.Start) {
+			t.Start = s.Start
+		}
+		if s.End.After(end) {
+			end = s.End
+		}
+		if s.Error {
+			t.Error = true
+		}
+	}
+	t.Duration = end.Sub(t.Start)
+
+	var walk func(s SpanRecord, depth int)
+	walk = func(s SpanRecord, depth int) {
+		node := SpanTreeNode{SpanRecord: s, Depth: depth}
+		if t.Duration > 0 {
+			node.Offset = float64(s.Start.Sub(t.Start)) / float64(t.Duration) * 100
+			node.Width = float64(s.Duration()) / float64(t.Duration) * 100
+		}
+		t.Spans = append(t.Spans, node)
+		for _, child := range children[s.SpanID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	return t
+}
+
+// ServeHTTP renders the recent traces, or a single trace with ?trace=<id>
+func (b *SpanBuffer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Traces []TraceRecord
+		Trace  *TraceRecord
+	}{}
+
+	if traceID := r.URL.Query().Get("trace"); traceID != "" {
+		t, ok := b.Trace(traceID)
+		if !ok {
+			http.Error(w, "trace not found", http.StatusNotFound)
+			return
+		}
+		data.Trace = &t
+	} else {
+		data.Traces = b.Traces()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tracesTemplate.Execute(w, data); err != nil {
+		slog.ErrorContext(r.Context(), "failed to render traces page",
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+var tracesTemplate = template.Must(template.New("traces").Funcs(template.FuncMap{
+	"indent": func(depth int) int { return depth * 16 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Recent traces</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #ddd; font-size: 14px; }
+.error { color: #c00; }
+.bar { position: relative; height: 12px; background: #f3f3f3; min-width: 300px; }
+.bar span { position: absolute; top: 0; height: 12px; background: #4a90d9; min-width: 1px; }
+.bar span.error { background: #c00; }
+</style>
+</head>
+<body>
+{{if .Trace}}
+<p><a href="?">&larr; all traces</a></p>
+<h1>{{.Trace.Root.Name}}</h1>
+<p>Trace {{.Trace.TraceID}} &middot; {{.Trace.Duration}} &middot; {{len .Trace.Spans}} spans</p>
+<table>
+<tr><th>Span</th><th>Kind</th><th>Duration</th><th>Timeline</th></tr>
+{{range .Trace.Spans}}
+<tr{{if .Error}} class="error"{{end}}>
+<td style="padding-left: {{indent .Depth}}px" title="{{range .Attributes}}{{.Key}}={{.Value.Emit}}&#10;{{end}}">{{.Name}}{{if .StatusMessage}} ({{.StatusMessage}}){{end}}</td>
+<td>{{.Kind}}</td>
+<td>{{.Duration}}</td>
+<td><div class="bar"><span{{if .Error}} class="error"{{end}} style="left: {{printf "%.2f" .Offset}}%; width: {{printf "%.2f" .Width}}%"></span></div></td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<h1>Recent traces</h1>
+<table>
+<tr><th>Started</th><th>Root span</th><th>Spans</th><th>Duration</th></tr>
+{{range .Traces}}
+<tr{{if .Error}} class="error"{{end}}>
+<td>{{.Start.Format "15:04:05.000"}}</td>
+<td><a href="?trace={{.TraceID}}">{{.Root.Name}}</a></td>
+<td>{{len .Spans}}</td>
+<td>{{.Duration}}</td>
+</tr>
+{{else}}
+<tr><td colspan="4">No traces recorded yet</td></tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,95 @@
+package otel
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSpanBuffer_Traces(t *testing.T) {
+	spans := NewSpanBuffer(10)
+	provider := trace.NewTracerProvider(trace.WithSyncer(spans))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	tracer := provider.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	root.End()
+
+	traces := spans.Traces()
+	if len(traces) != 1 {
+		t.Fatalf("expected 1 trace, got %d", len(traces))
+	}
+
+	tr := traces[0]
+	if tr.Root.Name != "root" || !tr.Error {
+		t.Errorf("expected errored trace rooted at 'root', got %+v", tr.Root)
+	}
+	if len(tr.Spans) != 2 || tr.Spans[0].Depth != 0 || tr.Spans[1].Name != "child" || tr.Spans[1].Depth != 1 {
+		t.Errorf("expected root followed by child at depth 1, got %+v", tr.Spans)
+	}
+}
+
+func TestSpanBuffer_EvictsOldest(t *testing.T) {
+	spans := NewSpanBuffer(2)
+	provider := trace.NewTracerProvider(trace.WithSyncer(spans))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	tracer := provider.Tracer("test")
+
+	for _, name := range []string{"first", "second", "third"} {
+		_, span := tracer.Start(context.Background(), name)
+		span.End()
+	}
+
+	traces := spans.Traces()
+	if len(traces) != 2 {
+		t.Fatalf("expected 2 traces, got %d", len(traces))
+	}
+	for _, tr := range traces {
+		if tr.Root.Name == "first" {
+			t.Error("expected oldest span to be evicted")
+		}
+	}
+}
+
+func TestSpanBuffer_ServeHTTP(t *testing.T) {
+	spans := NewSpanBuffer(10)
+	provider := trace.NewTracerProvider(trace.WithSyncer(spans))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	_, span := provider.Tracer("test").Start(context.Background(), "GET /api/example")
+	span.End()
+
+	rec := httptest.NewRecorder()
+	spans.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/traces", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "GET /api/example") {
+		t.Error("expected page to list the recorded trace")
+	}
+
+	rec = httptest.NewRecorder()
+	spans.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/traces?trace=unknown", http.NoBody))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestSetup_UnknownMode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := Setup(context.Background(), Config{Mode: "locla"}, logger); err == nil {
+		t.Error("expected error for unknown telemetry mode, got nil")
+	}
+}
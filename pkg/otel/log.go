@@ -0,0 +1,38 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// LogHandler returns an slog handler that emits records to the global
+// OpenTelemetry logger provider, correlated with the span in the context
+func LogHandler(name string) slog.Handler {
+	return otelslog.NewHandler(name)
+}
+
+func setupLoggerProvider(ctx context.Context, res *resource.Resource, exp *exporterOptions, logger *slog.Logger) (func(context.Context) error, error) {
+	// Create OTLP log exporter
+	logExporter, err := newLogExporter(ctx, exp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log exporter: %w", err)
+	}
+
+	// Create logger provider
+	loggerProvider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
+		sdklog.WithResource(res),
+	)
+
+	global.SetLoggerProvider(loggerProvider)
+
+	logger.Info("logger provider initialized")
+
+	return loggerProvider.Shutdown, nil
+}
@@ -2,14 +2,14 @@ package otel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"runtime"
 	"time"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -17,94 +17,162 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 )
 
+// instrumentationName identifies the instruments created by this package
+const instrumentationName = "github.com/ahxar/go-backend-service/pkg/otel"
+
 // Config holds OpenTelemetry configuration
 type Config struct {
 	ServiceName    string
 	ServiceVersion string
 	Environment    string
-	Endpoint       string
 	Enabled        bool
+	Exporter       ExporterConfig
+	Sampling       SamplingConfig
+	// Logs exports log records through the OTLP logs signal
+	Logs bool
+	// Mode is either otlp or local; local keeps spans in LocalSpans instead of exporting
+	Mode       string
+	LocalSpans *SpanBuffer
+	// RuntimeMetrics registers Go runtime and process metrics
+	RuntimeMetrics bool
+	Build          BuildInfo
+}
+
+// BuildInfo identifies the running binary
+type BuildInfo struct {
+	Version string
+	Commit  string
 }
 
-// Setup initializes OpenTelemetry with tracing and metrics
+// Setup initializes OpenTelemetry with tracing, metrics and optionally logs
 func Setup(ctx context.Context, cfg Config, logger *slog.Logger) (func(context.Context) error, error) {
+	if cfg.Mode != ModeOTLP && cfg.Mode != ModeLocal {
+		return nil, fmt.Errorf("unknown telemetry mode %q", cfg.Mode)
+	}
+
 	if !cfg.Enabled {
 		logger.Info("OpenTelemetry is disabled")
 		return func(context.Context) error { return nil }, nil
 	}
 
+	if cfg.Mode == ModeLocal && cfg.LocalSpans == nil {
+		return nil, errors.New("local telemetry mode requires a span buffer")
+	}
+
 	// Create resource
 	res, err := resource.New(ctx,
 		resource.WithAttributes(
 			semconv.ServiceName(cfg.ServiceName),
 			semconv.ServiceVersion(cfg.ServiceVersion),
 			semconv.DeploymentEnvironment(cfg.Environment),
+			semconv.ProcessRuntimeName("go"),
+			semconv.ProcessRuntimeVersion(runtime.Version()),
+			attribute.String("build.version", cfg.Build.Version),
+			attribute.String("build.commit", cfg.Build.Commit),
 		),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
+	// Exporter settings shared by every signal; local mode exports nothing
+	var exp *exporterOptions
+	if cfg.Mode != ModeLocal {
+		exp, err = newExporterOptions(cfg.Exporter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure exporters: %w", err)
+		}
+	}
+
+	// Shutdown functions of the initialized providers, run in reverse order
+	var shutdowns []func(context.Context) error
+	shutdown := func(ctx context.Context) error {
+		var errs []error
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			if err := shutdowns[i](ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	cleanup := func(err error) error {
+		if shutdownErr := shutdown(ctx); shutdownErr != nil {
+			logger.Error("failed to shutdown providers during cleanup",
+				slog.String("error", shutdownErr.Error()),
+			)
+		}
+		return err
+	}
+
 	// Setup trace provider
-	traceShutdown, err := setupTraceProvider(ctx, res, cfg.Endpoint, logger)
+	traceShutdown, err := setupTraceProvider(ctx, res, cfg, exp, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup trace provider: %w", err)
 	}
+	shutdowns = append(shutdowns, traceShutdown)
 
 	// Setup metric provider
-	metricShutdown, err := setupMeterProvider(ctx, res, cfg.Endpoint, logger)
+	metricShutdown, err := setupMeterProvider(ctx, res, cfg, exp, logger)
 	if err != nil {
-		if shutdownErr := traceShutdown(ctx); shutdownErr != nil {
-			logger.Error("failed to shutdown trace provider during cleanup",
-				slog.String("error", shutdownErr.Error()),
-			)
+		return nil, cleanup(fmt.Errorf("failed to setup meter provider: %w", err))
+	}
+	shutdowns = append(shutdowns, metricShutdown)
+
+	// Setup logger provider
+	if cfg.Logs && cfg.Mode == ModeLocal {
+		logger.Warn("OTLP log export is not available in local telemetry mode")
+	} else if cfg.Logs {
+		logShutdown, err := setupLoggerProvider(ctx, res, exp, logger)
+		if err != nil {
+			return nil, cleanup(fmt.Errorf("failed to setup logger provider: %w", err))
 		}
-		return nil, fmt.Errorf("failed to setup meter provider: %w", err)
+		shutdowns = append(shutdowns, logShutdown)
 	}
 
 	logger.Info("OpenTelemetry initialized",
 		slog.String("service", cfg.ServiceName),
-		slog.String("endpoint", cfg.Endpoint),
+		slog.String("mode", cfg.Mode),
+		slog.String("endpoint", cfg.Exporter.Endpoint),
+		slog.String("protocol", cfg.Exporter.Protocol),
+		slog.Bool("logs", cfg.Logs),
 	)
 
 	// Return combined shutdown function
-	return func(ctx context.Context) error {
-		var err error
-		if shutdownErr := traceShutdown(ctx); shutdownErr != nil {
-			err = shutdownErr
-		}
-		if shutdownErr := metricShutdown(ctx); shutdownErr != nil {
-			if err != nil {
-				err = fmt.Errorf("%v; %w", err, shutdownErr)
-			} else {
-				err = shutdownErr
-			}
-		}
-		return err
-	}, nil
+	return shutdown, nil
 }
 
-func setupTraceProvider(ctx context.Context, res *resource.Resource, endpoint string, logger *slog.Logger) (func(context.Context) error, error) {
-	// Strip scheme from endpoint if present (WithEndpoint expects host:port only)
-	endpoint = strings.TrimPrefix(endpoint, "http://")
-	endpoint = strings.TrimPrefix(endpoint, "https://")
-
-	// Create OTLP trace exporter
-	traceExporter, err := otlptracehttp.New(ctx,
-		otlptracehttp.WithEndpoint(endpoint),
-		otlptracehttp.WithInsecure(),
-	)
+func setupTraceProvider(ctx context.Context, res *resource.Resource, cfg Config, exp *exporterOptions, logger *slog.Logger) (func(context.Context) error, error) {
+	sampler, err := newSampler(cfg.Sampling)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		return nil, fmt.Errorf("failed to create sampler: %w", err)
+	}
+
+	var spanProcessor trace.SpanProcessor
+	if cfg.Mode == ModeLocal {
+		// Record spans synchronously so they show up on the debug page immediately
+		spanProcessor = trace.NewSimpleSpanProcessor(cfg.LocalSpans)
+	} else {
+		// Create OTLP trace exporter
+		traceExporter, err := newTraceExporter(ctx, exp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		}
+
+		spanProcessor = trace.NewBatchSpanProcessor(traceExporter,
+			trace.WithBatchTimeout(5*time.Second),
+		)
+	}
+
+	// Buffer unsampled traces so errored ones can still be exported
+	if cfg.Sampling.KeepErrors {
+		spanProcessor = newTailProcessor(spanProcessor)
 	}
 
 	// Create trace provider
 	traceProvider := trace.NewTracerProvider(
-		trace.WithBatcher(traceExporter,
-			trace.WithBatchTimeout(5*time.Second),
-		),
+		trace.WithSpanProcessor(spanProcessor),
 		trace.WithResource(res),
-		trace.WithSampler(trace.AlwaysSample()),
+		trace.WithSampler(sampler),
 	)
 
 	otel.SetTracerProvider(traceProvider)
@@ -113,32 +181,40 @@ func setupTraceProvider(ctx context.Context, res *resource.Resource, endpoint st
 		propagation.Baggage{},
 	))
 
-	logger.Info("trace provider initialized")
+	logger.Info("trace provider initialized",
+		slog.String("sampler", sampler.Description()),
+	)
 
 	return traceProvider.Shutdown, nil
 }
 
-func setupMeterProvider(ctx context.Context, res *resource.Resource, endpoint string, logger *slog.Logger) (func(context.Context) error, error) {
-	// Strip scheme from endpoint if present (WithEndpoint expects host:port only)
-	endpoint = strings.TrimPrefix(endpoint, "http://")
-	endpoint = strings.TrimPrefix(endpoint, "https://")
+func setupMeterProvider(ctx context.Context, res *resource.Resource, cfg Config, exp *exporterOptions, logger *slog.Logger) (func(context.Context) error, error) {
+	opts := []metric.Option{metric.WithResource(res)}
 
-	// Create OTLP metric exporter
-	metricExporter, err := otlpmetrichttp.New(ctx,
-		otlpmetrichttp.WithEndpoint(endpoint),
-		otlpmetrichttp.WithInsecure(),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create metric exporter: %w", err)
+	// Local mode records measurements without exporting them
+	if cfg.Mode != ModeLocal {
+		// Create OTLP metric exporter
+		metricExporter, err := newMetricExporter(ctx, exp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metric exporter: %w", err)
+		}
+
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(metricExporter,
+			metric.WithInterval(10*time.Second),
+		)))
 	}
 
 	// Create meter provider
-	meterProvider := metric.NewMeterProvider(
-		metric.WithReader(metric.NewPeriodicReader(metricExporter,
-			metric.WithInterval(10*time.Second),
-		)),
-		metric.WithResource(res),
-	)
+	meterProvider := metric.NewMeterProvider(opts...)
+
+	// Register before publishing the provider, so a failure leaves no global
+	// pointing at a provider nobody shuts down
+	if cfg.RuntimeMetrics {
+		if err := registerRuntimeMetrics(meterProvider.Meter(instrumentationName), cfg.Build); err != nil {
+			_ = meterProvider.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to register runtime metrics: %w", err)
+		}
+	}
 
 	otel.SetMeterProvider(meterProvider)
 
@@ -0,0 +1,47 @@
+package otel
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// processStats holds resource usage of the current process
+type processStats struct {
+	userCPU   time.Duration
+	systemCPU time.Duration
+	rss       int64
+	openFDs   int64
+}
+
+// readProcessStats reads CPU time from getrusage and memory and file
+// descriptor usage from /proc
+func readProcessStats() (processStats, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return processStats{}, false
+	}
+
+	stats := processStats{
+		userCPU:   time.Duration(usage.Utime.Nano()),
+		systemCPU: time.Duration(usage.Stime.Nano()),
+	}
+
+	// statm reports sizes in pages: total resident shared text lib data dirty
+	if statm, err := os.ReadFile("/proc/self/statm"); err == nil {
+		fields := strings.Fields(string(statm))
+		if len(fields) > 1 {
+			if pages, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				stats.rss = pages * int64(os.Getpagesize())
+			}
+		}
+	}
+
+	if fds, err := os.ReadDir("/proc/self/fd"); err == nil {
+		stats.openFDs = int64(len(fds))
+	}
+
+	return stats, true
+}
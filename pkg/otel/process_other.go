@@ -0,0 +1,18 @@
+//go:build !linux
+
+package otel
+
+import "time"
+
+// processStats holds resource usage of the current process
+type processStats struct {
+	userCPU   time.Duration
+	systemCPU time.Duration
+	rss       int64
+	openFDs   int64
+}
+
+// readProcessStats is only implemented on Linux
+func readProcessStats() (processStats, bool) {
+	return processStats{}, false
+}
@@ -0,0 +1,250 @@
+package otel
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// runtime/metrics samples read on every collection
+const (
+	metricGoroutines   = "/sched/goroutines:goroutines"
+	metricMemoryTotal  = "/memory/classes/total:bytes"
+	metricMemoryFree   = "/memory/classes/heap/released:bytes"
+	metricHeapObjects  = "/memory/classes/heap/objects:bytes"
+	metricHeapGoal     = "/gc/heap/goal:bytes"
+	metricHeapAllocs   = "/gc/heap/allocs:bytes"
+	metricGCCycles     = "/gc/cycles/total:gc-cycles"
+	metricGCPauses     = "/sched/pauses/total/gc:seconds"
+	metricSchedLatency = "/sched/latencies:seconds"
+)
+
+// reportedQuantiles are the quantiles reported for runtime histograms
+var reportedQuantiles = []float64{0.5, 0.99, 1}
+
+// runtimeCollector reads runtime/metrics and process statistics on every
+// metric collection and keeps the previous histograms to report per-interval quantiles
+type runtimeCollector struct {
+	mu      sync.Mutex
+	samples []metrics.Sample
+	prev    map[string]*metrics.Float64Histogram
+}
+
+// registerRuntimeMetrics registers Go runtime, process and build info instruments
+func registerRuntimeMetrics(meter metric.Meter, build BuildInfo) error {
+	c := &runtimeCollector{
+		samples: []metrics.Sample{
+			{Name: metricGoroutines},
+			{Name: metricMemoryTotal},
+			{Name: metricMemoryFree},
+			{Name: metricHeapObjects},
+			{Name: metricHeapGoal},
+			{Name: metricHeapAllocs},
+			{Name: metricGCCycles},
+			{Name: metricGCPauses},
+			{Name: metricSchedLatency},
+		},
+		prev: make(map[string]*metrics.Float64Histogram),
+	}
+
+	goroutines, err := meter.Int64ObservableUpDownCounter("go.goroutine.count",
+		metric.WithDescription("Count of live goroutines"),
+		metric.WithUnit("{goroutine}"),
+	)
+	if err != nil {
+		return err
+	}
+	memoryUsed, err := meter.Int64ObservableUpDownCounter("go.memory.used",
+		metric.WithDescription("Memory used by the Go runtime"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+	heapObjects, err := meter.Int64ObservableUpDownCounter("go.memory.heap.objects",
+		metric.WithDescription("Memory occupied by live and unswept heap objects"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+	heapGoal, err := meter.Int64ObservableUpDownCounter("go.memory.gc.goal",
+		metric.WithDescription("Heap size target for the end of the GC cycle"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+	allocated, err := meter.Int64ObservableCounter("go.memory.allocated",
+		metric.WithDescription("Memory allocated to the heap by the application"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+	gcCycles, err := meter.Int64ObservableCounter("go.gc.cycles",
+		metric.WithDescription("Completed GC cycles"),
+		metric.WithUnit("{cycle}"),
+	)
+	if err != nil {
+		return err
+	}
+	gcPause, err := meter.Float64ObservableGauge("go.gc.pause.duration",
+		metric.WithDescription("Quantiles of stop-the-world GC pauses since the last collection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+	schedLatency, err := meter.Float64ObservableGauge("go.schedule.latency",
+		metric.WithDescription("Quantiles of time goroutines spent runnable before running since the last collection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+	cpuTime, err := meter.Float64ObservableCounter("process.cpu.time",
+		metric.WithDescription("CPU time consumed by the process"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+	rss, err := meter.Int64ObservableUpDownCounter("process.memory.usage",
+		metric.WithDescription("Resident set size of the process"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+	openFDs, err := meter.Int64ObservableUpDownCounter("process.open_file_descriptor.count",
+		metric.WithDescription("Number of open file descriptors"),
+		metric.WithUnit("{file_descriptor}"),
+	)
+	if err != nil {
+		return err
+	}
+	buildInfo, err := meter.Int64ObservableGauge("service.build.info",
+		metric.WithDescription("Build information of the running binary, always 1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	buildAttrs := metric.WithAttributes(
+		attribute.String("version", build.Version),
+		attribute.String("commit", build.Commit),
+		attribute.String("go_version", runtime.Version()),
+	)
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		metrics.Read(c.samples)
+		values := make(map[string]metrics.Value, len(c.samples))
+		for _, sample := range c.samples {
+			values[sample.Name] = sample.Value
+		}
+
+		o.ObserveInt64(goroutines, uint64Value(values[metricGoroutines]))
+		o.ObserveInt64(memoryUsed, uint64Value(values[metricMemoryTotal])-uint64Value(values[metricMemoryFree]))
+		o.ObserveInt64(heapObjects, uint64Value(values[metricHeapObjects]))
+		o.ObserveInt64(heapGoal, uint64Value(values[metricHeapGoal]))
+		o.ObserveInt64(allocated, uint64Value(values[metricHeapAllocs]))
+		o.ObserveInt64(gcCycles, uint64Value(values[metricGCCycles]))
+
+		c.observeQuantiles(o, gcPause, metricGCPauses, values[metricGCPauses])
+		c.observeQuantiles(o, schedLatency, metricSchedLatency, values[metricSchedLatency])
+
+		if stats, ok := readProcessStats(); ok {
+			o.ObserveFloat64(cpuTime, stats.userCPU.Seconds(), metric.WithAttributes(attribute.String("cpu.mode", "user")))
+			o.ObserveFloat64(cpuTime, stats.systemCPU.Seconds(), metric.WithAttributes(attribute.String("cpu.mode", "system")))
+			o.ObserveInt64(rss, stats.rss)
+			o.ObserveInt64(openFDs, stats.openFDs)
+		}
+
+		o.ObserveInt64(buildInfo, 1, buildAttrs)
+		return nil
+	}, goroutines, memoryUsed, heapObjects, heapGoal, allocated, gcCycles, gcPause, schedLatency, cpuTime, rss, openFDs, buildInfo)
+
+	return err
+}
+
+// observeQuantiles reports quantiles of the histogram delta since the previous collection
+func (c *runtimeCollector) observeQuantiles(o metric.Observer, gauge metric.Float64Observable, name string, value metrics.Value) {
+	if value.Kind() != metrics.KindFloat64Histogram {
+		return
+	}
+
+	cur := value.Float64Histogram()
+	delta := histogramDelta(c.prev[name], cur)
+	c.prev[name] = &metrics.Float64Histogram{
+		Counts:  append([]uint64(nil), cur.Counts...),
+		Buckets: cur.Buckets,
+	}
+
+	for _, q := range reportedQuantiles {
+		o.ObserveFloat64(gauge, histogramQuantile(delta, q),
+			metric.WithAttributes(attribute.Float64("quantile", q)),
+		)
+	}
+}
+
+// histogramDelta subtracts the previous cumulative counts from the current ones
+func histogramDelta(prev, cur *metrics.Float64Histogram) *metrics.Float64Histogram {
+	delta := &metrics.Float64Histogram{
+		Counts:  append([]uint64(nil), cur.Counts...),
+		Buckets: cur.Buckets,
+	}
+	if prev != nil && len(prev.Counts) == len(cur.Counts) {
+		for i := range delta.Counts {
+			delta.Counts[i] -= prev.Counts[i]
+		}
+	}
+	return delta
+}
+
+// histogramQuantile estimates a quantile using the upper bound of the bucket
+// it falls in, returning 0 for an empty histogram
+func histogramQuantile(h *metrics.Float64Histogram, q float64) float64 {
+	var total uint64
+	for _, count := range h.Counts {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, count := range h.Counts {
+		seen += count
+		if seen < rank {
+			continue
+		}
+		// Bucket i spans Buckets[i] to Buckets[i+1]; fall back to the lower
+		// bound for the unbounded last bucket
+		if upper := h.Buckets[i+1]; !math.IsInf(upper, 1) {
+			return upper
+		}
+		return h.Buckets[i]
+	}
+	return 0
+}
+
+func uint64Value(v metrics.Value) int64 {
+	if v.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return int64(v.Uint64()) //nolint:gosec // runtime counters fit in int64
+}
@@ -0,0 +1,76 @@
+package otel
+
+import (
+	"context"
+	"math"
+	"runtime/metrics"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterRuntimeMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	err := registerRuntimeMetrics(provider.Meter("test"), BuildInfo{Version: "1.2.3", Commit: "abc123"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	found := make(map[string]metricdata.Metrics)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = m
+		}
+	}
+
+	for _, name := range []string{"go.goroutine.count", "go.memory.used", "go.gc.pause.duration", "go.schedule.latency", "service.build.info"} {
+		if _, ok := found[name]; !ok {
+			t.Errorf("expected metric %s to be reported", name)
+		}
+	}
+
+	gauge, ok := found["service.build.info"].Data.(metricdata.Gauge[int64])
+	if !ok || len(gauge.DataPoints) != 1 {
+		t.Fatalf("expected a single build info data point, got %+v", found["service.build.info"].Data)
+	}
+	if v, _ := gauge.DataPoints[0].Attributes.Value(attribute.Key("version")); v.AsString() != "1.2.3" {
+		t.Errorf("expected version attribute 1.2.3, got %q", v.AsString())
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{0, 90, 9, 1},
+		Buckets: []float64{0, 0.001, 0.01, 0.1, math.Inf(1)},
+	}
+
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{q: 0.5, want: 0.01},
+		{q: 0.99, want: 0.1},
+		{q: 1, want: 0.1},
+	}
+
+	for _, tt := range tests {
+		if got := histogramQuantile(h, tt.q); got != tt.want {
+			t.Errorf("quantile %v: expected %v, got %v", tt.q, tt.want, got)
+		}
+	}
+
+	prev := &metrics.Float64Histogram{Counts: []uint64{0, 90, 9, 0}, Buckets: h.Buckets}
+	if got := histogramQuantile(histogramDelta(prev, h), 0.5); got != 0.1 {
+		t.Errorf("expected delta median in the last bucket (lower bound 0.1), got %v", got)
+	}
+}
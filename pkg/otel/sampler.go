@@ -0,0 +1,297 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// SamplingConfig holds trace sampling configuration
+type SamplingConfig struct {
+	// Strategy is one of always_on, always_off, traceidratio,
+	// parentbased_always_on, parentbased_always_off or parentbased_traceidratio
+	Strategy string
+	// Ratio is the fraction of traces sampled by the traceidratio strategies
+	Ratio float64
+	// RateLimit caps the number of sampling decisions per second (0 disables)
+	RateLimit float64
+	// NeverPaths are request paths that are never sampled
+	NeverPaths []string
+	// AlwaysPaths are request paths that are always sampled
+	AlwaysPaths []string
+	// KeepErrors buffers unsampled traces and exports them if any span errors
+	KeepErrors bool
+}
+
+// maxBufferedTraces bounds the number of unsampled traces held for tail decisions
+const maxBufferedTraces = 4096
+
+// maxBufferedSpans bounds the number of spans held per unsampled trace
+const maxBufferedSpans = 512
+
+// newSampler builds the sampler chain described by the sampling configuration
+func newSampler(cfg SamplingConfig) (trace.Sampler, error) {
+	var root trace.Sampler
+	strategy := strings.ToLower(cfg.Strategy)
+
+	switch strings.TrimPrefix(strategy, "parentbased_") {
+	case "always_on", "":
+		root = trace.AlwaysSample()
+	case "always_off":
+		root = trace.NeverSample()
+	case "traceidratio":
+		if cfg.Ratio < 0 || cfg.Ratio > 1 {
+			return nil, fmt.Errorf("sampler ratio must be between 0 and 1, got %v", cfg.Ratio)
+		}
+		root = trace.TraceIDRatioBased(cfg.Ratio)
+	default:
+		return nil, fmt.Errorf("unknown sampler %q", cfg.Strategy)
+	}
+
+	if cfg.RateLimit > 0 {
+		root = newRateLimitSampler(root, cfg.RateLimit)
+	}
+
+	sampler := root
+	if strings.HasPrefix(strategy, "parentbased_") {
+		sampler = trace.ParentBased(root)
+	}
+
+	if cfg.KeepErrors {
+		sampler = recordOnlySampler{next: sampler}
+	}
+
+	if len(cfg.NeverPaths) > 0 || len(cfg.AlwaysPaths) > 0 {
+		sampler = newRouteSampler(sampler, cfg.NeverPaths, cfg.AlwaysPaths)
+	}
+
+	return sampler, nil
+}
+
+// routeSampler applies per-path sampling rules before delegating to the next sampler
+type routeSampler struct {
+	next   trace.Sampler
+	never  map[string]struct{}
+	always map[string]struct{}
+}
+
+func newRouteSampler(next trace.Sampler, never, always []string) *routeSampler {
+	s := &routeSampler{
+		next:   next,
+		never:  make(map[string]struct{}, len(never)),
+		always: make(map[string]struct{}, len(always)),
+	}
+	for _, path := range never {
+		s.never[path] = struct{}{}
+	}
+	for _, path := range always {
+		s.always[path] = struct{}{}
+	}
+	return s
+}
+
+func (s *routeSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if path, ok := targetPath(p.Attributes); ok {
+		psc := oteltrace.SpanContextFromContext(p.ParentContext)
+		if _, ok := s.never[path]; ok {
+			return trace.SamplingResult{Decision: trace.Drop, Tracestate: psc.TraceState()}
+		}
+		if _, ok := s.always[path]; ok {
+			return trace.SamplingResult{Decision: trace.RecordAndSample, Tracestate: psc.TraceState()}
+		}
+	}
+	return s.next.ShouldSample(p)
+}
+
+func (s *routeSampler) Description() string {
+	return fmt.Sprintf("RouteSampler{never:%d,always:%d,%s}", len(s.never), len(s.always), s.next.Description())
+}
+
+// targetPath returns the request path recorded on a server span at start
+func targetPath(attrs []attribute.KeyValue) (string, bool) {
+	for _, attr := range attrs {
+		if attr.Key == "http.target" {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// rateLimitSampler caps sampled decisions of the next sampler using a token bucket
+type rateLimitSampler struct {
+	next trace.Sampler
+	rate float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newRateLimitSampler(next trace.Sampler, perSecond float64) *rateLimitSampler {
+	return &rateLimitSampler{
+		next:   next,
+		rate:   perSecond,
+		tokens: perSecond,
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+func (s *rateLimitSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	result := s.next.ShouldSample(p)
+	if result.Decision != trace.RecordAndSample {
+		return result
+	}
+
+	if !s.take() {
+		result.Decision = trace.Drop
+	}
+	return result
+}
+
+// take refills the bucket for the elapsed time and consumes one token if available
+func (s *rateLimitSampler) take() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.tokens += now.Sub(s.last).Seconds() * s.rate
+	if s.tokens > s.rate {
+		s.tokens = s.rate
+	}
+	s.last = now
+
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+func (s *rateLimitSampler) Description() string {
+	return fmt.Sprintf("RateLimitSampler{%g/s,%s}", s.rate, s.next.Description())
+}
+
+// recordOnlySampler turns dropped spans into recorded-but-unsampled spans so
+// the tail sampler can still export the trace if it ends in an error
+type recordOnlySampler struct {
+	next trace.Sampler
+}
+
+func (s recordOnlySampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	result := s.next.ShouldSample(p)
+	if result.Decision != trace.Drop {
+		return result
+	}
+
+	// A local parent that was not recorded was dropped on purpose, keep it that way
+	parent := oteltrace.SpanFromContext(p.ParentContext)
+	if parent.SpanContext().IsValid() && !parent.SpanContext().IsRemote() && !parent.IsRecording() {
+		return result
+	}
+
+	result.Decision = trace.RecordOnly
+	return result
+}
+
+func (s recordOnlySampler) Description() string {
+	return fmt.Sprintf("RecordOnly{%s}", s.next.Description())
+}
+
+// tailProcessor buffers recorded-but-unsampled spans per trace and forwards
+// them to the next processor when the local root ends if any span errored
+type tailProcessor struct {
+	next trace.SpanProcessor
+
+	mu     sync.Mutex
+	traces map[oteltrace.TraceID]*tailTrace
+}
+
+type tailTrace struct {
+	spans   []trace.ReadOnlySpan
+	errored bool
+}
+
+func newTailProcessor(next trace.SpanProcessor) *tailProcessor {
+	return &tailProcessor{
+		next:   next,
+		traces: make(map[oteltrace.TraceID]*tailTrace),
+	}
+}
+
+func (p *tailProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *tailProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.next.OnEnd(s)
+		return
+	}
+
+	spans, errored := p.buffer(s)
+	if !errored {
+		return
+	}
+	for _, span := range spans {
+		p.next.OnEnd(sampledSpan{ReadOnlySpan: span})
+	}
+}
+
+// buffer records the span and, when it is the local root, returns the
+// buffered trace and whether it should be exported
+func (p *tailProcessor) buffer(s trace.ReadOnlySpan) ([]trace.ReadOnlySpan, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	traceID := s.SpanContext().TraceID()
+	t, ok := p.traces[traceID]
+	if !ok {
+		if len(p.traces) >= maxBufferedTraces {
+			return nil, false
+		}
+		t = &tailTrace{}
+		p.traces[traceID] = t
+	}
+
+	if len(t.spans) < maxBufferedSpans {
+		t.spans = append(t.spans, s)
+	}
+	if s.Status().Code == codes.Error {
+		t.errored = true
+	}
+
+	parent := s.Parent()
+	if parent.IsValid() && !parent.IsRemote() {
+		return nil, false
+	}
+
+	delete(p.traces, traceID)
+	return t.spans, t.errored
+}
+
+func (p *tailProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *tailProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampledSpan marks a buffered span as sampled so exporters accept it
+type sampledSpan struct {
+	trace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() oteltrace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
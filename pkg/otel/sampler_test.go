@@ -0,0 +1,123 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func setupTestTracer(t *testing.T, cfg SamplingConfig) (oteltrace.Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		t.Fatalf("failed to create sampler: %v", err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	var processor trace.SpanProcessor = trace.NewSimpleSpanProcessor(exporter)
+	if cfg.KeepErrors {
+		processor = newTailProcessor(processor)
+	}
+
+	provider := trace.NewTracerProvider(
+		trace.WithSpanProcessor(processor),
+		trace.WithSampler(sampler),
+	)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	return provider.Tracer("test"), exporter
+}
+
+func TestNewSampler_UnknownStrategy(t *testing.T) {
+	if _, err := newSampler(SamplingConfig{Strategy: "sometimes"}); err == nil {
+		t.Error("expected error for unknown strategy, got nil")
+	}
+}
+
+func TestSampler_NeverPaths(t *testing.T) {
+	tracer, exporter := setupTestTracer(t, SamplingConfig{
+		Strategy:   "always_on",
+		NeverPaths: []string{"/health"},
+	})
+
+	_, span := tracer.Start(context.Background(), "GET /health",
+		oteltrace.WithAttributes(attribute.String("http.target", "/health")),
+	)
+	span.SetStatus(codes.Error, "unhealthy")
+	span.End()
+
+	_, span = tracer.Start(context.Background(), "GET /api/example",
+		oteltrace.WithAttributes(attribute.String("http.target", "/api/example")),
+	)
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "GET /api/example" {
+		t.Errorf("expected only the example span to be exported, got %v", spans.Snapshots())
+	}
+}
+
+func TestSampler_KeepErrors(t *testing.T) {
+	tracer, exporter := setupTestTracer(t, SamplingConfig{
+		Strategy:   "parentbased_always_off",
+		KeepErrors: true,
+	})
+
+	// A successful unsampled trace is discarded
+	ctx, root := tracer.Start(context.Background(), "ok")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	root.End()
+
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Fatalf("expected no exported spans, got %d", n)
+	}
+
+	// An errored unsampled trace is exported in full
+	ctx, root = tracer.Start(context.Background(), "failed")
+	_, child = tracer.Start(ctx, "child")
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	root.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 exported spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		if !span.SpanContext.IsSampled() {
+			t.Errorf("expected span %s to be marked sampled", span.Name)
+		}
+	}
+}
+
+func TestRateLimitSampler(t *testing.T) {
+	now := time.Now()
+	sampler := newRateLimitSampler(trace.AlwaysSample(), 2)
+	sampler.now = func() time.Time { return now }
+	sampler.last = now
+
+	params := trace.SamplingParameters{ParentContext: context.Background()}
+
+	sampled := 0
+	for range 5 {
+		if sampler.ShouldSample(params).Decision == trace.RecordAndSample {
+			sampled++
+		}
+	}
+	if sampled != 2 {
+		t.Errorf("expected 2 sampled spans within the first second, got %d", sampled)
+	}
+
+	now = now.Add(time.Second)
+	if sampler.ShouldSample(params).Decision != trace.RecordAndSample {
+		t.Error("expected bucket to refill after one second")
+	}
+}
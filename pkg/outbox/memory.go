@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/cache"
+)
+
+// Handler consumes a message; returning an error makes the relay deliver it again
+type Handler func(ctx context.Context, msg Message) error
+
+// Memory is an in-process broker calling subscribers synchronously
+type Memory struct {
+	mu   sync.RWMutex
+	subs map[string][]Handler
+}
+
+// NewMemory creates an in-process broker
+func NewMemory() *Memory {
+	return &Memory{subs: make(map[string][]Handler)}
+}
+
+// Subscribe calls h for each message published to topic
+func (m *Memory) Subscribe(topic string, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subs[topic] = append(m.subs[topic], h)
+}
+
+// Publish calls every subscriber of the message's topic
+// All subscribers run; a failure of any of them is returned so the message is
+// delivered again, which is why subscribers should be wrapped in Deduplicate
+func (m *Memory) Publish(ctx context.Context, msg Message) error {
+	m.mu.RLock()
+	subs := m.subs[msg.Topic]
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, h := range subs {
+		if err := h(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to deliver message %s: %w", msg.ID, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Deduplicate wraps h so a message ID seen within ttl is acknowledged without
+// calling h again; IDs are recorded in store only after h succeeds
+func Deduplicate(store cache.Store, ttl time.Duration, h Handler) Handler {
+	return func(ctx context.Context, msg Message) error {
+		if _, seen, err := store.Get(ctx, msg.ID); err == nil && seen {
+			return nil
+		}
+		if err := h(ctx, msg); err != nil {
+			return err
+		}
+		// A lost record only means a possible duplicate delivery
+		_ = store.Set(ctx, msg.ID, []byte{1}, ttl)
+		return nil
+	}
+}
@@ -0,0 +1,253 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// natsPublish is a message received by the stand-in server
+type natsPublish struct {
+	subject string
+	headers map[string]string
+	payload string
+}
+
+// fakeNATS is a local stand-in speaking the subset of the NATS client protocol
+// the adapter uses; with jetStream set it acknowledges publishes like a stream,
+// reporting duplicates by Nats-Msg-Id
+type fakeNATS struct {
+	t         *testing.T
+	ln        net.Listener
+	jetStream bool
+
+	mu       sync.Mutex
+	received []natsPublish
+	seen     map[string]bool
+	conns    int
+	// dropNext closes the connection instead of handling the next publish
+	dropNext bool
+	connect  map[string]any
+}
+
+func newFakeNATS(t *testing.T, jetStream bool) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeNATS{t: t, ln: ln, jetStream: jetStream, seen: make(map[string]bool)}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeNATS) url() string {
+	return "nats://relay:secret@" + s.ln.Addr().String()
+}
+
+func (s *fakeNATS) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	send := func(format string, args ...any) {
+		fmt.Fprintf(w, format, args...)
+		_ = w.Flush()
+	}
+
+	send("INFO {\"server_id\":\"fake\",\"headers\":true,\"max_payload\":1048576}\r\n")
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(line, " ")
+		switch op {
+		case "CONNECT":
+			var opts map[string]any
+			_ = json.Unmarshal([]byte(args), &opts)
+			s.mu.Lock()
+			s.connect = opts
+			s.mu.Unlock()
+		case "PING":
+			send("PONG\r\n")
+		case "SUB":
+		case "HPUB":
+			fields := strings.Fields(args)
+			headerLen, _ := strconv.Atoi(fields[len(fields)-2])
+			total, _ := strconv.Atoi(fields[len(fields)-1])
+			body := make([]byte, total+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			if s.dropNext {
+				s.dropNext = false
+				s.mu.Unlock()
+				return
+			}
+			msg := natsPublish{subject: fields[0], headers: map[string]string{}, payload: string(body[headerLen:total])}
+			for _, h := range strings.Split(string(body[:headerLen]), "\r\n")[1:] {
+				if key, value, ok := strings.Cut(h, ": "); ok {
+					msg.headers[key] = value
+				}
+			}
+			duplicate := s.seen[msg.headers["Nats-Msg-Id"]]
+			s.seen[msg.headers["Nats-Msg-Id"]] = true
+			if !duplicate || !s.jetStream {
+				s.received = append(s.received, msg)
+			}
+			seq := len(s.received)
+			s.mu.Unlock()
+
+			if len(fields) == 4 {
+				ack := fmt.Sprintf(`{"stream":"EVENTS","seq":%d,"duplicate":%t}`, seq, duplicate)
+				send("MSG %s 1 %d\r\n%s\r\n", fields[1], len(ack), ack)
+			}
+		}
+	}
+}
+
+func (s *fakeNATS) messages() []natsPublish {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]natsPublish(nil), s.received...)
+}
+
+func TestNATS_PublishesWithHeaders(t *testing.T) {
+	server := newFakeNATS(t, false)
+	broker, err := NewNATS(NATSConfig{URL: server.url(), SubjectPrefix: "events.", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	defer func() { _ = broker.Close() }()
+
+	msg := Message{
+		ID:      "msg-1",
+		Topic:   "examples",
+		Type:    "example.created",
+		Key:     "42",
+		Payload: json.RawMessage(`{"id":"42"}`),
+		Headers: map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+	}
+	if err := broker.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	received := server.messages()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(received))
+	}
+	got := received[0]
+	if got.subject != "events.examples" {
+		t.Errorf("expected subject events.examples, got %s", got.subject)
+	}
+	if got.payload != `{"id":"42"}` {
+		t.Errorf("unexpected payload %s", got.payload)
+	}
+	for key, want := range map[string]string{
+		"Nats-Msg-Id": "msg-1",
+		"Event-Type":  "example.created",
+		"Event-Key":   "42",
+		"traceparent": msg.Headers["traceparent"],
+	} {
+		if got.headers[key] != want {
+			t.Errorf("expected header %s=%s, got %q", key, want, got.headers[key])
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.connect["user"] != "relay" || server.connect["pass"] != "secret" || server.connect["headers"] != true {
+		t.Errorf("unexpected CONNECT options %v", server.connect)
+	}
+}
+
+func TestNATS_JetStreamDeduplicatesRedelivery(t *testing.T) {
+	server := newFakeNATS(t, true)
+	broker, err := NewNATS(NATSConfig{URL: server.url(), JetStream: true, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	defer func() { _ = broker.Close() }()
+
+	msg := Message{ID: "msg-1", Topic: "examples", Payload: json.RawMessage(`{}`)}
+	for range 2 {
+		if err := broker.Publish(context.Background(), msg); err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+	}
+
+	if n := len(server.messages()); n != 1 {
+		t.Errorf("expected the stream to keep 1 message, got %d", n)
+	}
+}
+
+func TestNATS_ReconnectsAfterFailure(t *testing.T) {
+	server := newFakeNATS(t, true)
+	broker, err := NewNATS(NATSConfig{URL: server.url(), JetStream: true, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	defer func() { _ = broker.Close() }()
+
+	server.mu.Lock()
+	server.dropNext = true
+	server.mu.Unlock()
+
+	msg := Message{ID: "msg-1", Topic: "examples", Payload: json.RawMessage(`{}`)}
+	if err := broker.Publish(context.Background(), msg); err == nil {
+		t.Fatal("expected the dropped connection to fail the publish")
+	}
+	if err := broker.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("expected the retry to reconnect, got %v", err)
+	}
+
+	server.mu.Lock()
+	conns := server.conns
+	server.mu.Unlock()
+	if conns != 2 {
+		t.Errorf("expected 2 connections, got %d", conns)
+	}
+	if n := len(server.messages()); n != 1 {
+		t.Errorf("expected 1 message, got %d", n)
+	}
+}
+
+func TestNATS_UnreachableServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	broker, err := NewNATS(NATSConfig{URL: "nats://" + addr, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	if err := broker.Publish(context.Background(), Message{ID: "msg-1", Topic: "examples"}); err == nil {
+		t.Error("expected an error for an unreachable server")
+	}
+}
@@ -0,0 +1,98 @@
+// Package outbox relays messages recorded in a transactional outbox to a broker
+// with at-least-once delivery; consumers deduplicate by message ID
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// Message is an event recorded in the outbox
+type Message struct {
+	// ID is unique per message and is the deduplication key for consumers
+	ID    string `json:"id"`
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+	// Key identifies the entity the event is about, e.g. for partitioning
+	Key     string          `json:"key"`
+	Payload json.RawMessage `json:"payload"`
+	// Headers carry the trace context and request ID of the change
+	Headers   map[string]string `json:"headers,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	// Attempts counts failed publishes; it is kept by the store and not published
+	Attempts int `json:"-"`
+}
+
+// headerRequestID is the header holding the request ID of the change
+const headerRequestID = "request_id"
+
+// NewMessage creates a message with a JSON-encoded payload, recording the
+// trace context and request ID of ctx
+func NewMessage(ctx context.Context, topic, eventType, key string, payload any) (Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to encode message payload: %w", err)
+	}
+
+	msg := Message{
+		ID:        rand.Text(),
+		Topic:     topic,
+		Type:      eventType,
+		Key:       key,
+		Payload:   data,
+		Headers:   map[string]string{},
+		CreatedAt: time.Now().UTC(),
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Headers))
+	if id := requestid.FromContext(ctx); id != "" {
+		msg.Headers[headerRequestID] = id
+	}
+	return msg, nil
+}
+
+// Store reads the outbox written alongside data changes
+type Store interface {
+	// Pending returns up to limit unpublished messages, oldest first
+	Pending(ctx context.Context, limit int) ([]Message, error)
+	// MarkPublished records messages as delivered to the broker
+	MarkPublished(ctx context.Context, ids []string) error
+	// MarkFailed counts a failed publish of a message; a parked message is no
+	// longer pending, so it stops holding back the messages after it
+	MarkFailed(ctx context.Context, id string, park bool) error
+}
+
+// Broker delivers messages to consumers
+type Broker interface {
+	// Publish delivers msg, returning once the broker has accepted it
+	Publish(ctx context.Context, msg Message) error
+}
+
+// fanout publishes to several brokers
+type fanout []Broker
+
+// Fanout returns a broker publishing each message to every broker in order
+// Every broker is tried even if an earlier one fails, so one failing broker
+// does not hold the others back; brokers that succeeded see the message again
+// when it is redelivered
+func Fanout(brokers ...Broker) Broker {
+	return fanout(brokers)
+}
+
+func (f fanout) Publish(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, b := range f {
+		if err := b.Publish(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
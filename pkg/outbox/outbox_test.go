@@ -0,0 +1,250 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/cache"
+	"github.com/ahxar/go-backend-service/pkg/requestid"
+)
+
+// memoryStore is an outbox held in a slice
+type memoryStore struct {
+	mu        sync.Mutex
+	msgs      []Message
+	published map[string]bool
+	attempts  map[string]int
+	parked    map[string]bool
+}
+
+func newMemoryStore(msgs ...Message) *memoryStore {
+	return &memoryStore{
+		msgs:      msgs,
+		published: make(map[string]bool),
+		attempts:  make(map[string]int),
+		parked:    make(map[string]bool),
+	}
+}
+
+func (s *memoryStore) Pending(_ context.Context, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Message
+	for _, msg := range s.msgs {
+		if !s.published[msg.ID] && !s.parked[msg.ID] && len(out) < limit {
+			msg.Attempts = s.attempts[msg.ID]
+			out = append(out, msg)
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) MarkFailed(_ context.Context, id string, park bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[id]++
+	if park {
+		s.parked[id] = true
+	}
+	return nil
+}
+
+func (s *memoryStore) MarkPublished(_ context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.published[id] = true
+	}
+	return nil
+}
+
+// recordingBroker records published IDs and fails while failing is set
+type recordingBroker struct {
+	mu      sync.Mutex
+	ids     []string
+	failOn  string
+	failing bool
+}
+
+func (b *recordingBroker) Publish(_ context.Context, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failing && msg.ID == b.failOn {
+		return errors.New("broker unavailable")
+	}
+	b.ids = append(b.ids, msg.ID)
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func messages(ids ...string) []Message {
+	msgs := make([]Message, len(ids))
+	for i, id := range ids {
+		msgs[i] = Message{ID: id, Topic: "examples", Type: "example.created"}
+	}
+	return msgs
+}
+
+func TestNewMessage_RecordsRequestID(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	msg, err := NewMessage(ctx, "examples", "example.created", "42", map[string]string{"id": "42"})
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	if msg.ID == "" {
+		t.Error("expected a message ID")
+	}
+	if string(msg.Payload) != `{"id":"42"}` {
+		t.Errorf("unexpected payload %s", msg.Payload)
+	}
+	if msg.Headers["request_id"] != "req-1" {
+		t.Errorf("expected the request ID header, got %v", msg.Headers)
+	}
+}
+
+func TestRelay_PublishesInOrderAndRedeliversAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore(messages("a", "b", "c")...)
+	broker := &recordingBroker{failOn: "b", failing: true}
+	relay := NewRelay(store, broker, testLogger(), RelayConfig{BatchSize: 10})
+
+	n, err := relay.RelayOnce(ctx)
+	if err == nil {
+		t.Fatal("expected the broker failure to be returned")
+	}
+	if n != 1 {
+		t.Errorf("expected 1 message published before the failure, got %d", n)
+	}
+	// c must not overtake b
+	if !slices.Equal(broker.ids, []string{"a"}) {
+		t.Errorf("expected only a to be published, got %v", broker.ids)
+	}
+
+	broker.failing = false
+	if n, err := relay.RelayOnce(ctx); err != nil || n != 2 {
+		t.Fatalf("expected 2 messages published, got %d: %v", n, err)
+	}
+	if !slices.Equal(broker.ids, []string{"a", "b", "c"}) {
+		t.Errorf("expected messages in order, got %v", broker.ids)
+	}
+
+	if n, _ := relay.RelayOnce(ctx); n != 0 {
+		t.Errorf("expected nothing left to publish, got %d", n)
+	}
+}
+
+func TestRelay_ParksPoisonMessages(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore(messages("a", "b", "c")...)
+	broker := &recordingBroker{failOn: "b", failing: true}
+	relay := NewRelay(store, broker, testLogger(), RelayConfig{BatchSize: 10, MaxAttempts: 2})
+
+	// The first failure still holds back c
+	if _, err := relay.RelayOnce(ctx); err == nil {
+		t.Fatal("expected the broker failure to be returned")
+	}
+	if !slices.Equal(broker.ids, []string{"a"}) {
+		t.Errorf("expected only a to be published, got %v", broker.ids)
+	}
+
+	// The last allowed failure parks b and lets c through
+	n, err := relay.RelayOnce(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 message published after parking, got %d: %v", n, err)
+	}
+	if !slices.Equal(broker.ids, []string{"a", "c"}) {
+		t.Errorf("expected c to overtake the parked message, got %v", broker.ids)
+	}
+	if !store.parked["b"] || store.attempts["b"] != 2 {
+		t.Errorf("expected b parked after 2 attempts, got parked=%v attempts=%d", store.parked["b"], store.attempts["b"])
+	}
+
+	if pending, _ := store.Pending(ctx, 10); len(pending) != 0 {
+		t.Errorf("expected nothing pending, got %v", pending)
+	}
+}
+
+func TestRelay_StartAndStop(t *testing.T) {
+	store := newMemoryStore(messages("a", "b", "c")...)
+	broker := &recordingBroker{}
+	relay := NewRelay(store, broker, testLogger(), RelayConfig{PollInterval: 10 * time.Millisecond, BatchSize: 2})
+	relay.Start()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		broker.mu.Lock()
+		n := len(broker.ids)
+		broker.mu.Unlock()
+		if n == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 messages to be relayed, got %d", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := relay.Stop(ctx); err != nil {
+		t.Errorf("expected a clean stop, got %v", err)
+	}
+}
+
+func TestMemory_DeduplicatedRedelivery(t *testing.T) {
+	ctx := context.Background()
+	bus := NewMemory()
+
+	var calls int
+	bus.Subscribe("examples", Deduplicate(cache.NewMemory(10), time.Hour, func(context.Context, Message) error {
+		calls++
+		return nil
+	}))
+	failing := true
+	bus.Subscribe("examples", func(context.Context, Message) error {
+		if failing {
+			return errors.New("consumer down")
+		}
+		return nil
+	})
+
+	msg := messages("a")[0]
+	if err := bus.Publish(ctx, msg); err == nil {
+		t.Fatal("expected the failing subscriber's error")
+	}
+
+	// The relay delivers the message again; the first subscriber already handled it
+	failing = false
+	if err := bus.Publish(ctx, msg); err != nil {
+		t.Fatalf("expected redelivery to succeed, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the deduplicated handler to run once, got %d", calls)
+	}
+
+	if err := bus.Publish(ctx, Message{ID: "other", Topic: "other"}); err != nil {
+		t.Errorf("expected a topic without subscribers to succeed, got %v", err)
+	}
+}
+
+func TestFanout_PublishesToEveryBroker(t *testing.T) {
+	first := &recordingBroker{failOn: "a", failing: true}
+	second := &recordingBroker{}
+
+	if err := Fanout(first, second).Publish(context.Background(), messages("a")[0]); err == nil {
+		t.Fatal("expected the failure to be returned")
+	}
+	if len(second.ids) != 1 {
+		t.Errorf("expected the second broker to receive the message despite the first failing, got %v", second.ids)
+	}
+}
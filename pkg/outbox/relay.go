@@ -0,0 +1,204 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans and instruments created by this package
+const instrumentationName = "github.com/ahxar/go-backend-service/pkg/outbox"
+
+// RelayConfig holds relay configuration
+type RelayConfig struct {
+	// PollInterval is how long the relay waits after finding no pending messages
+	PollInterval time.Duration
+	// BatchSize is the number of messages read per poll
+	BatchSize int
+	// MaxAttempts is how many times a message may fail to publish before it is
+	// parked, so one undeliverable message cannot block the outbox forever
+	MaxAttempts int
+}
+
+// Relay publishes pending outbox messages to a broker in order
+// A message is marked published only after the broker accepts it, so a crash
+// or broker failure leads to redelivery rather than loss
+// A message failing RelayConfig.MaxAttempts times is parked and skipped, which
+// lets later messages overtake it
+type Relay struct {
+	store     Store
+	broker    Broker
+	logger    *slog.Logger
+	cfg       RelayConfig
+	tracer    trace.Tracer
+	published metric.Int64Counter
+
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// NewRelay creates a relay from store to broker
+func NewRelay(store Store, broker Broker, logger *slog.Logger, cfg RelayConfig) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+
+	published, err := otel.Meter(instrumentationName).Int64Counter("outbox.published",
+		metric.WithDescription("Outbox messages relayed to the broker by topic and result"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &Relay{
+		store:     store,
+		broker:    broker,
+		logger:    logger,
+		cfg:       cfg,
+		tracer:    otel.Tracer(instrumentationName),
+		published: published,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start begins relaying in the background
+func (r *Relay) Start() {
+	go func() {
+		defer close(r.done)
+		r.loop()
+	}()
+}
+
+// Stop ends relaying after the batch in progress
+func (r *Relay) Stop(ctx context.Context) error {
+	r.once.Do(func() { close(r.stop) })
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop outbox relay: %w", ctx.Err())
+	}
+}
+
+func (r *Relay) loop() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-timer.C:
+		}
+
+		// Keep going while batches come back full
+		n, err := r.RelayOnce(context.Background())
+		if err != nil {
+			r.logger.Error("failed to relay outbox",
+				slog.String("error", err.Error()),
+			)
+		}
+		if err == nil && n == r.cfg.BatchSize {
+			timer.Reset(0)
+			continue
+		}
+		timer.Reset(r.cfg.PollInterval)
+	}
+}
+
+// RelayOnce publishes one batch of pending messages and returns how many were published
+// Publishing stops at the first failure so later messages never overtake
+// earlier ones, unless the failing message is parked
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	msgs, err := r.store.Pending(ctx, r.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	var published []string
+	var publishErr error
+	for _, msg := range msgs {
+		err := r.publish(ctx, msg)
+		if err == nil {
+			published = append(published, msg.ID)
+			continue
+		}
+
+		park := msg.Attempts+1 >= r.cfg.MaxAttempts
+		if markErr := r.store.MarkFailed(ctx, msg.ID, park); markErr != nil {
+			publishErr = errors.Join(err, fmt.Errorf("failed to record outbox failure: %w", markErr))
+			break
+		}
+		if !park {
+			publishErr = err
+			break
+		}
+		r.logger.ErrorContext(ctx, "parked undeliverable outbox message",
+			slog.String("id", msg.ID),
+			slog.String("topic", msg.Topic),
+			slog.Int("attempts", msg.Attempts+1),
+			slog.String("error", err.Error()),
+		)
+		r.published.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("topic", msg.Topic),
+			attribute.String("result", "parked"),
+		))
+	}
+
+	if len(published) > 0 {
+		if err := r.store.MarkPublished(ctx, published); err != nil {
+			return 0, fmt.Errorf("failed to mark outbox messages published: %w", err)
+		}
+	}
+	if publishErr != nil {
+		return len(published), publishErr
+	}
+	return len(published), nil
+}
+
+// publish sends one message in a producer span continuing the change's trace
+func (r *Relay) publish(ctx context.Context, msg Message) error {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Headers))
+	ctx, span := r.tracer.Start(ctx, "outbox.publish "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.String("messaging.message.id", msg.ID),
+			attribute.String("event.type", msg.Type),
+		),
+	)
+	defer span.End()
+
+	result := "success"
+	err := r.broker.Publish(ctx, msg)
+	if err != nil {
+		result = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		err = fmt.Errorf("failed to publish message %s: %w", msg.ID, err)
+	}
+	r.published.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("topic", msg.Topic),
+		attribute.String("result", result),
+	))
+	return err
+}
@@ -0,0 +1,16 @@
+package profiling
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// Do runs fn with pprof labels for the route, method and trace so CPU and
+// goroutine profiles can be broken down per request
+func Do(ctx context.Context, route, method, traceID string, fn func(context.Context)) {
+	labels := []string{"http.route", route, "http.method", method}
+	if traceID != "" {
+		labels = append(labels, "trace_id", traceID)
+	}
+	pprof.Do(ctx, pprof.Labels(labels...), fn)
+}
@@ -0,0 +1,223 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/metrics"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// Profile types
+const (
+	TypeCPU       = "cpu"
+	TypeHeap      = "heap"
+	TypeGoroutine = "goroutine"
+)
+
+// Capture reasons
+const (
+	ReasonPeriodic = "periodic"
+	ReasonLatency  = "latency"
+	ReasonMemory   = "memory"
+)
+
+// memoryCheckInterval is how often the heap size is compared to the threshold
+const memoryCheckInterval = 5 * time.Second
+
+// Profile is a captured profile in pprof format
+type Profile struct {
+	Type   string
+	Reason string
+	Time   time.Time
+	Data   []byte
+}
+
+// Config holds continuous profiling configuration
+type Config struct {
+	// Interval between periodic captures; zero disables periodic capture
+	Interval time.Duration
+	// CPUDuration is how long each CPU profile records
+	CPUDuration time.Duration
+	// Types lists the profiles to capture: cpu, heap, goroutine
+	Types []string
+	// LatencyThreshold triggers a capture when a request takes longer; zero disables it
+	LatencyThreshold time.Duration
+	// MemoryThreshold triggers a capture when live heap bytes exceed it; zero disables it
+	MemoryThreshold uint64
+	// Cooldown is the minimum time between triggered captures
+	Cooldown time.Duration
+}
+
+// Profiler periodically captures profiles and writes them to a sink
+type Profiler struct {
+	cfg    Config
+	sink   Sink
+	logger *slog.Logger
+
+	trigger chan string
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu            sync.Mutex
+	lastTriggered time.Time
+}
+
+// New creates a profiler; call Start to begin capturing
+func New(cfg Config, sink Sink, logger *slog.Logger) *Profiler {
+	return &Profiler{
+		cfg:     cfg,
+		sink:    sink,
+		logger:  logger,
+		trigger: make(chan string, 1),
+	}
+}
+
+// Start runs the capture loop in the background
+func (p *Profiler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go p.run(ctx)
+
+	p.logger.Info("continuous profiling started",
+		slog.Duration("interval", p.cfg.Interval),
+		slog.Any("types", p.cfg.Types),
+	)
+}
+
+// Stop ends the capture loop, aborting an in-progress capture
+func (p *Profiler) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// ObserveLatency triggers a capture when d exceeds the latency threshold
+func (p *Profiler) ObserveLatency(d time.Duration) {
+	if p.cfg.LatencyThreshold > 0 && d > p.cfg.LatencyThreshold {
+		p.fire(ReasonLatency)
+	}
+}
+
+// fire requests a triggered capture unless one is pending or in cooldown
+func (p *Profiler) fire(reason string) {
+	p.mu.Lock()
+	if time.Since(p.lastTriggered) < p.cfg.Cooldown {
+		p.mu.Unlock()
+		return
+	}
+	p.lastTriggered = time.Now()
+	p.mu.Unlock()
+
+	select {
+	case p.trigger <- reason:
+	default:
+	}
+}
+
+func (p *Profiler) run(ctx context.Context) {
+	defer close(p.done)
+
+	var periodic <-chan time.Time
+	if p.cfg.Interval > 0 {
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+		periodic = ticker.C
+	}
+
+	var memory <-chan time.Time
+	if p.cfg.MemoryThreshold > 0 {
+		ticker := time.NewTicker(memoryCheckInterval)
+		defer ticker.Stop()
+		memory = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-periodic:
+			p.capture(ctx, ReasonPeriodic)
+		case <-memory:
+			if heapBytes() > p.cfg.MemoryThreshold {
+				p.fire(ReasonMemory)
+			}
+		case reason := <-p.trigger:
+			p.logger.Warn("capturing profiles on threshold breach",
+				slog.String("reason", reason),
+			)
+			p.capture(ctx, reason)
+		}
+	}
+}
+
+// capture records each configured profile type and writes it to the sink
+func (p *Profiler) capture(ctx context.Context, reason string) {
+	for _, typ := range p.cfg.Types {
+		data, err := p.collect(ctx, typ)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.logger.Error("failed to capture profile",
+					slog.String("type", typ),
+					slog.String("error", err.Error()),
+				)
+			}
+			continue
+		}
+
+		profile := Profile{Type: typ, Reason: reason, Time: time.Now(), Data: data}
+		if err := p.sink.Write(ctx, profile); err != nil {
+			p.logger.Error("failed to write profile",
+				slog.String("type", typ),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// collect records a single profile in gzipped protobuf format
+func (p *Profiler) collect(ctx context.Context, typ string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch typ {
+	case TypeCPU:
+		// Fails if another CPU profile, e.g. from /debug/pprof, is running
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		timer := time.NewTimer(p.cfg.CPUDuration)
+		select {
+		case <-timer.C:
+			pprof.StopCPUProfile()
+		case <-ctx.Done():
+			timer.Stop()
+			pprof.StopCPUProfile()
+			return nil, ctx.Err()
+		}
+	case TypeHeap, TypeGoroutine:
+		if err := pprof.Lookup(typ).WriteTo(&buf, 0); err != nil {
+			return nil, fmt.Errorf("failed to write %s profile: %w", typ, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown profile type %q", typ)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// heapBytes returns the memory occupied by live and unswept heap objects
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
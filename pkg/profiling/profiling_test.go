@@ -0,0 +1,161 @@
+package profiling
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime/pprof"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySink collects profiles for assertions
+type memorySink struct {
+	mu       sync.Mutex
+	profiles []Profile
+	written  chan struct{}
+}
+
+func newMemorySink() *memorySink {
+	return &memorySink{written: make(chan struct{}, 16)}
+}
+
+func (s *memorySink) Write(_ context.Context, p Profile) error {
+	s.mu.Lock()
+	s.profiles = append(s.profiles, p)
+	s.mu.Unlock()
+	s.written <- struct{}{}
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestProfiler_LatencyTrigger(t *testing.T) {
+	sink := newMemorySink()
+	p := New(Config{
+		Types:            []string{TypeHeap},
+		LatencyThreshold: 100 * time.Millisecond,
+		Cooldown:         time.Hour,
+	}, sink, testLogger())
+	p.Start()
+	defer p.Stop()
+
+	p.ObserveLatency(10 * time.Millisecond)
+	p.ObserveLatency(time.Second)
+	// Within the cooldown, so no second capture
+	p.ObserveLatency(time.Second)
+
+	select {
+	case <-sink.written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a profile to be captured")
+	}
+
+	p.Stop()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.profiles) != 1 {
+		t.Fatalf("expected 1 profile, got %d", len(sink.profiles))
+	}
+	if sink.profiles[0].Reason != ReasonLatency || sink.profiles[0].Type != TypeHeap {
+		t.Errorf("expected latency heap profile, got %s %s", sink.profiles[0].Reason, sink.profiles[0].Type)
+	}
+	if len(sink.profiles[0].Data) == 0 {
+		t.Error("expected profile data")
+	}
+}
+
+func TestProfiler_Periodic(t *testing.T) {
+	sink := newMemorySink()
+	p := New(Config{
+		Interval:    10 * time.Millisecond,
+		CPUDuration: 10 * time.Millisecond,
+		Types:       []string{TypeCPU, TypeGoroutine},
+	}, sink, testLogger())
+	p.Start()
+
+	for range 2 {
+		select {
+		case <-sink.written:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected periodic profiles to be captured")
+		}
+	}
+	p.Stop()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.profiles[0].Type != TypeCPU || sink.profiles[1].Type != TypeGoroutine {
+		t.Errorf("expected cpu then goroutine profiles, got %s and %s", sink.profiles[0].Type, sink.profiles[1].Type)
+	}
+	if sink.profiles[0].Reason != ReasonPeriodic {
+		t.Errorf("expected periodic reason, got %s", sink.profiles[0].Reason)
+	}
+}
+
+func TestDirSink_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewDirSink(dir, 2)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		p := Profile{Type: TypeHeap, Reason: ReasonPeriodic, Time: start.Add(time.Duration(i) * time.Second), Data: []byte{byte(i)}}
+		if err := sink.Write(context.Background(), p); err != nil {
+			t.Fatalf("failed to write profile: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 profiles after rotation, got %d", len(entries))
+	}
+	if entries[0].Name() != "20260101T000001.000Z-periodic-heap.pprof" {
+		t.Errorf("expected oldest profile to be removed, got %s", entries[0].Name())
+	}
+}
+
+func TestHTTPSink_Write(t *testing.T) {
+	var gotType, gotReason string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.URL.Query().Get("type")
+		gotReason = r.URL.Query().Get("reason")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL+"/ingest", time.Second)
+	err := sink.Write(context.Background(), Profile{Type: TypeCPU, Reason: ReasonMemory, Time: time.Now(), Data: []byte("pprof")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if gotType != TypeCPU || gotReason != ReasonMemory || string(gotBody) != "pprof" {
+		t.Errorf("unexpected upload: type=%s reason=%s body=%s", gotType, gotReason, gotBody)
+	}
+}
+
+func TestDo_SetsLabels(t *testing.T) {
+	Do(context.Background(), "GET /api/example", "GET", "abc", func(ctx context.Context) {
+		if route, _ := pprof.Label(ctx, "http.route"); route != "GET /api/example" {
+			t.Errorf("expected route label, got %q", route)
+		}
+		if traceID, _ := pprof.Label(ctx, "trace_id"); traceID != "abc" {
+			t.Errorf("expected trace_id label, got %q", traceID)
+		}
+	})
+}
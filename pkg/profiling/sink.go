@@ -0,0 +1,122 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink stores or forwards captured profiles
+type Sink interface {
+	Write(ctx context.Context, p Profile) error
+}
+
+// DirSink writes profiles to a local directory, keeping only the most recent files
+type DirSink struct {
+	mu       sync.Mutex
+	dir      string
+	maxFiles int
+}
+
+// NewDirSink creates the directory if needed and returns a sink keeping up to maxFiles profiles
+func NewDirSink(dir string, maxFiles int) (*DirSink, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return &DirSink{dir: dir, maxFiles: maxFiles}, nil
+}
+
+// Write stores the profile as <time>-<reason>-<type>.pprof and removes the oldest files
+func (s *DirSink) Write(_ context.Context, p Profile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := fmt.Sprintf("%s-%s-%s.pprof", p.Time.UTC().Format("20060102T150405.000Z"), p.Reason, p.Type)
+	if err := os.WriteFile(filepath.Join(s.dir, name), p.Data, 0o600); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	return s.rotate()
+}
+
+// rotate removes the oldest profiles beyond maxFiles; names sort by capture time
+func (s *DirSink) rotate() error {
+	if s.maxFiles <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list profile directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".pprof") {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= s.maxFiles {
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names[:len(names)-s.maxFiles] {
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+			return fmt.Errorf("failed to remove old profile: %w", err)
+		}
+	}
+	return nil
+}
+
+// HTTPSink pushes profiles to a remote endpoint
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates a sink that POSTs each profile to url
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Write uploads the profile body with its type, reason and time as query parameters
+func (s *HTTPSink) Write(ctx context.Context, p Profile) error {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return fmt.Errorf("invalid push URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("type", p.Type)
+	q.Set("reason", p.Reason)
+	q.Set("time", p.Time.UTC().Format(time.RFC3339))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(p.Data))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push profile: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to push profile: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
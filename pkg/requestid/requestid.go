@@ -0,0 +1,73 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header is the HTTP header carrying the request ID
+const Header = "X-Request-ID"
+
+// maxLength bounds accepted incoming request IDs
+const maxLength = 128
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored in the context, or ""
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Generate returns a new random 128-bit request ID in hex
+func Generate() string {
+	var b [16]byte
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether an incoming request ID is safe to reuse: non-empty,
+// bounded in length and limited to printable ASCII so it cannot inject into
+// headers or log lines
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Transport propagates the request ID from the request context on outgoing calls
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base, defaulting to http.DefaultTransport when nil
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+// RoundTrip sets the request ID header unless the caller already set one
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if id := FromContext(r.Context()); id != "" && r.Header.Get(Header) == "" {
+		// RoundTrippers must not modify the caller's request
+		r = r.Clone(r.Context())
+		r.Header.Set(Header, id)
+	}
+	return t.Base.RoundTrip(r)
+}
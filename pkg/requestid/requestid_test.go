@@ -0,0 +1,81 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Errorf("expected empty request ID, got %s", id)
+	}
+
+	ctx := NewContext(context.Background(), "abc")
+	if id := FromContext(ctx); id != "abc" {
+		t.Errorf("expected request ID abc, got %s", id)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	a, b := Generate(), Generate()
+
+	if len(a) != 32 {
+		t.Errorf("expected 32 hex chars, got %d", len(a))
+	}
+	if a == b {
+		t.Error("expected unique request IDs")
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: "3f1c2a9e-6b1d-4c55-9a43-2f7e1d1c0b7a", want: true},
+		{name: "empty", id: "", want: false},
+		{name: "too long", id: strings.Repeat("a", 129), want: false},
+		{name: "newline", id: "abc\ninjected", want: false},
+		{name: "space", id: "abc def", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.id); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	req, err := http.NewRequestWithContext(NewContext(context.Background(), "req-1"), http.MethodGet, srv.URL, http.NoBody)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if got != "req-1" {
+		t.Errorf("expected propagated request ID req-1, got %q", got)
+	}
+	if req.Header.Get(Header) != "" {
+		t.Error("expected caller's request to be left unmodified")
+	}
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Next(t *testing.T) {
+	from := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC) // a Thursday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2026, 1, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2026, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon", time.Date(2026, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 feb,mar *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// Restricted day of month and weekday match either, as in Vixie cron
+		{"0 0 20 * fri", time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every",
+		"@every -1m",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestParse_Impossible(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("expected no activation, got %s", next)
+	}
+}
+
+func TestEvery_IsAligned(t *testing.T) {
+	schedule := Every(5 * time.Minute)
+	got := schedule.Next(time.Date(2026, 1, 15, 10, 32, 10, 0, time.UTC))
+	want := time.Date(2026, 1, 15, 10, 35, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
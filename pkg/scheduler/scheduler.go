@@ -0,0 +1,351 @@
+// Package scheduler runs periodic tasks on cron or interval schedules, with
+// jitter, overlap prevention and leader-only execution across replicas
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	mrand "math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ahxar/go-backend-service/pkg/logger"
+)
+
+// instrumentationName identifies the spans and instruments created by this package
+const instrumentationName = "github.com/ahxar/go-backend-service/pkg/scheduler"
+
+// leaderLock is the name of the lock held by the replica running tasks
+const leaderLock = "scheduler"
+
+// Run results recorded in the scheduler.runs metric
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+	// ResultSkipped is recorded when an activation finds the previous run still going
+	ResultSkipped = "skipped"
+)
+
+// Locker grants a lease on a named lock to one owner at a time
+// Implementations backed by shared storage let exactly one replica lead
+type Locker interface {
+	// TryLock acquires or renews name for owner until ttl elapses, reporting whether owner holds it
+	TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Unlock releases name if owner holds it
+	Unlock(ctx context.Context, name, owner string) error
+}
+
+// Task is a periodic unit of work
+type Task struct {
+	// Name identifies the task in logs, spans and metrics
+	Name     string
+	Schedule Schedule
+	// Jitter delays each activation by a random amount up to Jitter so replicas
+	// and tasks sharing a schedule do not all fire at once
+	Jitter time.Duration
+	// Timeout bounds each run; 0 uses the scheduler default
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Config holds scheduler configuration
+type Config struct {
+	// Locker elects the replica that runs tasks; nil runs them on every replica
+	Locker Locker
+	// Owner identifies this replica to the Locker; defaults to the hostname and a random suffix
+	Owner string
+	// LeaseTTL is how long leadership lasts without renewal; it is renewed every third of it
+	LeaseTTL time.Duration
+	// Location is the time zone cron schedules are evaluated in; defaults to UTC
+	Location *time.Location
+	// Timeout bounds runs of tasks without their own
+	Timeout time.Duration
+}
+
+// Scheduler runs tasks on their schedules
+type Scheduler struct {
+	logger   *slog.Logger
+	cfg      Config
+	tasks    []*Task
+	leader   atomic.Bool
+	tracer   trace.Tracer
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+
+	// stop ends the schedule and lease loops; ctx is canceled to abort runs
+	// when Stop times out
+	stop     chan struct{}
+	stopOnce sync.Once
+	ctx      context.Context
+	cancel   context.CancelFunc
+	// loops tracks schedule and lease goroutines; running tracks task runs
+	loops   sync.WaitGroup
+	running sync.WaitGroup
+}
+
+// New creates a scheduler
+func New(logger *slog.Logger, cfg Config) *Scheduler {
+	if cfg.Owner == "" {
+		host, _ := os.Hostname()
+		cfg.Owner = host + "-" + rand.Text()[:8]
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = 30 * time.Second
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+
+	meter := otel.Meter(instrumentationName)
+	runs, err := meter.Int64Counter("scheduler.runs",
+		metric.WithDescription("Scheduled task activations by task and result"),
+		metric.WithUnit("{run}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	duration, err := meter.Float64Histogram("scheduler.run.duration",
+		metric.WithDescription("Duration of scheduled task runs"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		logger:   logger,
+		cfg:      cfg,
+		tracer:   otel.Tracer(instrumentationName),
+		runs:     runs,
+		duration: duration,
+		stop:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Add registers a task; it must be called before Start
+func (s *Scheduler) Add(task Task) error {
+	if task.Name == "" || task.Schedule == nil || task.Run == nil {
+		return errors.New("task requires a name, schedule and run function")
+	}
+	if task.Timeout <= 0 {
+		task.Timeout = s.cfg.Timeout
+	}
+	s.tasks = append(s.tasks, &task)
+	return nil
+}
+
+// IsLeader reports whether this replica currently runs tasks
+func (s *Scheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+// Start begins leader election and the task schedules
+func (s *Scheduler) Start() {
+	if s.cfg.Locker == nil {
+		s.leader.Store(true)
+	} else {
+		s.renew()
+		s.loops.Add(1)
+		go func() {
+			defer s.loops.Done()
+			s.lease()
+		}()
+	}
+
+	for _, task := range s.tasks {
+		s.loops.Add(1)
+		go func() {
+			defer s.loops.Done()
+			s.loop(task)
+		}()
+	}
+}
+
+// Stop ends the schedules, waits for running tasks and gives up leadership
+// When ctx ends first, running tasks are canceled
+func (s *Scheduler) Stop(ctx context.Context) error {
+	// Closing the schedules first means no new runs start while draining
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		err = fmt.Errorf("failed to finish scheduled tasks: %w", ctx.Err())
+	}
+	s.cancel()
+
+	if s.cfg.Locker != nil && s.leader.Swap(false) {
+		if unlockErr := s.cfg.Locker.Unlock(context.WithoutCancel(ctx), leaderLock, s.cfg.Owner); unlockErr != nil {
+			s.logger.Error("failed to release scheduler lock",
+				slog.String("error", unlockErr.Error()),
+			)
+		}
+	}
+	return err
+}
+
+// lease renews leadership every third of the lease TTL
+func (s *Scheduler) lease() {
+	ticker := time.NewTicker(s.cfg.LeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.renew()
+		}
+	}
+}
+
+// renew acquires or extends the leader lock
+// Lock errors drop leadership so a replica that cannot reach the lock store
+// never runs tasks alongside the one that can
+func (s *Scheduler) renew() {
+	ctx, cancel := context.WithTimeout(s.ctx, s.cfg.LeaseTTL/3)
+	defer cancel()
+
+	held, err := s.cfg.Locker.TryLock(ctx, leaderLock, s.cfg.Owner, s.cfg.LeaseTTL)
+	if err != nil {
+		s.logger.Error("failed to renew scheduler lock",
+			slog.String("error", err.Error()),
+		)
+		held = false
+	}
+	if was := s.leader.Swap(held); was != held {
+		s.logger.Info("scheduler leadership changed",
+			slog.String("owner", s.cfg.Owner),
+			slog.Bool("leader", held),
+		)
+	}
+}
+
+// loop waits for each activation of task and starts a run
+func (s *Scheduler) loop(task *Task) {
+	var running atomic.Bool
+
+	for {
+		now := time.Now().In(s.cfg.Location)
+		next := task.Schedule.Next(now)
+		if next.IsZero() {
+			s.logger.Warn("scheduled task has no further activations",
+				slog.String("task", task.Name),
+			)
+			return
+		}
+		wait := next.Sub(now)
+		if task.Jitter > 0 {
+			wait += mrand.N(task.Jitter) //nolint:gosec // jitter does not need a secure source
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !s.leader.Load() {
+			continue
+		}
+		if !running.CompareAndSwap(false, true) {
+			// The previous run is still going; skip rather than pile up
+			s.logger.Warn("scheduled task still running, skipping activation",
+				slog.String("task", task.Name),
+			)
+			s.runs.Add(s.ctx, 1, metric.WithAttributes(
+				attribute.String("task", task.Name),
+				attribute.String("result", ResultSkipped),
+			))
+			continue
+		}
+
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			defer running.Store(false)
+			s.run(task, next)
+		}()
+	}
+}
+
+// run executes one activation of task in its own trace
+func (s *Scheduler) run(task *Task, scheduled time.Time) {
+	ctx, span := s.tracer.Start(s.ctx, "scheduler.run "+task.Name,
+		trace.WithNewRoot(),
+		trace.WithAttributes(
+			attribute.String("scheduler.task", task.Name),
+			attribute.String("scheduler.scheduled_at", scheduled.Format(time.RFC3339)),
+		),
+	)
+	defer span.End()
+	ctx = logger.WithAttrs(ctx, slog.String("task", task.Name))
+
+	ctx, cancel := context.WithTimeout(ctx, task.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := call(ctx, task)
+	elapsed := time.Since(start)
+
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.ErrorContext(ctx, "scheduled task failed",
+			slog.Duration("duration", elapsed),
+			slog.String("error", err.Error()),
+		)
+	} else {
+		s.logger.InfoContext(ctx, "scheduled task completed",
+			slog.Duration("duration", elapsed),
+		)
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("task", task.Name),
+		attribute.String("result", result),
+	)
+	s.runs.Add(ctx, 1, attrs)
+	s.duration.Record(ctx, elapsed.Seconds(), attrs)
+}
+
+// call runs the task, turning panics into errors
+func call(ctx context.Context, task *Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	return task.Run(ctx)
+}
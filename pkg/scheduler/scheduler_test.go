@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestScheduler(cfg Config) *Scheduler {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+}
+
+// memoryLocker is a Locker shared by schedulers in a test
+type memoryLocker struct {
+	mu     sync.Mutex
+	owner  string
+	until  time.Time
+	broken atomic.Bool
+}
+
+func (l *memoryLocker) TryLock(_ context.Context, _, owner string, ttl time.Duration) (bool, error) {
+	if l.broken.Load() {
+		return false, errors.New("lock store unavailable")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owner != "" && l.owner != owner && time.Now().Before(l.until) {
+		return false, nil
+	}
+	l.owner, l.until = owner, time.Now().Add(ttl)
+	return true, nil
+}
+
+func (l *memoryLocker) Unlock(_ context.Context, _, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owner == owner {
+		l.owner = ""
+	}
+	return nil
+}
+
+func TestScheduler_RunsTasks(t *testing.T) {
+	s := newTestScheduler(Config{})
+
+	var runs atomic.Int32
+	if err := s.Add(Task{Name: "tick", Schedule: Every(10 * time.Millisecond), Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	s.Start()
+	time.Sleep(100 * time.Millisecond)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+
+	if runs.Load() < 3 {
+		t.Errorf("expected several runs, got %d", runs.Load())
+	}
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	s := newTestScheduler(Config{})
+
+	var running, overlaps, runs atomic.Int32
+	_ = s.Add(Task{Name: "slow", Schedule: Every(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		runs.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}})
+	s.Start()
+	time.Sleep(100 * time.Millisecond)
+	_ = s.Stop(context.Background())
+
+	if overlaps.Load() != 0 {
+		t.Errorf("expected no overlapping runs, got %d", overlaps.Load())
+	}
+	if runs.Load() == 0 || runs.Load() > 4 {
+		t.Errorf("expected activations during a run to be skipped, got %d runs", runs.Load())
+	}
+}
+
+func TestScheduler_LeaderOnly(t *testing.T) {
+	locker := &memoryLocker{}
+	var runsA, runsB atomic.Int32
+
+	newReplica := func(owner string, runs *atomic.Int32) *Scheduler {
+		s := newTestScheduler(Config{Locker: locker, Owner: owner, LeaseTTL: 30 * time.Millisecond})
+		_ = s.Add(Task{Name: "tick", Schedule: Every(5 * time.Millisecond), Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}})
+		return s
+	}
+
+	a := newReplica("a", &runsA)
+	b := newReplica("b", &runsB)
+	a.Start()
+	b.Start()
+	time.Sleep(60 * time.Millisecond)
+
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected a to lead, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if runsA.Load() == 0 || runsB.Load() != 0 {
+		t.Errorf("expected only the leader to run tasks, got a=%d b=%d", runsA.Load(), runsB.Load())
+	}
+
+	// Stopping the leader releases the lock and the other replica takes over
+	_ = a.Stop(context.Background())
+	time.Sleep(60 * time.Millisecond)
+	_ = b.Stop(context.Background())
+
+	if runsB.Load() == 0 {
+		t.Error("expected the remaining replica to take over")
+	}
+}
+
+func TestScheduler_LockErrorsDropLeadership(t *testing.T) {
+	locker := &memoryLocker{}
+	s := newTestScheduler(Config{Locker: locker, LeaseTTL: 15 * time.Millisecond})
+	s.Start()
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	if !s.IsLeader() {
+		t.Fatal("expected to lead")
+	}
+	locker.broken.Store(true)
+	time.Sleep(30 * time.Millisecond)
+	if s.IsLeader() {
+		t.Error("expected leadership to be dropped when the lock cannot be renewed")
+	}
+}
+
+func TestScheduler_StopCancelsAfterTimeout(t *testing.T) {
+	s := newTestScheduler(Config{})
+
+	started := make(chan struct{}, 1)
+	_ = s.Add(Task{Name: "stuck", Schedule: Every(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	s.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a stop timeout, got %v", err)
+	}
+}
+
+func TestScheduler_AddValidates(t *testing.T) {
+	s := newTestScheduler(Config{})
+	if err := s.Add(Task{Name: "incomplete"}); err == nil {
+		t.Error("expected a task without schedule and run function to be rejected")
+	}
+}
@@ -0,0 +1,196 @@
+// Package sse implements a topic-based Server-Sent Events broker
+package sse
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is a message published to a topic
+type Event struct {
+	// ID increases monotonically across all topics within one broker epoch
+	ID uint64
+	// Epoch identifies the broker instance that published the event; IDs restart with each epoch
+	Epoch string
+	// Topic is the channel the event was published to
+	Topic string
+	// Type is sent as the SSE event name
+	Type string
+	// Data is the event payload, typically JSON
+	Data []byte
+}
+
+// Config holds broker configuration
+type Config struct {
+	// ReplaySize is the number of recent events kept for Last-Event-ID resume
+	ReplaySize int
+	// ClientBuffer is the number of events queued per subscriber before it is dropped
+	ClientBuffer int
+}
+
+// Broker fans out published events to subscribers of their topic
+type Broker struct {
+	mu  sync.Mutex
+	cfg Config
+	// epoch is unique per broker so IDs from before a restart are recognized
+	epoch  string
+	nextID uint64
+	// replay is a ring buffer of the most recent events
+	replay []Event
+	head   int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroker creates a broker with a bounded replay buffer
+func NewBroker(cfg Config) *Broker {
+	if cfg.ClientBuffer <= 0 {
+		cfg.ClientBuffer = 64
+	}
+	return &Broker{
+		cfg:   cfg,
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event an ID, stores it for replay and delivers it to subscribers
+// Subscribers whose buffer is full are dropped rather than blocking the publisher;
+// they can reconnect with Last-Event-ID to resume from the replay buffer
+func (b *Broker) Publish(topic, eventType string, data []byte) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := Event{ID: b.nextID, Epoch: b.epoch, Topic: topic, Type: eventType, Data: data}
+
+	if b.cfg.ReplaySize > 0 {
+		if len(b.replay) < b.cfg.ReplaySize {
+			b.replay = append(b.replay, event)
+		} else {
+			b.replay[b.head] = event
+			b.head = (b.head + 1) % b.cfg.ReplaySize
+		}
+	}
+
+	for sub := range b.subs {
+		if !sub.matches(topic) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(sub, true)
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a subscriber for the given topics, or all topics when empty
+// Events newer than lastEventID still held in the replay buffer are returned
+// so the caller can send them before live events
+// An ID from an earlier epoch predates every buffered event, so the whole buffer is replayed
+func (b *Broker) Subscribe(topics []string, lastEventID string) (*Subscription, []Event) {
+	sub := &Subscription{
+		broker: b,
+		events: make(chan Event, b.cfg.ClientBuffer),
+	}
+	if len(topics) > 0 {
+		sub.topics = make(map[string]bool, len(topics))
+		for _, topic := range topics {
+			sub.topics[topic] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(sub.events)
+		return sub, nil
+	}
+
+	// Collect the backlog under the same lock as registration so no event is missed
+	var backlog []Event
+	if lastEventID != "" {
+		epoch, after := parseEventID(lastEventID)
+		if epoch != b.epoch {
+			after = 0
+		}
+		for _, event := range b.recent() {
+			if event.ID > after && sub.matches(event.Topic) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	b.subs[sub] = struct{}{}
+	return sub, backlog
+}
+
+// recent returns the replay buffer oldest first
+func (b *Broker) recent() []Event {
+	return slices.Concat(b.replay[b.head:], b.replay[:b.head])
+}
+
+// Subscribers returns the number of active subscribers
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription so streaming handlers return, e.g. on shutdown
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub, false)
+	}
+}
+
+// drop unregisters a subscriber and closes its channel; the caller holds b.mu
+func (b *Broker) drop(sub *Subscription, lagged bool) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	sub.lagged = lagged
+	close(sub.events)
+}
+
+// Subscription receives events for a set of topics
+type Subscription struct {
+	broker *Broker
+	events chan Event
+	topics map[string]bool
+	lagged bool
+}
+
+// Events returns the channel of live events, closed when the subscription ends
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Lagged reports whether the subscription was dropped because its buffer was full
+func (s *Subscription) Lagged() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.lagged
+}
+
+// Close unregisters the subscription
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.drop(s, false)
+}
+
+func (s *Subscription) matches(topic string) bool {
+	return s.topics == nil || s.topics[topic]
+}
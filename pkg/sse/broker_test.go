@@ -0,0 +1,139 @@
+package sse
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBroker_PublishToTopicSubscribers(t *testing.T) {
+	b := NewBroker(Config{ReplaySize: 10, ClientBuffer: 4})
+
+	examples, _ := b.Subscribe([]string{"examples"}, "")
+	all, _ := b.Subscribe(nil, "")
+	other, _ := b.Subscribe([]string{"other"}, "")
+
+	event := b.Publish("examples", "example.processed", []byte(`{}`))
+	if event.ID != 1 {
+		t.Errorf("expected ID 1, got %d", event.ID)
+	}
+
+	for name, sub := range map[string]*Subscription{"examples": examples, "all": all} {
+		select {
+		case got := <-sub.Events():
+			if got.ID != event.ID {
+				t.Errorf("%s: expected event %d, got %d", name, event.ID, got.ID)
+			}
+		default:
+			t.Errorf("%s: expected an event", name)
+		}
+	}
+	select {
+	case got := <-other.Events():
+		t.Errorf("expected no event for other topic, got %+v", got)
+	default:
+	}
+}
+
+func TestBroker_ReplayAfterLastEventID(t *testing.T) {
+	b := NewBroker(Config{ReplaySize: 3, ClientBuffer: 4})
+	for range 5 {
+		b.Publish("examples", "tick", nil)
+	}
+	b.Publish("other", "tick", nil)
+
+	// Events 1 and 2 were evicted from the replay buffer
+	_, backlog := b.Subscribe([]string{"examples"}, b.epoch+"-1")
+	if len(backlog) != 2 || backlog[0].ID != 4 || backlog[1].ID != 5 {
+		t.Errorf("expected events 4 and 5, got %+v", backlog)
+	}
+
+	// An ID from before a restart is older than every buffered event
+	for _, id := range []string{"previous-5", "5"} {
+		_, backlog = b.Subscribe(nil, id)
+		if len(backlog) != 3 || backlog[0].ID != 4 {
+			t.Errorf("%s: expected the whole buffer, got %+v", id, backlog)
+		}
+	}
+
+	_, backlog = b.Subscribe(nil, "")
+	if len(backlog) != 0 {
+		t.Errorf("expected no replay without Last-Event-ID, got %d events", len(backlog))
+	}
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(Config{ClientBuffer: 2})
+	sub, _ := b.Subscribe(nil, "")
+
+	for range 3 {
+		b.Publish("examples", "tick", nil)
+	}
+
+	if !sub.Lagged() {
+		t.Error("expected subscriber to be marked as lagged")
+	}
+	if b.Subscribers() != 0 {
+		t.Errorf("expected no subscribers, got %d", b.Subscribers())
+	}
+
+	// Buffered events are still delivered before the channel closes
+	count := 0
+	for range sub.Events() {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 buffered events, got %d", count)
+	}
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := NewBroker(Config{})
+	sub, _ := b.Subscribe(nil, "")
+
+	b.Close()
+	sub.Close()
+
+	if _, ok := <-sub.Events(); ok {
+		t.Error("expected subscription to be closed")
+	}
+	if sub.Lagged() {
+		t.Error("expected closed subscription not to be lagged")
+	}
+
+	late, _ := b.Subscribe(nil, "")
+	if _, ok := <-late.Events(); ok {
+		t.Error("expected subscriptions after close to be closed")
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteEvent(&buf, Event{ID: 7, Epoch: "e1", Type: "example\nprocessed", Data: []byte("line one\r\nline two")})
+	if err != nil {
+		t.Fatalf("failed to write event: %v", err)
+	}
+
+	want := "id: e1-7\nevent: exampleprocessed\ndata: line one\ndata: line two\n\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestParseEventID(t *testing.T) {
+	tests := []struct {
+		value string
+		epoch string
+		id    uint64
+	}{
+		{"e1-42", "e1", 42},
+		{" e1-7 ", "e1", 7},
+		{"42", "", 42},
+		{"", "", 0},
+		{"e1-abc", "e1", 0},
+	}
+	for _, tt := range tests {
+		if epoch, id := parseEventID(tt.value); epoch != tt.epoch || id != tt.id {
+			t.Errorf("parseEventID(%q) = %q, %d, want %q, %d", tt.value, epoch, id, tt.epoch, tt.id)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FormatID returns the SSE id of an event: its epoch and ID, e.g. "mg7x1k2b3c-42"
+func FormatID(event Event) string {
+	id := strconv.FormatUint(event.ID, 10)
+	if event.Epoch == "" {
+		return id
+	}
+	return event.Epoch + "-" + id
+}
+
+// parseEventID splits an id written by FormatID, returning ID 0 when it is invalid
+func parseEventID(value string) (string, uint64) {
+	value = strings.TrimSpace(value)
+	epoch, seq, ok := strings.Cut(value, "-")
+	if !ok {
+		epoch, seq = "", value
+	}
+	id, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return epoch, 0
+	}
+	return epoch, id
+}
+
+// WriteEvent writes an event in the text/event-stream format
+// Multi-line data is split across data fields as required by the format
+func WriteEvent(w io.Writer, event Event) error {
+	var b bytes.Buffer
+	b.WriteString("id: ")
+	b.WriteString(FormatID(event))
+	b.WriteByte('\n')
+	if event.Type != "" {
+		b.WriteString("event: ")
+		b.WriteString(sanitize(event.Type))
+		b.WriteByte('\n')
+	}
+
+	data := strings.ReplaceAll(string(event.Data), "\r\n", "\n")
+	for line := range strings.SplitSeq(data, "\n") {
+		b.WriteString("data: ")
+		b.WriteString(strings.ReplaceAll(line, "\r", ""))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WriteComment writes a comment line, used as a heartbeat to keep proxies from closing idle streams
+func WriteComment(w io.Writer, comment string) error {
+	_, err := io.WriteString(w, ": "+sanitize(comment)+"\n\n")
+	return err
+}
+
+// WriteRetry tells the client how long to wait before reconnecting
+func WriteRetry(w io.Writer, millis int64) error {
+	_, err := io.WriteString(w, "retry: "+strconv.FormatInt(millis, 10)+"\n\n")
+	return err
+}
+
+// sanitize removes line breaks that would end a field early
+func sanitize(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrAddressNotAllowed is returned when a webhook URL resolves to an internal address
+var ErrAddressNotAllowed = errors.New("webhook address not allowed")
+
+// reservedNetworks are special-purpose ranges the Is* checks miss
+var reservedNetworks = []netip.Prefix{
+	// This network, which Linux dials as the local host
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// Carrier-grade NAT shared address space
+	netip.MustParsePrefix("100.64.0.0/10"),
+	// IETF protocol assignments
+	netip.MustParsePrefix("192.0.0.0/24"),
+	// Benchmarking
+	netip.MustParsePrefix("198.18.0.0/15"),
+	// NAT64, which translates to any IPv4 address including internal ones
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// AddressPolicy keeps webhook requests off the service's own network, so a
+// customer-supplied URL cannot reach loopback, private, link-local, multicast,
+// unspecified or reserved addresses, such as the cloud metadata endpoint
+// It is checked when dialing, after DNS resolution, so a public name that
+// resolves to an internal address is refused too
+type AddressPolicy struct {
+	// Allow lists internal networks webhooks may still reach, e.g. 127.0.0.0/8 for local development
+	Allow []netip.Prefix
+}
+
+// Check returns ErrAddressNotAllowed if addr is internal and not allowed
+func (p AddressPolicy) Check(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, prefix := range p.Allow {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return fmt.Errorf("%s is an internal address: %w", addr, ErrAddressNotAllowed)
+	}
+	for _, prefix := range reservedNetworks {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%s is a reserved address: %w", addr, ErrAddressNotAllowed)
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control function applying the policy to the
+// resolved address of every connection
+func (p AddressPolicy) Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse dial address %q: %w", address, err)
+	}
+	return p.Check(addrPort.Addr())
+}
+
+// NewTransport returns an http.DefaultTransport clone that dials only
+// addresses the policy allows
+// Proxies from the environment are ignored, since the policy would only see the proxy's address
+func NewTransport(policy AddressPolicy) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   policy.Control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
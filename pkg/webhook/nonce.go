@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers the IDs of accepted deliveries so replays are rejected
+// Shared stores such as Redis (SET NX PX) implement it to detect replays across instances
+type NonceStore interface {
+	// Remember records id for ttl, reporting false when it is already recorded
+	Remember(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// Forget removes id so a delivery that failed to be accepted can be retried
+	Forget(ctx context.Context, id string) error
+}
+
+// MemoryNonces is an in-process NonceStore
+type MemoryNonces struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryNonces creates an empty in-process nonce store
+func NewMemoryNonces() *MemoryNonces {
+	return &MemoryNonces{
+		expiresAt: make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// Remember records id unless an unexpired record exists
+func (m *MemoryNonces) Remember(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now, ttl)
+	if expiresAt, ok := m.expiresAt[id]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	m.expiresAt[id] = now.Add(ttl)
+	return true, nil
+}
+
+// Forget removes id
+func (m *MemoryNonces) Forget(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.expiresAt, id)
+	return nil
+}
+
+// sweep drops expired records at most once per interval; the caller holds m.mu
+func (m *MemoryNonces) sweep(now time.Time, interval time.Duration) {
+	if now.Sub(m.lastSweep) < interval {
+		return
+	}
+	m.lastSweep = now
+	for id, expiresAt := range m.expiresAt {
+		if !now.Before(expiresAt) {
+			delete(m.expiresAt, id)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ahxar/go-backend-service/pkg/httperror"
+)
+
+// ReceiverConfig holds inbound webhook configuration
+type ReceiverConfig struct {
+	// MaxBodyBytes is the largest accepted request body
+	MaxBodyBytes int64
+	// NonceTTL is how long delivery IDs are remembered to reject replays
+	// It should exceed the longest time a provider keeps retrying a delivery
+	NonceTTL time.Duration
+}
+
+// Delivery is a verified inbound webhook request
+type Delivery struct {
+	Provider string
+	// ID identifies the delivery, as returned by the provider's Verifier
+	ID     string
+	Header http.Header
+	// Body is the raw request body exactly as signed; decode it only after verification
+	Body       []byte
+	ReceivedAt time.Time
+}
+
+// Provider configures how one sender's webhooks are verified and handled
+type Provider struct {
+	Verifier Verifier
+	// Handle accepts a verified delivery; it should hand the delivery off, e.g. to
+	// a job queue, rather than process it inline so the sender gets a prompt response
+	Handle func(ctx context.Context, d Delivery) error
+}
+
+// Receiver serves POST /webhooks/{provider}, verifying each request against the
+// provider's secrets over the raw body before handing it to the provider's handler
+type Receiver struct {
+	nonces    NonceStore
+	logger    *slog.Logger
+	cfg       ReceiverConfig
+	mu        sync.RWMutex
+	providers map[string]Provider
+	now       func() time.Time
+}
+
+// NewReceiver creates a receiver that records delivery IDs in nonces
+func NewReceiver(nonces NonceStore, logger *slog.Logger, cfg ReceiverConfig) *Receiver {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 1 << 20
+	}
+	if cfg.NonceTTL <= 0 {
+		cfg.NonceTTL = 24 * time.Hour
+	}
+	return &Receiver{
+		nonces:    nonces,
+		logger:    logger,
+		cfg:       cfg,
+		providers: make(map[string]Provider),
+		now:       time.Now,
+	}
+}
+
+// Register adds or replaces the provider served under name
+func (rc *Receiver) Register(name string, p Provider) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.providers[name] = p
+}
+
+// ServeHTTP verifies and accepts one delivery
+// Replayed deliveries are acknowledged without being handled again so the sender stops retrying
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("provider")
+	rc.mu.RLock()
+	p, ok := rc.providers[name]
+	rc.mu.RUnlock()
+	if !ok {
+		httperror.Write(w, r, http.StatusNotFound, "unknown webhook provider")
+		return
+	}
+
+	// The body is read in full before anything decodes it, since signatures cover the exact bytes
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, rc.cfg.MaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httperror.Write(w, r, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		httperror.Write(w, r, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	now := rc.now()
+	id, err := p.Verifier.Verify(r.Header, body, now)
+	if err != nil {
+		rc.logger.WarnContext(r.Context(), "rejected inbound webhook",
+			slog.String("provider", name),
+			slog.String("error", err.Error()),
+		)
+		httperror.Write(w, r, http.StatusUnauthorized, "invalid webhook signature")
+		return
+	}
+
+	ctx := r.Context()
+	nonce := name + ":" + id
+	fresh, err := rc.nonces.Remember(ctx, nonce, rc.cfg.NonceTTL)
+	if err != nil {
+		rc.logger.ErrorContext(ctx, "failed to record webhook delivery",
+			slog.String("provider", name),
+			slog.String("error", err.Error()),
+		)
+		httperror.Write(w, r, http.StatusServiceUnavailable, "webhook receiver unavailable")
+		return
+	}
+	if !fresh {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "duplicate", "id": id})
+		return
+	}
+
+	delivery := Delivery{Provider: name, ID: id, Header: r.Header.Clone(), Body: body, ReceivedAt: now}
+	if err := p.Handle(ctx, delivery); err != nil {
+		// Forget the delivery so the sender's retry is accepted
+		_ = rc.nonces.Forget(context.WithoutCancel(ctx), nonce)
+		rc.logger.ErrorContext(ctx, "failed to accept inbound webhook",
+			slog.String("provider", name),
+			slog.String("delivery_id", id),
+			slog.String("error", err.Error()),
+		)
+		httperror.Write(w, r, http.StatusInternalServerError, "failed to accept webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted", "id": id})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
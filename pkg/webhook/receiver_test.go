@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func hexMAC(secret, content string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestGitHubVerifier(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"action":"opened"}`)
+	header := http.Header{}
+	header.Set("X-GitHub-Delivery", "d-1")
+	header.Set("X-Hub-Signature-256", "sha256="+hexMAC("new", string(body)))
+
+	// The old secret stays listed while it is rotated out
+	id, err := NewGitHubVerifier([]byte("old"), []byte("new")).Verify(header, body, now)
+	if err != nil || id != "d-1" {
+		t.Fatalf("expected delivery d-1 to verify, got %q %v", id, err)
+	}
+
+	if _, err := NewGitHubVerifier([]byte("old")).Verify(header, body, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for an unknown secret, got %v", err)
+	}
+
+	header.Del("X-GitHub-Delivery")
+	if _, err := NewGitHubVerifier([]byte("new")).Verify(header, body, now); !errors.Is(err, ErrMissingHeaders) {
+		t.Errorf("expected ErrMissingHeaders without a delivery ID, got %v", err)
+	}
+}
+
+func TestSlackVerifier(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"type":"event_callback"}`)
+	sign := func(at time.Time) http.Header {
+		ts := strconv.FormatInt(at.Unix(), 10)
+		header := http.Header{}
+		header.Set("X-Slack-Request-Timestamp", ts)
+		header.Set("X-Slack-Signature", "v0="+hexMAC("secret", "v0:"+ts+":"+string(body)))
+		return header
+	}
+	v := NewSlackVerifier(5*time.Minute, []byte("secret"))
+
+	first, err := v.Verify(sign(now), body, now)
+	if err != nil {
+		t.Fatalf("expected signature to verify, got %v", err)
+	}
+	// Without an ID header the signature identifies the delivery, so a replay has the same ID
+	if second, _ := v.Verify(sign(now), body, now); second != first {
+		t.Errorf("expected identical requests to share an ID, got %q and %q", first, second)
+	}
+
+	if _, err := v.Verify(sign(now.Add(-time.Hour)), body, now); !errors.Is(err, ErrInvalidTimestamp) {
+		t.Errorf("expected ErrInvalidTimestamp, got %v", err)
+	}
+}
+
+func TestMemoryNonces(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	nonces := NewMemoryNonces()
+	nonces.now = func() time.Time { return now }
+
+	if fresh, _ := nonces.Remember(ctx, "a", time.Minute); !fresh {
+		t.Fatal("expected the first delivery to be fresh")
+	}
+	if fresh, _ := nonces.Remember(ctx, "a", time.Minute); fresh {
+		t.Error("expected a replay to be detected")
+	}
+
+	now = now.Add(time.Minute)
+	if fresh, _ := nonces.Remember(ctx, "a", time.Minute); !fresh {
+		t.Error("expected the record to expire")
+	}
+
+	_ = nonces.Forget(ctx, "a")
+	if fresh, _ := nonces.Remember(ctx, "a", time.Minute); !fresh {
+		t.Error("expected a forgotten delivery to be fresh")
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	var handled []Delivery
+	failNext := false
+	receiver := NewReceiver(NewMemoryNonces(), slog.New(slog.NewTextHandler(io.Discard, nil)), ReceiverConfig{MaxBodyBytes: 64})
+	receiver.Register("partner", Provider{
+		Verifier: NewStandardVerifier(time.Minute, []byte("secret")),
+		Handle: func(_ context.Context, d Delivery) error {
+			if failNext {
+				failNext = false
+				return errors.New("queue unavailable")
+			}
+			handled = append(handled, d)
+			return nil
+		},
+	})
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhooks/{provider}", receiver)
+
+	send := func(provider string, header http.Header, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/"+provider, strings.NewReader(body))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	body := `{"type":"order.paid"}`
+	signed := signedHeader("secret", "evt-1", time.Now(), []byte(body))
+
+	if code := send("unknown", signed, body); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown provider, got %d", code)
+	}
+	if code := send("partner", signed, `{"type":"order.refunded"}`); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a tampered body, got %d", code)
+	}
+	if code := send("partner", signed, strings.Repeat(" ", 65)); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for an oversized body, got %d", code)
+	}
+
+	// A delivery the handler fails to accept can be retried
+	failNext = true
+	if code := send("partner", signed, body); code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the handler fails, got %d", code)
+	}
+	if code := send("partner", signed, body); code != http.StatusAccepted {
+		t.Errorf("expected 202 for the retry, got %d", code)
+	}
+	if code := send("partner", signed, body); code != http.StatusOK {
+		t.Errorf("expected 200 for a replay, got %d", code)
+	}
+
+	if len(handled) != 1 {
+		t.Fatalf("expected 1 handled delivery, got %d", len(handled))
+	}
+	if d := handled[0]; d.Provider != "partner" || d.ID != "evt-1" || string(d.Body) != body {
+		t.Errorf("unexpected delivery %+v", d)
+	}
+}
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxDrainBytes bounds how much of a response body is read before closing it
+const maxDrainBytes = 4096
+
+// SenderConfig holds sender configuration
+type SenderConfig struct {
+	// Timeout bounds each attempt, including reading the response
+	Timeout time.Duration
+	// UserAgent identifies the sender to receivers
+	UserAgent string
+}
+
+// Sender posts signed webhook requests
+type Sender struct {
+	client *http.Client
+	cfg    SenderConfig
+	now    func() time.Time
+}
+
+// Result describes one delivery attempt
+type Result struct {
+	// StatusCode is 0 when no response was received
+	StatusCode int
+	Duration   time.Duration
+}
+
+// NewSender creates a sender using transport, or NewTransport with the default
+// AddressPolicy when nil
+// Redirects are not followed, so an endpoint that moved fails until it is updated
+func NewSender(transport http.RoundTripper, cfg SenderConfig) *Sender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "go-backend-service-webhooks/1.0"
+	}
+	if transport == nil {
+		transport = NewTransport(AddressPolicy{})
+	}
+
+	return &Sender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Send posts body as event id to url, signed with secret
+// Responses other than 2xx are returned as errors alongside their Result
+// The response body is discarded; receivers' replies are never stored
+func (s *Sender) Send(ctx context.Context, url string, secret []byte, id string, body []byte) (Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	now := s.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.cfg.UserAgent)
+	req.Header.Set(HeaderID, id)
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(now.Unix()))
+	req.Header.Set(HeaderSignature, Sign(secret, id, now, body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Result{Duration: time.Since(start)}, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	result := Result{
+		StatusCode: resp.StatusCode,
+		Duration:   time.Since(start),
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("webhook endpoint responded %d", resp.StatusCode)
+	}
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Verifier authenticates inbound webhook requests from one provider
+type Verifier interface {
+	// Verify checks the signature of a request over its raw body and returns the
+	// delivery's unique ID, which the receiver remembers to reject replays
+	Verify(header http.Header, body []byte, now time.Time) (string, error)
+}
+
+// HMACVerifier verifies HMAC-SHA256 signatures in the layouts used by most providers
+type HMACVerifier struct {
+	// Secrets are tried in turn, so the old secret keeps working while it is rotated
+	Secrets [][]byte
+	// SignatureHeader holds the signature; several may be separated by spaces or commas
+	SignatureHeader string
+	// SignaturePrefix precedes each encoded signature, e.g. "sha256="
+	SignaturePrefix string
+	// Base64 decodes signatures as standard base64 instead of hex
+	Base64 bool
+	// TimestampHeader holds the Unix time the request was signed; empty skips the check
+	TimestampHeader string
+	// Tolerance is the largest accepted difference between the timestamp and now
+	Tolerance time.Duration
+	// IDHeader holds the delivery ID; when empty the signature identifies the delivery
+	IDHeader string
+	// Content returns the signed bytes; nil signs the body alone
+	Content func(header http.Header, body []byte) []byte
+}
+
+// NewStandardVerifier verifies requests signed by this package's Sender
+func NewStandardVerifier(tolerance time.Duration, secrets ...[]byte) *HMACVerifier {
+	return &HMACVerifier{
+		Secrets:         secrets,
+		SignatureHeader: HeaderSignature,
+		SignaturePrefix: signatureVersion,
+		TimestampHeader: HeaderTimestamp,
+		Tolerance:       tolerance,
+		IDHeader:        HeaderID,
+		Content: func(header http.Header, body []byte) []byte {
+			return standardContent(header.Get(HeaderID), header.Get(HeaderTimestamp), body)
+		},
+	}
+}
+
+// NewGitHubVerifier verifies GitHub webhooks: X-Hub-Signature-256 over the body,
+// identified by X-GitHub-Delivery
+// GitHub does not sign a timestamp, so replays are only caught by the nonce store
+func NewGitHubVerifier(secrets ...[]byte) *HMACVerifier {
+	return &HMACVerifier{
+		Secrets:         secrets,
+		SignatureHeader: "X-Hub-Signature-256",
+		SignaturePrefix: "sha256=",
+		IDHeader:        "X-GitHub-Delivery",
+	}
+}
+
+// NewSlackVerifier verifies Slack requests: X-Slack-Signature over
+// "v0:<timestamp>:<body>" with the timestamp in X-Slack-Request-Timestamp
+func NewSlackVerifier(tolerance time.Duration, secrets ...[]byte) *HMACVerifier {
+	return &HMACVerifier{
+		Secrets:         secrets,
+		SignatureHeader: "X-Slack-Signature",
+		SignaturePrefix: "v0=",
+		TimestampHeader: "X-Slack-Request-Timestamp",
+		Tolerance:       tolerance,
+		Content: func(header http.Header, body []byte) []byte {
+			return append([]byte("v0:"+header.Get("X-Slack-Request-Timestamp")+":"), body...)
+		},
+	}
+}
+
+// Verify checks the timestamp, then the signature, and returns the delivery ID
+func (v *HMACVerifier) Verify(header http.Header, body []byte, now time.Time) (string, error) {
+	signatures := header.Get(v.SignatureHeader)
+	if signatures == "" {
+		return "", ErrMissingHeaders
+	}
+	id := signatures
+	if v.IDHeader != "" {
+		if id = header.Get(v.IDHeader); id == "" {
+			return "", ErrMissingHeaders
+		}
+	}
+
+	if v.TimestampHeader != "" {
+		timestamp := header.Get(v.TimestampHeader)
+		if timestamp == "" {
+			return "", ErrMissingHeaders
+		}
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return "", ErrInvalidTimestamp
+		}
+		if skew := now.Sub(time.Unix(seconds, 0)); skew > v.Tolerance || skew < -v.Tolerance {
+			return "", ErrInvalidTimestamp
+		}
+	}
+
+	content := body
+	if v.Content != nil {
+		content = v.Content(header, body)
+	}
+
+	for _, secret := range v.Secrets {
+		expected := mac(secret, content)
+		for _, signature := range strings.FieldsFunc(signatures, func(r rune) bool { return r == ' ' || r == ',' }) {
+			if v.matches(signature, expected) {
+				if v.IDHeader == "" {
+					// Identical requests share a signature, so its hash identifies the delivery
+					sum := sha256.Sum256([]byte(signature))
+					id = hex.EncodeToString(sum[:])
+				}
+				return id, nil
+			}
+		}
+	}
+	return "", ErrInvalidSignature
+}
+
+// matches decodes one signature and compares it in constant time
+func (v *HMACVerifier) matches(signature string, expected []byte) bool {
+	value, ok := strings.CutPrefix(signature, v.SignaturePrefix)
+	if !ok {
+		return false
+	}
+
+	var got []byte
+	var err error
+	if v.Base64 {
+		got, err = base64.StdEncoding.DecodeString(value)
+	} else {
+		got, err = hex.DecodeString(value)
+	}
+	return err == nil && hmac.Equal(got, expected)
+}
@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func signedHeader(secret, id string, at time.Time, body []byte) http.Header {
+	h := http.Header{}
+	h.Set(HeaderID, id)
+	h.Set(HeaderTimestamp, strconv.FormatInt(at.Unix(), 10))
+	h.Set(HeaderSignature, Sign([]byte(secret), id, at, body))
+	return h
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"type":"example.created"}`)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		want   error
+	}{
+		{"valid", signedHeader("secret", "evt-1", now, body), body, nil},
+		{"tampered body", signedHeader("secret", "evt-1", now, body), []byte(`{}`), ErrInvalidSignature},
+		{"wrong secret", signedHeader("other", "evt-1", now, body), body, ErrInvalidSignature},
+		{"stale", signedHeader("secret", "evt-1", now.Add(-10*time.Minute), body), body, ErrInvalidTimestamp},
+		{"future", signedHeader("secret", "evt-1", now.Add(10*time.Minute), body), body, ErrInvalidTimestamp},
+		{"missing", http.Header{}, body, ErrMissingHeaders},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify([]byte("secret"), tt.header, tt.body, 5*time.Minute, now); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestVerify_AnyOfSeveralSignatures(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{}`)
+	header := signedHeader("new", "evt-1", now, body)
+	header.Set(HeaderSignature, Sign([]byte("old"), "evt-1", now, body)+" "+header.Get(HeaderSignature))
+
+	if err := Verify([]byte("new"), header, body, time.Minute, now); err != nil {
+		t.Errorf("expected a signature made with the new secret to verify, got %v", err)
+	}
+}
+
+func TestSender_SignsRequests(t *testing.T) {
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = Verify([]byte("secret"), r.Header, body, time.Minute, time.Now())
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	sender := NewSender(loopbackTransport(), SenderConfig{Timeout: time.Second})
+	result, err := sender.Send(context.Background(), server.URL, []byte("secret"), "evt-1", []byte(`{"id":"1"}`))
+	if err != nil {
+		t.Fatalf("expected delivery to succeed, got %v", err)
+	}
+	if verifyErr != nil {
+		t.Errorf("expected the receiver to verify the signature, got %v", verifyErr)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestSender_FailureResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+			return
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := NewSender(loopbackTransport(), SenderConfig{Timeout: time.Second})
+	for path, status := range map[string]int{"/": http.StatusServiceUnavailable, "/moved": http.StatusMovedPermanently} {
+		result, err := sender.Send(context.Background(), server.URL+path, []byte("secret"), "evt-1", []byte(`{}`))
+		if err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+		if result.StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, result.StatusCode)
+		}
+	}
+}
+
+// loopbackTransport allows the test servers' loopback address
+func loopbackTransport() http.RoundTripper {
+	return NewTransport(AddressPolicy{Allow: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}})
+}
+
+func TestAddressPolicy(t *testing.T) {
+	policy := AddressPolicy{Allow: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}}
+
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{addr: "93.184.215.14", allowed: true},
+		{addr: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", allowed: true},
+		{addr: "10.1.2.3", allowed: true},
+		{addr: "127.0.0.1", allowed: false},
+		{addr: "::1", allowed: false},
+		{addr: "10.2.0.1", allowed: false},
+		{addr: "172.16.0.1", allowed: false},
+		{addr: "192.168.1.1", allowed: false},
+		{addr: "169.254.169.254", allowed: false},
+		{addr: "fe80::1", allowed: false},
+		{addr: "fd00::1", allowed: false},
+		{addr: "0.0.0.0", allowed: false},
+		{addr: "0.1.2.3", allowed: false},
+		{addr: "::ffff:127.0.0.1", allowed: false},
+		{addr: "224.0.0.1", allowed: false},
+		{addr: "100.64.0.1", allowed: false},
+		{addr: "100.127.255.254", allowed: false},
+		{addr: "100.128.0.1", allowed: true},
+		{addr: "192.0.0.8", allowed: false},
+		{addr: "198.18.0.1", allowed: false},
+		{addr: "198.19.255.255", allowed: false},
+		{addr: "198.20.0.1", allowed: true},
+		{addr: "64:ff9b::a9fe:a9fe", allowed: false},
+	}
+	for _, tt := range tests {
+		err := policy.Check(netip.MustParseAddr(tt.addr))
+		if tt.allowed && err != nil {
+			t.Errorf("%s: expected to be allowed, got %v", tt.addr, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrAddressNotAllowed) {
+			t.Errorf("%s: expected ErrAddressNotAllowed, got %v", tt.addr, err)
+		}
+	}
+}
+
+func TestSender_RefusesInternalAddresses(t *testing.T) {
+	var called atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+	}))
+	defer server.Close()
+
+	// localhost resolves to loopback, so the default policy refuses it at dial time
+	target := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	result, err := NewSender(nil, SenderConfig{Timeout: time.Second}).Send(context.Background(), target, []byte("secret"), "evt-1", []byte(`{}`))
+	if !errors.Is(err, ErrAddressNotAllowed) {
+		t.Errorf("expected ErrAddressNotAllowed, got %v", err)
+	}
+	if result.StatusCode != 0 || called.Load() {
+		t.Error("expected no request to reach the server")
+	}
+}
@@ -0,0 +1,204 @@
+// Package wshub keeps a registry of WebSocket sessions for broadcast and shutdown
+package wshub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// ErrClosed is returned by Register once the hub is shutting down
+var ErrClosed = errors.New("websocket hub is closed")
+
+// Config holds hub configuration
+type Config struct {
+	// SendBuffer is the number of messages queued per session before it is closed as too slow
+	SendBuffer int
+	// WriteTimeout bounds each message and ping write
+	WriteTimeout time.Duration
+	// PingInterval is the time between keepalive pings; 0 disables pings
+	PingInterval time.Duration
+}
+
+// Hub tracks open sessions
+type Hub struct {
+	mu       sync.Mutex
+	cfg      Config
+	sessions map[*Session]struct{}
+	closed   bool
+}
+
+// New creates an empty hub
+func New(cfg Config) *Hub {
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = 16
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	return &Hub{
+		cfg:      cfg,
+		sessions: make(map[*Session]struct{}),
+	}
+}
+
+// Session is a registered WebSocket connection
+type Session struct {
+	// ID identifies the session in logs and messages
+	ID string
+	// Subject is the authenticated identity that opened the session
+	Subject string
+
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+	// done is closed when the session should stop writing
+	done      chan struct{}
+	closeOnce sync.Once
+	status    websocket.StatusCode
+	reason    string
+}
+
+// Register adds a connection to the hub
+func (h *Hub) Register(conn *websocket.Conn, id, subject string) (*Session, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+
+	s := &Session{
+		ID:      id,
+		Subject: subject,
+		hub:     h,
+		conn:    conn,
+		send:    make(chan []byte, h.cfg.SendBuffer),
+		done:    make(chan struct{}),
+	}
+	h.sessions[s] = struct{}{}
+	return s, nil
+}
+
+// Len returns the number of open sessions
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.sessions)
+}
+
+// Broadcast queues a text message for every session
+// Sessions whose queue is full are closed rather than blocking the sender
+func (h *Hub) Broadcast(data []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.sessions {
+		if !s.enqueue(data) {
+			s.stop(websocket.StatusTryAgainLater, "client too slow")
+		}
+	}
+}
+
+// Close sends a going-away close frame to every session and waits for the
+// sessions to finish, e.g. on server shutdown
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	sessions := make([]*Session, 0, len(h.sessions))
+	for s := range h.sessions {
+		sessions = append(sessions, s)
+	}
+	h.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, s := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.stop(websocket.StatusGoingAway, "server shutting down")
+			_ = s.conn.Close(websocket.StatusGoingAway, "server shutting down")
+		}()
+	}
+	wg.Wait()
+}
+
+// Send queues a text message for the session, reporting false if its queue is full
+func (s *Session) Send(data []byte) bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.enqueue(data)
+}
+
+// enqueue adds data to the send queue without blocking; the caller holds hub.mu
+func (s *Session) enqueue(data []byte) bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+	}
+	select {
+	case s.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run writes queued messages and keepalive pings until ctx ends, a write
+// fails or the session is stopped, which closes the connection
+// A ping that is not answered within the write timeout ends the session
+// Pongs are only processed while the connection is being read
+func (s *Session) Run(ctx context.Context) error {
+	var pings <-chan time.Time
+	if s.hub.cfg.PingInterval > 0 {
+		ticker := time.NewTicker(s.hub.cfg.PingInterval)
+		defer ticker.Stop()
+		pings = ticker.C
+	}
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.done:
+			return s.conn.Close(s.status, s.reason)
+		case data := <-s.send:
+			err = s.write(ctx, func(ctx context.Context) error {
+				return s.conn.Write(ctx, websocket.MessageText, data)
+			})
+		case <-pings:
+			err = s.write(ctx, s.conn.Ping)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// write runs fn with the write timeout
+func (s *Session) write(ctx context.Context, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.hub.cfg.WriteTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// stop tells Run to close the connection with the given status
+func (s *Session) stop(status websocket.StatusCode, reason string) {
+	s.closeOnce.Do(func() {
+		s.status = status
+		s.reason = reason
+		close(s.done)
+	})
+}
+
+// Unregister removes the session from the hub
+func (s *Session) Unregister() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	delete(s.hub.sessions, s)
+}
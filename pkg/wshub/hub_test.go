@@ -0,0 +1,140 @@
+package wshub
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// serveHub accepts connections, registers them and reads until they close
+func serveHub(t *testing.T, hub *Hub) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		session, err := hub.Register(conn, "session", "subject")
+		if err != nil {
+			return
+		}
+		defer session.Unregister()
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		go func() {
+			defer cancel()
+			_ = session.Run(ctx)
+		}()
+		for {
+			if _, _, err := conn.Read(ctx); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dial(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.Dial(t.Context(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.CloseNow() })
+	return conn
+}
+
+// waitForSessions waits until the hub has n sessions
+func waitForSessions(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for hub.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d sessions, got %d", n, hub.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHub_Broadcast(t *testing.T) {
+	hub := New(Config{})
+	server := serveHub(t, hub)
+
+	first := dial(t, server)
+	second := dial(t, server)
+	waitForSessions(t, hub, 2)
+
+	hub.Broadcast([]byte("hello"))
+
+	for _, conn := range []*websocket.Conn{first, second} {
+		_, data, err := conn.Read(t.Context())
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		if string(data) != "hello" {
+			t.Errorf("expected hello, got %s", data)
+		}
+	}
+}
+
+func TestHub_CloseSendsGoingAway(t *testing.T) {
+	hub := New(Config{})
+	server := serveHub(t, hub)
+
+	conn := dial(t, server)
+	waitForSessions(t, hub, 1)
+
+	done := make(chan struct{})
+	go func() {
+		hub.Close()
+		close(done)
+	}()
+
+	_, _, err := conn.Read(t.Context())
+	if status := websocket.CloseStatus(err); status != websocket.StatusGoingAway {
+		t.Errorf("expected going away, got %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Close to return")
+	}
+
+	if _, err := hub.Register(nil, "late", "subject"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestSession_SendQueueFull(t *testing.T) {
+	hub := New(Config{SendBuffer: 1})
+	session, err := hub.Register(nil, "session", "subject")
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	if !session.Send([]byte("first")) {
+		t.Error("expected first message to be queued")
+	}
+	if session.Send([]byte("second")) {
+		t.Error("expected full queue to reject the message")
+	}
+
+	// Broadcast stops sessions that cannot keep up
+	hub.Broadcast([]byte("third"))
+	select {
+	case <-session.done:
+	default:
+		t.Error("expected slow session to be stopped")
+	}
+}